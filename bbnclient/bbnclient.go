@@ -1,42 +1,98 @@
 package bbnclient
 
 import (
+	"context"
+	"fmt"
 	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/babylonlabs-io/babylon/v3/client/query"
 	bbntypes "github.com/babylonlabs-io/babylon/v3/x/btcstaking/types"
+	ckpttypes "github.com/babylonlabs-io/babylon/v3/x/checkpointing/types"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	cosmosclient "github.com/cosmos/cosmos-sdk/client"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
 	sdkquerytypes "github.com/cosmos/cosmos-sdk/types/query"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"google.golang.org/grpc/metadata"
 )
 
 type BabylonClient struct {
 	*query.QueryClient
+
+	// blockTimestamps caches the timestamps of the Babylon blocks by height, which never change
+	blockTimestamps *lru.Cache[int64, int64]
+	// lastHeightByTimestamp is the result of the last lookup of a height by timestamp, where the next lookup
+	// starts searching from
+	lastHeightByTimestamp atomic.Int64
 }
 
+const (
+	// timeout for queries issued directly to the Babylon RPC
+	defaultTimeout = 20 * time.Second
+	// blockTimestampCacheSize is the number of Babylon block timestamps cached for the lookups of heights by
+	// timestamp
+	blockTimestampCacheSize = 10000
+)
+
 //////////////////////////////
 // CONSTRUCTOR
 //////////////////////////////
 
-func NewBabylonClient(queryClient *query.QueryClient) *BabylonClient {
-	return &BabylonClient{
-		QueryClient: queryClient,
+func NewBabylonClient(queryClient *query.QueryClient) (*BabylonClient, error) {
+	blockTimestamps, err := lru.New[int64, int64](blockTimestampCacheSize)
+	if err != nil {
+		return nil, err
 	}
+	return &BabylonClient{
+		QueryClient:     queryClient,
+		blockTimestamps: blockTimestamps,
+	}, nil
 }
 
 //////////////////////////////
 // METHODS
 //////////////////////////////
 
-func (bbnClient *BabylonClient) QueryAllFpBtcPubKeys(consumerId string) ([]string, error) {
-	pagination := &sdkquerytypes.PageRequest{}
-	resp, err := bbnClient.QueryClient.FinalityProviders(consumerId, pagination)
-	if err != nil {
-		return nil, err
+// QueryAllFpBtcPubKeys returns the BTC public keys (hex) of all non-slashed finality providers
+// registered for the consumer chain, as of the given Babylon height. A height of 0 queries the
+// latest state.
+func (bbnClient *BabylonClient) QueryAllFpBtcPubKeys(consumerId string, babylonHeight uint64) ([]string, error) {
+	if babylonHeight > math.MaxInt64 {
+		return nil, fmt.Errorf("babylon height %d exceeds maximum int64 value", babylonHeight)
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatUint(babylonHeight, 10))
+
+	queryClient := bbntypes.NewQueryClient(cosmosclient.Context{Client: bbnClient.QueryClient.RPCClient})
+	pagination := &sdkquerytypes.PageRequest{}
+
 	var pkArr []string
+	for {
+		resp, err := queryClient.FinalityProviders(ctx, &bbntypes.QueryFinalityProvidersRequest{
+			BsnId:      consumerId,
+			Pagination: pagination,
+		})
+		if err != nil {
+			return nil, classifyError(err)
+		}
 
-	for _, fp := range resp.FinalityProviders {
-		pkArr = append(pkArr, fp.BtcPk.MarshalHex())
+		for _, fp := range resp.FinalityProviders {
+			// slashed FPs no longer have voting power
+			if fp.SlashedBabylonHeight > 0 {
+				continue
+			}
+			pkArr = append(pkArr, fp.BtcPk.MarshalHex())
+		}
+
+		if resp.Pagination == nil || resp.Pagination.NextKey == nil {
+			break
+		}
+		pagination.Key = resp.Pagination.NextKey
 	}
 	return pkArr, nil
 }
@@ -48,11 +104,11 @@ func (bbnClient *BabylonClient) QueryMultiFpPower(
 	// Pre-fetch parameters once for all FPs (they're the same for all delegations at this height)
 	btccheckpointParams, err := bbnClient.QueryClient.BTCCheckpointParams()
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	}
 	btcstakingParams, err := bbnClient.QueryClient.BTCStakingParams()
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	}
 
 	// Process FPs in parallel for better performance
@@ -116,25 +172,25 @@ func (bbnClient *BabylonClient) QueryFpEarliestActiveDelBtcHeight(fpPubkeyHex st
 	// queries the BTCStaking module for all delegations of a finality provider
 	resp, err := bbnClient.QueryClient.FinalityProviderDelegations(fpPubkeyHex, pagination)
 	if err != nil {
-		return math.MaxUint32, err
+		return math.MaxUint32, classifyError(err)
 	}
 
 	// queries BtcConfirmationDepth, CovenantQuorum, and the latest BTC header
 	btccheckpointParams, err := bbnClient.QueryClient.BTCCheckpointParams()
 	if err != nil {
-		return math.MaxUint32, err
+		return math.MaxUint32, classifyError(err)
 	}
 
 	// get the BTC staking params
 	btcstakingParams, err := bbnClient.QueryClient.BTCStakingParams()
 	if err != nil {
-		return math.MaxUint32, err
+		return math.MaxUint32, classifyError(err)
 	}
 
 	// get the latest BTC header
 	btcHeader, err := bbnClient.QueryClient.BTCHeaderChainTip()
 	if err != nil {
-		return math.MaxUint32, err
+		return math.MaxUint32, classifyError(err)
 	}
 
 	kValue := btccheckpointParams.GetParams().BtcConfirmationDepth
@@ -170,16 +226,161 @@ func (bbnClient *BabylonClient) QueryFpEarliestActiveDelBtcHeight(fpPubkeyHex st
 
 		resp, err = bbnClient.QueryClient.FinalityProviderDelegations(fpPubkeyHex, pagination)
 		if err != nil {
-			return math.MaxUint32, err
+			return math.MaxUint32, classifyError(err)
 		}
 	}
 	return earliestBtcHeight, nil
 }
 
+// QueryLatestHeight returns the height of the latest Babylon block
+func (bbnClient *BabylonClient) QueryLatestHeight() (uint64, error) {
+	status, err := bbnClient.QueryClient.GetStatus()
+	if err != nil {
+		return 0, classifyError(err)
+	}
+	if status.SyncInfo.LatestBlockHeight < 0 {
+		return 0, fmt.Errorf("unexpected negative Babylon height: %d", status.SyncInfo.LatestBlockHeight)
+	}
+	return uint64(status.SyncInfo.LatestBlockHeight), nil
+}
+
+// QueryLastFinalizedEpoch returns the number of the latest Babylon epoch whose checkpoint is finalized on BTC
+func (bbnClient *BabylonClient) QueryLastFinalizedEpoch() (uint64, error) {
+	resp, err := bbnClient.QueryClient.LatestEpochFromStatus(ckpttypes.Finalized)
+	if err != nil {
+		return 0, classifyError(err)
+	}
+	return resp.RawCheckpoint.EpochNum, nil
+}
+
+// QueryEpochInterval returns the number of Babylon blocks per epoch
+func (bbnClient *BabylonClient) QueryEpochInterval() (uint64, error) {
+	resp, err := bbnClient.QueryClient.EpochingParams()
+	if err != nil {
+		return 0, classifyError(err)
+	}
+	return resp.Params.EpochInterval, nil
+}
+
+/* QueryBabylonHeightByTimestamp returns the height of the latest Babylon block whose timestamp is
+ * at or before the given unix timestamp
+ *
+ * - if the timestamp is after the latest Babylon block, the latest height is returned
+ * - Babylon block timestamps increase with the height, and consecutive L2 blocks map to the same or
+ *   close Babylon heights, so the search window is narrowed down around the last result first
+ * - the block timestamps are cached, so lookups of close timestamps mostly don't query the RPC
+ */
+func (bbnClient *BabylonClient) QueryBabylonHeightByTimestamp(targetTimestamp uint64) (uint64, error) {
+	status, err := bbnClient.QueryClient.GetStatus()
+	if err != nil {
+		return 0, classifyError(err)
+	}
+
+	if targetTimestamp > math.MaxInt64 {
+		return 0, fmt.Errorf("timestamp %d exceeds maximum int64 value", targetTimestamp)
+	}
+	target := int64(targetTimestamp)
+
+	lowerBound := status.SyncInfo.EarliestBlockHeight
+	upperBound := status.SyncInfo.LatestBlockHeight
+	if status.SyncInfo.LatestBlockTime.Unix() <= target {
+		return uint64(upperBound), nil // #nosec G115
+	}
+	if status.SyncInfo.EarliestBlockTime.Unix() > target {
+		return 0, fmt.Errorf("timestamp %d is before the earliest available Babylon block %d", targetTimestamp, lowerBound)
+	}
+
+	// the earliest block is at or before the target, the latest block is after it
+	if last := bbnClient.lastHeightByTimestamp.Load(); last > lowerBound && last < upperBound {
+		lowerBound, upperBound, err = bbnClient.narrowSearchWindow(last, target, lowerBound, upperBound)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for lowerBound+1 < upperBound {
+		midHeight := lowerBound + (upperBound-lowerBound)/2
+
+		blockTimestamp, err := bbnClient.queryBlockTimestamp(midHeight)
+		if err != nil {
+			return 0, err
+		}
+
+		if blockTimestamp <= target {
+			lowerBound = midHeight
+		} else {
+			upperBound = midHeight
+		}
+	}
+
+	bbnClient.lastHeightByTimestamp.Store(lowerBound)
+	return uint64(lowerBound), nil // #nosec G115
+}
+
 //////////////////////////////
 // INTERNAL
 //////////////////////////////
 
+// queryBlockTimestamp returns the unix timestamp of the Babylon block at the given height
+func (bbnClient *BabylonClient) queryBlockTimestamp(height int64) (int64, error) {
+	if timestamp, ok := bbnClient.blockTimestamps.Get(height); ok {
+		return timestamp, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	resp, err := bbnClient.QueryClient.RPCClient.Header(ctx, &height)
+	if err != nil {
+		return 0, classifyError(err)
+	}
+	timestamp := resp.Header.Time.Unix()
+	bbnClient.blockTimestamps.Add(height, timestamp)
+	return timestamp, nil
+}
+
+/* narrowSearchWindow narrows the window of heights searched for the target timestamp down around the given
+ * height, within the lower and upper bounds
+ *
+ * - the window starts between the height and the next one, towards the target timestamp, and doubles until
+ *   it brackets the target timestamp
+ * - as for the bounds, the returned lower bound is at or before the target timestamp, and the returned upper
+ *   bound after it
+ */
+func (bbnClient *BabylonClient) narrowSearchWindow(height int64, target int64, lowerBound int64, upperBound int64) (int64, int64, error) {
+	timestamp, err := bbnClient.queryBlockTimestamp(height)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if timestamp <= target {
+		lower := height
+		for step := int64(1); height+step < upperBound; step *= 2 {
+			timestamp, err := bbnClient.queryBlockTimestamp(height + step)
+			if err != nil {
+				return 0, 0, err
+			}
+			if timestamp > target {
+				return lower, height + step, nil
+			}
+			lower = height + step
+		}
+		return lower, upperBound, nil
+	}
+
+	upper := height
+	for step := int64(1); height-step > lowerBound; step *= 2 {
+		timestamp, err := bbnClient.queryBlockTimestamp(height - step)
+		if err != nil {
+			return 0, 0, err
+		}
+		if timestamp <= target {
+			return height - step, upper, nil
+		}
+		upper = height - step
+	}
+	return lowerBound, upper, nil
+}
+
 // queryFpPower is an optimized version that reuses cached parameters
 func (bbnClient *BabylonClient) queryFpPower(
 	fpPubkeyHex string,
@@ -194,7 +395,7 @@ func (bbnClient *BabylonClient) queryFpPower(
 	// Query delegations for this FP
 	resp, err := bbnClient.QueryClient.FinalityProviderDelegations(fpPubkeyHex, pagination)
 	if err != nil {
-		return 0, err
+		return 0, classifyError(err)
 	}
 
 	for {
@@ -216,7 +417,7 @@ func (bbnClient *BabylonClient) queryFpPower(
 		// Query next page
 		resp, err = bbnClient.QueryClient.FinalityProviderDelegations(fpPubkeyHex, pagination)
 		if err != nil {
-			return 0, err
+			return 0, classifyError(err)
 		}
 	}
 
@@ -280,3 +481,8 @@ func getDelFirstActiveHeight(btcDel *bbntypes.BTCDelegationResponse, latestBtcHe
 	}
 	return activationHeight
 }
+
+// classifyError marks the error of a Babylon query as transient or permanent
+func classifyError(err error) error {
+	return types.ClassifyUpstreamError(types.UpstreamBabylon, err)
+}
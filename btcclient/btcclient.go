@@ -1,10 +1,13 @@
 package btcclient
 
 import (
+	"errors"
 	"fmt"
 	"math"
 
 	"github.com/avast/retry-go/v4"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/btcsuite/btcd/wire"
@@ -157,10 +160,20 @@ func clientCallWithRetry[T any](
 	call retry.RetryableFuncWithData[*T], logger *zap.Logger, cfg *BTCConfig,
 ) (*T, error) {
 	result, err := retry.DoWithData(
-		call,
+		func() (*T, error) {
+			result, err := call()
+			if err != nil {
+				return nil, classifyError(err)
+			}
+			return result, nil
+		},
 		retry.Attempts(cfg.MaxRetryTimes),
 		retry.Delay(cfg.RetryInterval),
 		retry.LastErrorOnly(true),
+		// retrying a call the node rejects fails the same way
+		retry.RetryIf(func(err error) bool {
+			return !types.IsPermanentError(err)
+		}),
 		retry.OnRetry(func(n uint, err error) {
 			logger.Debug(
 				"failed to call the RPC client",
@@ -176,3 +189,20 @@ func clientCallWithRetry[T any](
 	}
 	return result, nil
 }
+
+// classifyError marks the error of a BTC RPC call as permanent if the node rejects the credentials or the request
+// itself, and as transient otherwise
+func classifyError(err error) error {
+	if errors.Is(err, rpcclient.ErrInvalidAuth) {
+		return types.NewUpstreamError(types.UpstreamBitcoin, types.ErrorClassPermanent, err)
+	}
+
+	var rpcErr *btcjson.RPCError
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.Code {
+		case btcjson.ErrRPCMethodNotFound.Code, btcjson.ErrRPCInvalidParams.Code, btcjson.ErrRPCInvalidParameter:
+			return types.NewUpstreamError(types.UpstreamBitcoin, types.ErrorClassPermanent, err)
+		}
+	}
+	return types.NewUpstreamError(types.UpstreamBitcoin, types.ErrorClassTransient, err)
+}
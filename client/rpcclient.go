@@ -127,6 +127,156 @@ func (c *FinalityGadgetGrpcClient) QueryLatestFinalizedBlock() (*types.Block, er
 	}, nil
 }
 
+func (c *FinalityGadgetGrpcClient) QueryFinalityEvidenceByHeight(height uint64) (*types.FinalityEvidence, error) {
+	req := &proto.QueryFinalityEvidenceByHeightRequest{
+		BlockHeight: height,
+	}
+
+	res, err := c.client.QueryFinalityEvidenceByHeight(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromProtoFinalityEvidence(res.Evidence), nil
+}
+
+func (c *FinalityGadgetGrpcClient) QueryFinalityEvidenceByHash(hash string) (*types.FinalityEvidence, error) {
+	req := &proto.QueryFinalityEvidenceByHashRequest{
+		BlockHash: hash,
+	}
+
+	res, err := c.client.QueryFinalityEvidenceByHash(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromProtoFinalityEvidence(res.Evidence), nil
+}
+
+func (c *FinalityGadgetGrpcClient) QueryFinalityProof(height uint64) (*types.FinalityProof, error) {
+	req := &proto.QueryFinalityProofRequest{
+		BlockHeight: height,
+	}
+
+	res, err := c.client.QueryFinalityProof(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromProtoFinalityProof(res.Proof), nil
+}
+
+// ListFinalizedBlocks returns a page of the finalized blocks matching the query, resuming the listing at the
+// cursor returned with the previous page
+func (c *FinalityGadgetGrpcClient) ListFinalizedBlocks(query types.BlockQuery, cursor string) (*types.BlockPage, error) {
+	req := &proto.ListFinalizedBlocksRequest{
+		FromHeight:    query.FromHeight,
+		ToHeight:      query.ToHeight,
+		FromTimestamp: query.FromTimestamp,
+		ToTimestamp:   query.ToTimestamp,
+		Descending:    query.Descending,
+		Limit:         query.Limit,
+		Cursor:        cursor,
+	}
+
+	res, err := c.client.ListFinalizedBlocks(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &types.BlockPage{NextCursor: res.NextCursor}
+	for _, block := range res.Blocks {
+		page.Blocks = append(page.Blocks, &types.Block{
+			BlockHash:      block.BlockHash,
+			BlockHeight:    block.BlockHeight,
+			BlockTimestamp: block.BlockTimestamp,
+		})
+	}
+	return page, nil
+}
+
+// SubscribeFinalizedBlocks replays the finalized blocks from the given height and then calls handler for each
+// newly finalized block, until ctx is cancelled, the stream fails or handler returns an error
+func (c *FinalityGadgetGrpcClient) SubscribeFinalizedBlocks(ctx context.Context, fromHeight uint64, handler func(*types.Block) error) error {
+	req := &proto.SubscribeFinalizedBlocksRequest{
+		FromHeight: fromHeight,
+	}
+
+	stream, err := c.client.SubscribeFinalizedBlocks(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := handler(&types.Block{
+			BlockHash:      res.Block.BlockHash,
+			BlockHeight:    res.Block.BlockHeight,
+			BlockTimestamp: res.Block.BlockTimestamp,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
 func (c *FinalityGadgetGrpcClient) Close() error {
 	return c.conn.Close()
 }
+
+func fromProtoFinalityEvidence(evidence *proto.FinalityEvidence) *types.FinalityEvidence {
+	voters := make([]*types.VoterPower, 0, len(evidence.Voters))
+	for _, voter := range evidence.Voters {
+		voters = append(voters, &types.VoterPower{
+			FpBtcPkHex: voter.FpBtcPkHex,
+			Power:      voter.Power,
+		})
+	}
+
+	return &types.FinalityEvidence{
+		BlockHash:     evidence.BlockHash,
+		BlockHeight:   evidence.BlockHeight,
+		BabylonHeight: evidence.BabylonHeight,
+		BtcHeight:     evidence.BtcHeight,
+		TotalPower:    evidence.TotalPower,
+		VotedPower:    evidence.VotedPower,
+		Voters:        voters,
+		QuorumRatio:   evidence.QuorumRatio,
+		QuorumPower:   evidence.QuorumPower,
+	}
+}
+
+func fromProtoFinalityProof(proof *proto.FinalityProof) *types.FinalityProof {
+	fps := make([]*types.VoterPower, 0, len(proof.FinalityProviders))
+	for _, fp := range proof.FinalityProviders {
+		fps = append(fps, &types.VoterPower{
+			FpBtcPkHex: fp.FpBtcPkHex,
+			Power:      fp.Power,
+		})
+	}
+	votes := make([]*types.BlockVoter, 0, len(proof.Votes))
+	for _, vote := range proof.Votes {
+		votes = append(votes, &types.BlockVoter{
+			FpBtcPkHex:        vote.FpBtcPkHex,
+			PubRand:           vote.PubRand,
+			FinalitySignature: vote.FinalitySignature,
+		})
+	}
+
+	return &types.FinalityProof{
+		Version:            proof.Version,
+		ConsumerId:         proof.ConsumerId,
+		BlockHash:          proof.Block.GetBlockHash(),
+		BlockHeight:        proof.Block.GetBlockHeight(),
+		BlockTimestamp:     proof.Block.GetBlockTimestamp(),
+		BabylonHeight:      proof.BabylonHeight,
+		BtcHeight:          proof.BtcHeight,
+		VotesBabylonHeight: proof.VotesBabylonHeight,
+		TotalPower:         proof.TotalPower,
+		FinalityProviders:  fps,
+		Votes:              votes,
+		SignaturesVerified: proof.SignaturesVerified,
+	}
+}
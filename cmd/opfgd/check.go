@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math/big"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	bbnclient "github.com/babylonlabs-io/babylon/v3/client/client"
+	bbncfg "github.com/babylonlabs-io/babylon/v3/client/config"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/babylonlabs-io/finality-gadget/config"
+	"github.com/babylonlabs-io/finality-gadget/cwclient"
+	"github.com/babylonlabs-io/finality-gadget/db"
+	"github.com/babylonlabs-io/finality-gadget/ethl2client"
+)
+
+const (
+	repairFlag                    = "repair"
+	l2SampleFlag                  = "l2-sample"
+	finalitySignatureIntervalFlag = "finality-signature-interval"
+	bsnActivationHeightFlag       = "bsn-activation-height"
+
+	// l2RequestTimeout bounds each L2 RPC request of the hash cross-check
+	l2RequestTimeout = 10 * time.Second
+)
+
+// CommandDBCheck returns the db check command, which checks the consistency of the DB indices.
+func CommandDBCheck() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "check",
+		Short: "Check the consistency of the DB",
+		Long: `Scan the DB set in the config file and report the inconsistencies between the blocks and their indices: hash mappings, finality evidence,
+earliest and latest heights, and gaps at finality signature interval heights. The interval and BSN activation height are queried from the contract,
+unless set with flags. With --repair, the hash mappings, evidence and indices are rebuilt from the stored blocks; gaps need the blocks to be processed again.
+With --l2-sample, the hashes of that many randomly picked blocks are cross-checked against the L2 RPC. The daemon must be stopped first.`,
+		Example: `opfgd db check --cfg config.toml --l2-sample 100`,
+		Args:    cobra.NoArgs,
+		RunE:    runDBCheckCmd,
+	}
+	cmd.Flags().Bool(repairFlag, false, "repair the hash mappings, evidence and earliest and latest indices")
+	cmd.Flags().Uint64(l2SampleFlag, 0, "number of random blocks to cross-check against the L2 RPC")
+	cmd.Flags().Uint64(finalitySignatureIntervalFlag, 0, "finality signature interval, queried from the contract if unset")
+	cmd.Flags().Uint64(bsnActivationHeightFlag, 0, "BSN activation height, used with --"+finalitySignatureIntervalFlag)
+	return cmd
+}
+
+func runDBCheckCmd(cmd *cobra.Command, args []string) error {
+	repair, err := cmd.Flags().GetBool(repairFlag)
+	if err != nil {
+		return err
+	}
+	l2Sample, err := cmd.Flags().GetUint64(l2SampleFlag)
+	if err != nil {
+		return err
+	}
+	opts := db.CheckOptions{Repair: repair}
+	if opts.FinalitySignatureInterval, err = cmd.Flags().GetUint64(finalitySignatureIntervalFlag); err != nil {
+		return err
+	}
+	if opts.BsnActivationHeight, err = cmd.Flags().GetUint64(bsnActivationHeightFlag); err != nil {
+		return err
+	}
+
+	cfg, logger, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if opts.FinalitySignatureInterval == 0 {
+		if err := queryIntervalHeights(cfg, &opts, logger); err != nil {
+			return err
+		}
+	}
+
+	handler, err := openConfiguredDB(cfg, opts.Repair, logger)
+	if err != nil {
+		return err
+	}
+	defer handler.Close()
+	checker, ok := handler.(db.Checker)
+	if !ok {
+		return fmt.Errorf("the %s DB backend doesn't support checks", cfg.DBBackend)
+	}
+
+	report, err := checker.Check(opts)
+	if err != nil {
+		return fmt.Errorf("failed to check DB: %w", err)
+	}
+	for _, issue := range report.Issues {
+		logger.Warn("DB inconsistency",
+			zap.String("kind", string(issue.Kind)),
+			zap.Uint64("height", issue.Height),
+			zap.String("detail", issue.Detail),
+			zap.Bool("repaired", issue.Repaired))
+	}
+	if listed := uint64(len(report.Issues)); listed < report.Total() {
+		logger.Warn("More DB inconsistencies than listed", zap.Uint64("unlisted", report.Total()-listed))
+	}
+	for _, kind := range slices.Sorted(maps.Keys(report.Counts)) {
+		logger.Info("DB inconsistencies", zap.String("kind", string(kind)), zap.Uint64("count", report.Counts[kind]))
+	}
+	logger.Info("DB check done",
+		zap.Uint64("blocks", report.Blocks),
+		zap.Uint64("earliest_height", report.EarliestHeight),
+		zap.Uint64("latest_height", report.LatestHeight),
+		zap.Uint64("repaired", report.Repaired),
+		zap.Uint64("unrepaired", report.Unrepaired()))
+
+	var mismatches uint64
+	if l2Sample > 0 && report.Blocks > 0 {
+		if mismatches, err = crossCheckL2Hashes(cfg, handler, report, l2Sample, logger); err != nil {
+			return err
+		}
+	}
+
+	if report.Unrepaired() > 0 || mismatches > 0 {
+		return fmt.Errorf("DB check failed: %d unrepaired inconsistencies, %d blocks not on the L2 chain", report.Unrepaired(), mismatches)
+	}
+	return nil
+}
+
+// queryIntervalHeights sets the finality signature interval and BSN activation height of the check from the contract
+func queryIntervalHeights(cfg *config.Config, opts *db.CheckOptions, logger *zap.Logger) error {
+	bbnConfig := bbncfg.DefaultBabylonConfig()
+	bbnConfig.RPCAddr = cfg.BBNRPCAddress
+	bbnConfig.ChainID = cfg.BBNChainID
+	babylonClient, err := bbnclient.New(&bbnConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create Babylon client: %w", err)
+	}
+	cwClient := cwclient.NewCosmWasmClient(babylonClient.QueryClient.RPCClient, cfg.FGContractAddress)
+	contractConfig, err := cwClient.QueryConfig()
+	if err != nil {
+		return fmt.Errorf("failed to query contract config, set --%s to check offline: %w", finalitySignatureIntervalFlag, err)
+	}
+	opts.FinalitySignatureInterval = contractConfig.FinalitySignatureInterval
+	opts.BsnActivationHeight = contractConfig.BsnActivationHeight
+	return nil
+}
+
+/* crossCheckL2Hashes compares the hashes of randomly picked stored blocks with the L2 chain
+ *
+ * - heights are picked uniformly between the earliest and latest stored blocks, and resolved to the
+ *   first stored block at or above them
+ * - returns the number of blocks whose hash isn't the one of the L2 block at their height, which
+ *   means the DB missed an L2 reorg
+ */
+func crossCheckL2Hashes(cfg *config.Config, handler db.IDatabaseHandler, report *db.CheckReport, samples uint64, logger *zap.Logger) (uint64, error) {
+	l2Client, err := ethl2client.NewEthL2Client(cfg.L2RPCHost)
+	if err != nil {
+		return 0, err
+	}
+	defer l2Client.Close()
+
+	var mismatches uint64
+	for i := uint64(0); i < samples; i++ {
+		height := report.EarliestHeight + rand.Uint64N(report.LatestHeight-report.EarliestHeight+1)
+		blocks, err := handler.GetBlocksFromHeight(height, 1)
+		if err != nil {
+			return 0, err
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		block := blocks[0]
+
+		ctx, cancel := context.WithTimeout(context.Background(), l2RequestTimeout)
+		header, err := l2Client.HeaderByNumber(ctx, new(big.Int).SetUint64(block.BlockHeight))
+		cancel()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get L2 block %d: %w", block.BlockHeight, err)
+		}
+		if l2Hash := header.Hash().Hex(); !strings.EqualFold(l2Hash, block.BlockHash) {
+			mismatches++
+			logger.Warn("Stored block isn't on the L2 chain",
+				zap.Uint64("height", block.BlockHeight),
+				zap.String("stored_hash", block.BlockHash),
+				zap.String("l2_hash", l2Hash))
+		}
+	}
+	logger.Info("L2 hash cross-check done", zap.Uint64("samples", samples), zap.Uint64("mismatches", mismatches))
+	return mismatches, nil
+}
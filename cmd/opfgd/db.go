@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/babylonlabs-io/finality-gadget/config"
+	"github.com/babylonlabs-io/finality-gadget/db"
+	"github.com/babylonlabs-io/finality-gadget/log"
+)
+
+const (
+	dryRunFlag = "dry-run"
+)
+
+// CommandDB returns the db command, which groups the maintenance commands of the local DB.
+// The daemon must be stopped first, as the DB is opened for exclusive access, unless a
+// snapshot is taken from the daemon's admin HTTP endpoint.
+func CommandDB() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the op finality gadget DB",
+	}
+	cmd.AddCommand(CommandDBMigrate(), CommandDBSnapshot(), CommandDBRestore(), CommandDBCheck())
+	return cmd
+}
+
+// CommandDBMigrate returns the db migrate command, which upgrades the DB schema to the latest version.
+func CommandDBMigrate() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "migrate",
+		Short:   "Upgrade the DB schema to the latest version",
+		Long:    `Apply the pending schema migrations of the DB set in the config file. The daemon applies them on start too; this command allows upgrading, or checking an upgrade with --dry-run, ahead of it.`,
+		Example: `opfgd db migrate --cfg config.toml --dry-run`,
+		Args:    cobra.NoArgs,
+		RunE:    runDBMigrateCmd,
+	}
+	cmd.Flags().Bool(dryRunFlag, false, "run the pending migrations and roll them back, without writing anything")
+	return cmd
+}
+
+func runDBMigrateCmd(cmd *cobra.Command, args []string) error {
+	dryRun, err := cmd.Flags().GetBool(dryRunFlag)
+	if err != nil {
+		return err
+	}
+
+	handler, logger, err := openDB(cmd)
+	if err != nil {
+		return err
+	}
+	defer handler.Close()
+
+	return migrateDB(handler, dryRun, logger)
+}
+
+// openDB opens the existing DB set in the config file and creates the parts of its initial schema it lacks,
+// for Migrate to upgrade
+func openDB(cmd *cobra.Command) (db.IDatabaseHandler, *zap.Logger, error) {
+	cfg, logger, err := loadConfig(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	handler, err := db.OpenHandler(cfg.DBBackend, cfg.DBSource(), false, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open DB: %w", err)
+	}
+	if err := handler.CreateInitialSchema(); err != nil {
+		handler.Close()
+		return nil, nil, fmt.Errorf("create initial buckets error: %w", err)
+	}
+	return handler, logger, nil
+}
+
+/* openConfiguredDB opens the existing DB set in the config, for the commands reading it
+ *
+ * - the DB is opened read-only unless writable, and it's an error if there is none at the configured path
+ * - the commands only read the latest schema, so a DB with pending migrations is refused rather than
+ *   upgraded behind the operator's back
+ */
+func openConfiguredDB(cfg *config.Config, writable bool, logger *zap.Logger) (db.IDatabaseHandler, error) {
+	handler, err := db.OpenHandler(cfg.DBBackend, cfg.DBSource(), !writable, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DB: %w", err)
+	}
+	migrator, ok := handler.(db.Migrator)
+	if !ok {
+		return handler, nil
+	}
+	pending, err := migrator.PendingMigrations()
+	if err != nil {
+		handler.Close()
+		return nil, fmt.Errorf("failed to check the DB schema: %w", err)
+	}
+	if len(pending) > 0 {
+		handler.Close()
+		return nil, fmt.Errorf("the DB schema is %d migration(s) behind version %d, run `opfgd db migrate` or `opfgd start` first",
+			len(pending), pending[len(pending)-1].Version)
+	}
+	return handler, nil
+}
+
+// loadConfig loads the config file and creates the logger it sets
+func loadConfig(cmd *cobra.Command) (*config.Config, *zap.Logger, error) {
+	cfgPath, err := cmd.Flags().GetString(cfgFlag)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logLevel, err := zapcore.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid log level: %w", err)
+	}
+	logger, err := log.NewRootLogger("console", logLevel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+	return cfg, logger, nil
+}
+
+// migrateDB applies the pending schema migrations of the DB, if its backend has a versioned schema
+func migrateDB(handler db.IDatabaseHandler, dryRun bool, logger *zap.Logger) error {
+	migrator, ok := handler.(db.Migrator)
+	if !ok {
+		logger.Info("DB backend has no versioned schema, nothing to migrate")
+		return nil
+	}
+
+	migrations, err := migrator.Migrate(dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to migrate DB: %w", err)
+	}
+	version, err := migrator.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		logger.Info("DB migration", zap.Uint64("version", m.Version), zap.String("description", m.Description), zap.Bool("dry_run", dryRun))
+	}
+	logger.Info("DB schema version", zap.Uint64("schema_version", version), zap.Int("migrations", len(migrations)), zap.Bool("dry_run", dryRun))
+	return nil
+}
@@ -24,6 +24,9 @@ func main() {
 	cmd := NewRootCmd()
 
 	cmd.AddCommand(CommandStart())
+	cmd.AddCommand(CommandProof())
+	cmd.AddCommand(CommandVerifyProof())
+	cmd.AddCommand(CommandDB())
 
 	cmd.PersistentFlags().String("cfg", "config.toml", "config file")
 	if err := viper.BindPFlag("cfg", cmd.PersistentFlags().Lookup("cfg")); err != nil {
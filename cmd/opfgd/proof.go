@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/babylonlabs-io/finality-gadget/client"
+	"github.com/babylonlabs-io/finality-gadget/finalitygadget"
+	"github.com/babylonlabs-io/finality-gadget/types"
+)
+
+const (
+	heightFlag   = "height"
+	grpcAddrFlag = "grpc-addr"
+	outputFlag   = "output"
+
+	quorumNumeratorFlag   = "quorum-numerator"
+	quorumDenominatorFlag = "quorum-denominator"
+	quorumStrictFlag      = "quorum-strict"
+)
+
+// CommandProof returns the proof command, which exports the finality proof bundle of a block.
+func CommandProof() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "proof",
+		Short:   "Export the finality proof bundle of a block",
+		Long:    `Export a self-contained JSON proof bundle that a block is BTC-finalized, queried from a running op finality gadget daemon.`,
+		Example: `opfgd proof --height 100 --grpc-addr 127.0.0.1:50051 --output proof.json`,
+		Args:    cobra.NoArgs,
+		RunE:    runProofCmd,
+	}
+	cmd.Flags().Uint64(heightFlag, 0, "height of the block")
+	cmd.Flags().String(grpcAddrFlag, "127.0.0.1:50051", "gRPC address of the op finality gadget daemon")
+	cmd.Flags().String(outputFlag, "", "file to write the proof bundle to (defaults to stdout)")
+	if err := cmd.MarkFlagRequired(heightFlag); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// CommandVerifyProof returns the verify-proof command, which re-checks a finality proof bundle offline.
+func CommandVerifyProof() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "verify-proof [proof-file]",
+		Short:   "Verify a finality proof bundle offline",
+		Long:    `Verify the EOTS signatures and the quorum of a finality proof bundle exported by the proof command. The FP set and voting power are taken as given by the bundle.`,
+		Example: `opfgd verify-proof proof.json --quorum-numerator 2 --quorum-denominator 3`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    runVerifyProofCmd,
+	}
+	cmd.Flags().Uint64(quorumNumeratorFlag, types.DefaultQuorumRule.Numerator, "numerator of the share of voting power required for finality")
+	cmd.Flags().Uint64(quorumDenominatorFlag, types.DefaultQuorumRule.Denominator, "denominator of the share of voting power required for finality")
+	cmd.Flags().Bool(quorumStrictFlag, false, "require the voted power to be strictly greater than the quorum")
+	return cmd
+}
+
+func runProofCmd(cmd *cobra.Command, args []string) error {
+	height, err := cmd.Flags().GetUint64(heightFlag)
+	if err != nil {
+		return err
+	}
+	grpcAddr, err := cmd.Flags().GetString(grpcAddrFlag)
+	if err != nil {
+		return err
+	}
+	output, err := cmd.Flags().GetString(outputFlag)
+	if err != nil {
+		return err
+	}
+
+	fgClient, err := client.NewFinalityGadgetGrpcClient(grpcAddr)
+	if err != nil {
+		return err
+	}
+	defer fgClient.Close()
+
+	proof, err := fgClient.QueryFinalityProof(height)
+	if err != nil {
+		return fmt.Errorf("failed to query finality proof: %w", err)
+	}
+
+	proofBytes, err := json.MarshalIndent(proof, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Println(string(proofBytes))
+		return nil
+	}
+	// 0600 = read/write permission for owner only
+	return os.WriteFile(output, proofBytes, 0600)
+}
+
+func runVerifyProofCmd(cmd *cobra.Command, args []string) error {
+	numerator, err := cmd.Flags().GetUint64(quorumNumeratorFlag)
+	if err != nil {
+		return err
+	}
+	denominator, err := cmd.Flags().GetUint64(quorumDenominatorFlag)
+	if err != nil {
+		return err
+	}
+	strict, err := cmd.Flags().GetBool(quorumStrictFlag)
+	if err != nil {
+		return err
+	}
+	quorum := types.QuorumRule{Numerator: numerator, Denominator: denominator, Strict: strict}
+
+	proofBytes, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	var proof types.FinalityProof
+	if err := json.Unmarshal(proofBytes, &proof); err != nil {
+		return fmt.Errorf("failed to decode proof bundle: %w", err)
+	}
+
+	if err := finalitygadget.VerifyFinalityProof(&proof, quorum); err != nil {
+		return err
+	}
+	fmt.Printf("Proof is valid: block %d (%s) is finalized with %d finality provider votes of total power %d\n",
+		proof.BlockHeight, proof.BlockHash, len(proof.Votes), proof.TotalPower)
+	return nil
+}
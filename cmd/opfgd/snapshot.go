@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/babylonlabs-io/finality-gadget/config"
+	"github.com/babylonlabs-io/finality-gadget/db"
+	"github.com/babylonlabs-io/finality-gadget/server"
+)
+
+const (
+	fromFlag = "from"
+)
+
+// CommandDBSnapshot returns the db snapshot command, which writes a snapshot of the DB to bootstrap new replicas.
+func CommandDBSnapshot() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "snapshot",
+		Short: "Write a snapshot of the DB",
+		Long: `Write a snapshot of the DB set in the config file, with a manifest holding its chain ID, contract address, height range and checksum.
+With --from, the snapshot is taken by the running daemon at that HTTP address, from its admin endpoint; the config file must hold its admin token.
+Otherwise the daemon must be stopped, as the DB is opened for exclusive access. Only the bbolt backend supports snapshots.`,
+		Example: `opfgd db snapshot --cfg config.toml --output snapshot.tar.gz --from http://127.0.0.1:8080`,
+		Args:    cobra.NoArgs,
+		RunE:    runDBSnapshotCmd,
+	}
+	cmd.Flags().String(outputFlag, "", "path of the snapshot file to write")
+	cmd.Flags().String(fromFlag, "", "HTTP address of a running daemon to take the snapshot from")
+	_ = cmd.MarkFlagRequired(outputFlag)
+	return cmd
+}
+
+// CommandDBRestore returns the db restore command, which creates the DB from a snapshot.
+func CommandDBRestore() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "restore [snapshot]",
+		Short: "Create the DB from a snapshot",
+		Long: `Create the DB set in the config file from a snapshot written by "opfgd db snapshot". The DB must not exist yet.
+The snapshot must be for the chain ID and contract address of the config; the daemon checks the restored DB against the snapshot manifest on its next start.`,
+		Example: `opfgd db restore snapshot.tar.gz --cfg config.toml`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    runDBRestoreCmd,
+	}
+	return cmd
+}
+
+func runDBSnapshotCmd(cmd *cobra.Command, args []string) error {
+	output, err := cmd.Flags().GetString(outputFlag)
+	if err != nil {
+		return err
+	}
+	from, err := cmd.Flags().GetString(fromFlag)
+	if err != nil {
+		return err
+	}
+	cfg, logger, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Never overwrite an existing file, it may be the only copy of a snapshot
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	if from != "" {
+		err = downloadSnapshot(f, cfg, from)
+	} else {
+		err = writeSnapshot(f, cfg, logger)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return err
+	}
+
+	// Read the snapshot back, so an interrupted download is caught now rather than on restore
+	f, err = os.Open(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	manifest, err := db.VerifySnapshot(f)
+	if err != nil {
+		return fmt.Errorf("failed to verify snapshot %s: %w", output, err)
+	}
+	logger.Info("Wrote DB snapshot",
+		zap.String("path", output),
+		zap.String("chain_id", manifest.ChainID),
+		zap.String("contract_address", manifest.ContractAddress),
+		zap.Uint64("earliest_height", manifest.EarliestHeight),
+		zap.Uint64("latest_height", manifest.LatestHeight),
+		zap.String("checksum", manifest.Checksum))
+	return nil
+}
+
+// writeSnapshot writes a snapshot of the DB set in the config, which the daemon must not hold open
+func writeSnapshot(w io.Writer, cfg *config.Config, logger *zap.Logger) error {
+	handler, err := openConfiguredDB(cfg, false, logger)
+	if err != nil {
+		return fmt.Errorf("%w, use --%s to take a snapshot from a running daemon", err, fromFlag)
+	}
+	defer handler.Close()
+
+	snapshotter, ok := handler.(db.Snapshotter)
+	if !ok {
+		return fmt.Errorf("the %s DB backend doesn't support snapshots", cfg.DBBackend)
+	}
+	_, err = snapshotter.WriteSnapshot(w, cfg.BBNChainID, cfg.FGContractAddress)
+	return err
+}
+
+// downloadSnapshot downloads a snapshot from the admin HTTP endpoint of the daemon at the given address
+func downloadSnapshot(w io.Writer, cfg *config.Config, from string) error {
+	if cfg.AdminToken == "" {
+		return errors.New("the admin token must be set in the config to take a snapshot from a running daemon")
+	}
+	if !strings.Contains(from, "://") {
+		from = "http://" + from
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(from, "/")+server.AdminSnapshotPath, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+cfg.AdminToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to request snapshot: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to download snapshot: %w", err)
+	}
+	return nil
+}
+
+func runDBRestoreCmd(cmd *cobra.Command, args []string) error {
+	cfg, logger, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+	if cfg.DBBackend != "" && cfg.DBBackend != db.BBoltBackend {
+		return fmt.Errorf("the %s DB backend doesn't support snapshots", cfg.DBBackend)
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := db.RestoreSnapshot(f, cfg.DBFilePath); err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	// The manifest is at the end of the snapshot, so it's only checked against the config once restored
+	manifest, err := db.ReadRestoredSnapshotManifest(cfg.DBFilePath, cfg.BBNChainID, cfg.FGContractAddress)
+	if err != nil {
+		os.Remove(cfg.DBFilePath)
+		os.Remove(db.SnapshotManifestPath(cfg.DBFilePath))
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	logger.Info("Restored DB snapshot",
+		zap.String("path", cfg.DBFilePath),
+		zap.Uint64("earliest_height", manifest.EarliestHeight),
+		zap.Uint64("latest_height", manifest.LatestHeight),
+		zap.Uint64("schema_version", manifest.SchemaVersion))
+	return nil
+}
+
+/* confirmRestoredSnapshot checks a DB restored from a snapshot on the first start of the daemon
+ *
+ * - the manifest was checked against the config and the DB file before the DB was opened, see
+ *   db.ReadRestoredSnapshotManifest
+ * - the height range of the manifest must match the DB
+ * - the manifest is then removed, so later starts don't check the DB file, which has changed since
+ */
+func confirmRestoredSnapshot(cfg *config.Config, handler db.IDatabaseHandler, manifest *db.SnapshotManifest, logger *zap.Logger) error {
+	if err := manifest.CheckRestoredDB(handler); err != nil {
+		return fmt.Errorf("invalid restored DB: %w", err)
+	}
+	if err := os.Remove(db.SnapshotManifestPath(cfg.DBFilePath)); err != nil {
+		return err
+	}
+	logger.Info("Validated DB restored from snapshot",
+		zap.String("chain_id", manifest.ChainID),
+		zap.Uint64("earliest_height", manifest.EarliestHeight),
+		zap.Uint64("latest_height", manifest.LatestHeight))
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/spf13/cobra"
@@ -19,7 +20,8 @@ import (
 )
 
 const (
-	cfgFlag = "cfg"
+	cfgFlag           = "cfg"
+	migrateDryRunFlag = "migrate-dry-run"
 )
 
 // CommandStart returns the start command of fpd daemon.
@@ -32,6 +34,7 @@ func CommandStart() *cobra.Command {
 		Args:    cobra.NoArgs,
 		RunE:    runEWithClientCtx(runStartCmd),
 	}
+	cmd.Flags().Bool(migrateDryRunFlag, false, "dry run the pending DB migrations and exit without starting the daemon")
 	return cmd
 }
 
@@ -45,6 +48,10 @@ func runStartCmd(ctx client.Context, cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	migrateDryRun, err := cmd.Flags().GetBool(migrateDryRunFlag)
+	if err != nil {
+		return err
+	}
 
 	// Create logger
 	logLevel, err := zapcore.ParseLevel(cfg.LogLevel)
@@ -59,8 +66,17 @@ func runStartCmd(ctx client.Context, cmd *cobra.Command, args []string) error {
 	// Initialize metrics
 	metrics.Init(logger)
 
+	// Check a DB restored from a snapshot is for this chain and contract, before opening it changes the file
+	var snapshotManifest *db.SnapshotManifest
+	if cfg.DBBackend == "" || cfg.DBBackend == db.BBoltBackend {
+		snapshotManifest, err = db.ReadRestoredSnapshotManifest(cfg.DBFilePath, cfg.BBNChainID, cfg.FGContractAddress)
+		if err != nil {
+			return fmt.Errorf("invalid restored DB: %w", err)
+		}
+	}
+
 	// Init local DB for storing and querying blocks
-	db, err := db.NewBBoltHandler(cfg.DBFilePath, logger)
+	db, err := db.NewHandler(cfg.DBBackend, cfg.DBSource(), logger)
 	if err != nil {
 		return fmt.Errorf("failed to create DB handler: %w", err)
 	}
@@ -75,6 +91,19 @@ func runStartCmd(ctx client.Context, cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("create initial buckets error: %w", err)
 	}
 
+	// Upgrade the DB schema before anything reads from it
+	if err := migrateDB(db, migrateDryRun, logger); err != nil {
+		return err
+	}
+	if migrateDryRun {
+		return nil
+	}
+	if snapshotManifest != nil {
+		if err := confirmRestoredSnapshot(cfg, db, snapshotManifest, logger); err != nil {
+			return err
+		}
+	}
+
 	// Create finality gadget
 	fg, err := finalitygadget.NewFinalityGadget(cfg, db, logger)
 	if err != nil {
@@ -93,26 +122,67 @@ func runStartCmd(ctx client.Context, cmd *cobra.Command, args []string) error {
 		return err
 	}
 	srv := server.NewFinalityGadgetServer(cfg, db, fg, shutdownInterceptor, logger)
+	serverDone := make(chan struct{})
 	go func() {
+		defer close(serverDone)
 		err = srv.RunUntilShutdown()
 		if err != nil {
 			logger.Fatal("Finality gadget server error", zap.Error(err))
 		}
 	}()
 
-	// Run finality gadget in a separate goroutine
+	// The background tasks all use the DB, so it's only closed once they have returned
+	var wg sync.WaitGroup
+
+	// Keep the local BTC header index in sync
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fg.SyncBtcHeaders(fgCtx)
+	}()
+
+	// Prune finalized blocks out of the retention window, if any
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fg.PruneBlocks(fgCtx)
+	}()
+
+	// Alert on finalization stalls, if any stall threshold is set
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fg.WatchFinalization(fgCtx)
+	}()
+
+	// Run finality gadget in a separate goroutine. Transient upstream errors are retried, so it only fails on
+	// unrecoverable errors, in which case the daemon shuts down gracefully and exits with the error
+	processErr := make(chan error, 1)
+	wg.Add(1)
 	go func() {
+		defer wg.Done()
 		if err := fg.ProcessBlocks(fgCtx); err != nil {
-			logger.Fatal("Error processing blocks", zap.Error(err))
+			logger.Error("Unrecoverable error processing blocks, shutting down", zap.Error(err))
+			processErr <- err
+			shutdownInterceptor.RequestShutdown()
 		}
 	}()
 
-	// Wait for shutdown signal
+	// Wait for shutdown signal, then for the background tasks to return and the servers to stop serving
+	// requests reading the DB
 	<-shutdownInterceptor.ShutdownChannel()
+	cancel()
+	wg.Wait()
+	<-serverDone
 
 	// Call Close method when interrupt signal is received
 	logger.Info("Closing finality gadget server...")
 	fg.Close()
 
-	return nil
+	select {
+	case err := <-processErr:
+		return fmt.Errorf("error processing blocks: %w", err)
+	default:
+		return nil
+	}
 }
@@ -2,27 +2,46 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"slices"
 	"time"
 
+	"github.com/babylonlabs-io/finality-gadget/db"
+	"github.com/babylonlabs-io/finality-gadget/types"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	L2RPCHost         string        `long:"l2-rpc-host" description:"rpc host address of the L2 node"`
-	BitcoinRPCHost    string        `long:"bitcoin-rpc-host" description:"rpc host address of the bitcoin node"`
-	BitcoinRPCUser    string        `long:"bitcoin-rpc-user" description:"rpc user of the bitcoin node"`
-	BitcoinRPCPass    string        `long:"bitcoin-rpc-pass" description:"rpc password of the bitcoin node"`
-	FGContractAddress string        `long:"fg-contract-address" description:"BabylonChain op finality gadget contract address"`
-	BBNChainID        string        `long:"bbn-chain-id" description:"BabylonChain chain ID"`
-	BBNRPCAddress     string        `long:"bbn-rpc-address" description:"BabylonChain chain RPC address"`
-	DBFilePath        string        `long:"db-file-path" description:"path to the DB file"`
-	GRPCListener      string        `long:"grpc-listener" description:"host:port to listen for gRPC connections"`
-	HTTPListener      string        `long:"http-listener" description:"host:port to listen for HTTP connections"`
-	LogLevel          string        `long:"log-level" description:"log level (debug, info, warn, error)"`
-	BitcoinDisableTLS bool          `long:"bitcoin-disable-tls" description:"disable TLS for RPC connections"`
-	PollInterval      time.Duration `long:"retry-interval" description:"interval in seconds to recheck Babylon finality of block"`
-	BatchSize         uint64        `long:"batch-size" description:"number of blocks to process in a batch"`
-	StartBlockHeight  uint64        `long:"start-block-height" description:"block height to start processing from when no previous state exists in database"`
+	L2RPCHost             string        `long:"l2-rpc-host" description:"rpc host address of the L2 node"`
+	BitcoinRPCHost        string        `long:"bitcoin-rpc-host" description:"rpc host address of the bitcoin node"`
+	BitcoinRPCUser        string        `long:"bitcoin-rpc-user" description:"rpc user of the bitcoin node"`
+	BitcoinRPCPass        string        `long:"bitcoin-rpc-pass" description:"rpc password of the bitcoin node"`
+	FGContractAddress     string        `long:"fg-contract-address" description:"BabylonChain op finality gadget contract address"`
+	BBNChainID            string        `long:"bbn-chain-id" description:"BabylonChain chain ID"`
+	BBNRPCAddress         string        `long:"bbn-rpc-address" description:"BabylonChain chain RPC address"`
+	DBFilePath            string        `long:"db-file-path" description:"path to the DB file"`
+	DBBackend             string        `long:"db-backend" description:"storage backend of the DB (bbolt, pebble, memory, sqlite or postgres)"`
+	DBURL                 string        `long:"db-url" description:"connection string of the DB, for the postgres backend"`
+	GRPCListener          string        `long:"grpc-listener" description:"host:port to listen for gRPC connections"`
+	HTTPListener          string        `long:"http-listener" description:"host:port to listen for HTTP connections"`
+	LogLevel              string        `long:"log-level" description:"log level (debug, info, warn, error)"`
+	BitcoinDisableTLS     bool          `long:"bitcoin-disable-tls" description:"disable TLS for RPC connections"`
+	PollInterval          time.Duration `long:"retry-interval" description:"interval in seconds to recheck Babylon finality of block"`
+	BatchSize             uint64        `long:"batch-size" description:"maximum number of finalized blocks committed to the DB at once"`
+	ProcessingConcurrency uint64        `long:"processing-concurrency" description:"number of heights fetched and evaluated in parallel (defaults to batch-size)"`
+	StartBlockHeight      uint64        `long:"start-block-height" description:"block height to start processing from when no previous state exists in database"`
+	VerifyEotsSigs        bool          `long:"verify-eots-sigs" description:"locally verify the EOTS signature of each finality vote before counting it"`
+	QuorumNumerator       uint64        `long:"quorum-numerator" description:"numerator of the share of voting power required for finality (defaults to the contract's quorum, or 2/3)"`
+	QuorumDenominator     uint64        `long:"quorum-denominator" description:"denominator of the share of voting power required for finality"`
+	QuorumStrict          bool          `long:"quorum-strict" description:"require the voted power to be strictly greater than the quorum, instead of greater or equal"`
+	RetentionBlocks       uint64        `long:"retention-blocks" description:"number of latest finalized blocks to keep in the DB, older ones are pruned (0 keeps all)"`
+	RetentionDays         uint64        `long:"retention-days" description:"number of days of finalized blocks to keep in the DB, older ones are pruned (0 keeps all)"`
+	PruneInterval         time.Duration `long:"prune-interval" description:"interval between two prunings of the DB when a retention is set (defaults to 10m)"`
+	AdminToken            string        `long:"admin-token" description:"bearer token of the admin HTTP endpoints, which are disabled when empty"`
+	ReadinessMaxLag       uint64        `long:"readiness-max-lag" description:"maximum number of L2 blocks above the latest finalized block for the finality gadget to be ready (defaults to 100)"`
+	StallMaxDuration      time.Duration `long:"stall-max-duration" description:"duration without a new finalized block after which the finalization is stalled (0 disables)"`
+	StallMaxLag           uint64        `long:"stall-max-lag" description:"number of L2 blocks above the latest finalized block beyond which the finalization is stalled (0 disables)"`
+	AlertWebhooks         []string      `long:"alert-webhooks" description:"HTTP endpoints the finalization stall alerts are POSTed to"`
 }
 
 func (c *Config) Validate() error {
@@ -43,8 +62,19 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("bbn-rpc-address is required")
 	}
 	// TODO: add some default values if missing
-	if c.DBFilePath == "" {
-		return fmt.Errorf("db-file-path is required")
+	switch c.DBBackend {
+	case db.MemoryBackend:
+	case db.PostgresBackend:
+		if c.DBURL == "" {
+			return fmt.Errorf("db-url is required for the postgres backend")
+		}
+	default:
+		if c.DBFilePath == "" {
+			return fmt.Errorf("db-file-path is required")
+		}
+	}
+	if c.DBBackend != "" && !slices.Contains(db.Backends, c.DBBackend) {
+		return fmt.Errorf("db-backend must be one of %v", db.Backends)
 	}
 	if c.GRPCListener == "" {
 		return fmt.Errorf("grpc-listener is required")
@@ -61,6 +91,26 @@ func (c *Config) Validate() error {
 	if c.BatchSize == 0 {
 		return fmt.Errorf("batch-size must be greater than 0")
 	}
+	if c.HasQuorum() {
+		if err := c.QuorumRule().Validate(); err != nil {
+			return fmt.Errorf("invalid quorum: %w", err)
+		}
+	}
+	if c.PruneInterval < 0 {
+		return fmt.Errorf("prune-interval must not be negative")
+	}
+	if c.StallMaxDuration < 0 {
+		return fmt.Errorf("stall-max-duration must not be negative")
+	}
+	if len(c.AlertWebhooks) > 0 && c.StallMaxDuration == 0 && c.StallMaxLag == 0 {
+		return fmt.Errorf("alert-webhooks requires stall-max-duration or stall-max-lag")
+	}
+	for _, webhook := range c.AlertWebhooks {
+		u, err := url.Parse(webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("alert webhook %q must be an http or https URL", webhook)
+		}
+	}
 
 	return nil
 }
@@ -89,3 +139,25 @@ func Load(configPath string) (*Config, error) {
 
 	return &config, nil
 }
+
+// HasQuorum returns true if the quorum is set in the config, rather than left to the contract or the default
+func (c *Config) HasQuorum() bool {
+	return c.QuorumNumerator != 0 || c.QuorumDenominator != 0
+}
+
+// QuorumRule returns the quorum set in the config
+func (c *Config) QuorumRule() types.QuorumRule {
+	return types.QuorumRule{
+		Numerator:   c.QuorumNumerator,
+		Denominator: c.QuorumDenominator,
+		Strict:      c.QuorumStrict,
+	}
+}
+
+// DBSource returns where the configured db backend opens the db: the connection string for postgres, the file path otherwise
+func (c *Config) DBSource() string {
+	if c.DBBackend == db.PostgresBackend {
+		return c.DBURL
+	}
+	return c.DBFilePath
+}
@@ -3,6 +3,8 @@ package cwclient
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"math"
 	"time"
 
 	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
@@ -19,6 +21,10 @@ type CosmWasmClient struct {
 const (
 	// hardcode the timeout to 20 seconds. We can expose it to the params once needed
 	DefaultTimeout = 20 * time.Second
+	// page size when listing public randomness commitments
+	listPubRandCommitLimit = 30
+	// page size when listing the allow-listed finality providers
+	listAllowedFinalityProvidersLimit = 30
 )
 
 //////////////////////////////
@@ -36,42 +42,140 @@ func NewCosmWasmClient(rpcClient rpcclient.Client, contractAddr string) *CosmWas
 // METHODS
 //////////////////////////////
 
-func (cwClient *CosmWasmClient) QueryListOfVotedFinalityProviders(
+// QueryBlockVoters returns the votes (FP BTC public key, public randomness and finality signature)
+// submitted for the given (L2 block height, L2 block hash) combination. Returns nil if no FP voted.
+func (cwClient *CosmWasmClient) QueryBlockVoters(
 	queryParams *types.Block,
-) ([]string, error) {
+) ([]*types.BlockVoter, error) {
+	return cwClient.QueryBlockVotersAtHeight(queryParams, 0)
+}
+
+// QueryBlockVotersAtHeight returns the votes submitted for the given (L2 block height, L2 block hash)
+// combination as of the given Babylon height. A height of 0 queries the latest state.
+func (cwClient *CosmWasmClient) QueryBlockVotersAtHeight(
+	queryParams *types.Block,
+	babylonHeight uint64,
+) ([]*types.BlockVoter, error) {
 	queryData, err := createBlockVotersQueryData(queryParams)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := cwClient.querySmartContractState(queryData)
+	resp, err := cwClient.querySmartContractStateAtHeight(queryData, babylonHeight)
 	if err != nil {
 		return nil, err
 	}
-	// BlockVoters's return type is Option<HashSet<String>> in contract
+	// BlockVoters's return type is Option<Vec<BlockVoterInfo>> in contract
 	// Check empty response before unmarshaling
 	if len(resp.Data) == 0 {
 		return nil, nil
 	}
 
-	// The contract now returns detailed finality signature information
-	// We need to extract just the fp_btc_pk_hex field from each object
-	var votedFpDetails []struct {
-		FpBtcPkHex string `json:"fp_btc_pk_hex"`
-		// We can ignore the other fields (pub_rand, finality_signature) for now
+	var voterInfos []blockVoterInfo
+	if err := json.Unmarshal(resp.Data, &voterInfos); err != nil {
+		return nil, err
+	}
+	if voterInfos == nil {
+		return nil, nil
 	}
 
-	if err := json.Unmarshal(resp.Data, &votedFpDetails); err != nil {
-		return nil, err
+	voters := make([]*types.BlockVoter, len(voterInfos))
+	for i, info := range voterInfos {
+		voters[i] = &types.BlockVoter{
+			FpBtcPkHex:        info.FpBtcPkHex,
+			PubRand:           info.PubRand,
+			FinalitySignature: info.FinalitySignature,
+		}
 	}
 
-	// Extract just the public key hex values
-	votedFpPkHexList := make([]string, len(votedFpDetails))
-	for i, detail := range votedFpDetails {
-		votedFpPkHexList[i] = detail.FpBtcPkHex
+	return voters, nil
+}
+
+// QueryPubRandCommitForHeight returns the public randomness commitment of the given FP that covers
+// the given L2 height, or nil if there is none
+func (cwClient *CosmWasmClient) QueryPubRandCommitForHeight(fpBtcPkHex string, height uint64) (*types.PubRandCommit, error) {
+	// commitments are stored by start height, so page through them from the latest one backwards
+	// until we find one covering the height or pass below it
+	var startAfter *uint64
+	for {
+		queryData, err := createListPubRandCommitQueryData(fpBtcPkHex, startAfter)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := cwClient.querySmartContractState(queryData)
+		if err != nil {
+			return nil, err
+		}
+
+		var commits []pubRandCommitResponse
+		if err := json.Unmarshal(resp.Data, &commits); err != nil {
+			return nil, err
+		}
+
+		for _, c := range commits {
+			commit := &types.PubRandCommit{
+				StartHeight:   c.StartHeight,
+				NumPubRand:    c.NumPubRand,
+				BabylonHeight: c.Height,
+				Commitment:    c.Commitment,
+			}
+			if commit.Covers(height) {
+				return commit, nil
+			}
+			if commit.StartHeight < height {
+				// all remaining commitments end before the height
+				return nil, nil
+			}
+		}
+
+		if len(commits) < listPubRandCommitLimit {
+			return nil, nil
+		}
+		lastStartHeight := commits[len(commits)-1].StartHeight
+		startAfter = &lastStartHeight
 	}
+}
+
+/* QueryAllowedFinalityProviders returns the BTC public keys (hex) of the finality providers in the
+ * contract allow-list, as of the given Babylon height. A height of 0 queries the latest state.
+ *
+ * - the allow-list is paged through in the contract's key order with the `start_after` and `limit`
+ *   fields of the contract's `QueryMsg::AllowedFinalityProviders`, every page at the same height
+ * - paging stops at the first empty page, as the contract may cap the page size below the limit
+ * - an empty or unset allow-list returns no key, i.e. no FP is allowed: the contract rejects the
+ *   finality signatures of FPs outside the allow-list, so none of their votes can count
+ */
+func (cwClient *CosmWasmClient) QueryAllowedFinalityProviders(babylonHeight uint64) ([]string, error) {
+	var allowedFpPkHexList []string
+	var startAfter *string
+	for {
+		queryData, err := createAllowedFinalityProvidersQueryData(startAfter)
+		if err != nil {
+			return nil, err
+		}
 
-	return votedFpPkHexList, nil
+		resp, err := cwClient.querySmartContractStateAtHeight(queryData, babylonHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []string
+		if err := json.Unmarshal(resp.Data, &page); err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			return allowedFpPkHexList, nil
+		}
+		allowedFpPkHexList = append(allowedFpPkHexList, page...)
+
+		lastFpPkHex := page[len(page)-1]
+		// a contract ignoring start_after would return the same page forever
+		if startAfter != nil && lastFpPkHex <= *startAfter {
+			return nil, fmt.Errorf("allow-list page after %s doesn't advance", *startAfter)
+		}
+		startAfter = &lastFpPkHex
+	}
 }
 
 func (cwClient *CosmWasmClient) QueryConsumerId() (string, error) {
@@ -114,6 +218,8 @@ func (cwClient *CosmWasmClient) QueryConfig() (*types.ContractConfig, error) {
 		BsnActivationHeight:       data.BsnActivationHeight,
 		FinalitySignatureInterval: data.FinalitySignatureInterval,
 		MinPubRand:                data.MinPubRand,
+		QuorumNumerator:           data.QuorumNumerator,
+		QuorumDenominator:         data.QuorumDenominator,
 	}, nil
 }
 
@@ -140,11 +246,16 @@ type contractConfigResponse struct {
 	BsnActivationHeight       uint64 `json:"bsn_activation_height"`
 	FinalitySignatureInterval uint64 `json:"finality_signature_interval"`
 	MinPubRand                uint64 `json:"min_pub_rand"`
+	// the quorum is optional, contracts that don't expose it leave it to the finality gadget config
+	QuorumNumerator   uint64 `json:"quorum_numerator,omitempty"`
+	QuorumDenominator uint64 `json:"quorum_denominator,omitempty"`
 }
 
 type ContractQueryMsgs struct {
-	Config      *contractConfig   `json:"config,omitempty"`
-	BlockVoters *blockVotersQuery `json:"block_voters,omitempty"`
+	Config                   *contractConfig                `json:"config,omitempty"`
+	BlockVoters              *blockVotersQuery              `json:"block_voters,omitempty"`
+	AllowedFinalityProviders *allowedFinalityProvidersQuery `json:"allowed_finality_providers,omitempty"`
+	ListPubRandCommit        *listPubRandCommitQuery        `json:"list_pub_rand_commit,omitempty"`
 }
 
 type blockVotersQuery struct {
@@ -152,8 +263,52 @@ type blockVotersQuery struct {
 	Height uint64 `json:"height"`
 }
 
+type blockVoterInfo struct {
+	FpBtcPkHex        string        `json:"fp_btc_pk_hex"`
+	PubRand           contractBytes `json:"pub_rand"`
+	FinalitySignature contractBytes `json:"finality_signature"`
+}
+
+type listPubRandCommitQuery struct {
+	BtcPkHex   string  `json:"btc_pk_hex"`
+	StartAfter *uint64 `json:"start_after,omitempty"`
+	Limit      uint32  `json:"limit"`
+	Reverse    bool    `json:"reverse"`
+}
+
+type pubRandCommitResponse struct {
+	StartHeight uint64        `json:"start_height"`
+	NumPubRand  uint64        `json:"num_pub_rand"`
+	Height      uint64        `json:"height"`
+	Commitment  contractBytes `json:"commitment"`
+}
+
+// contractBytes decodes binary fields returned by the contract, which are serialized either as
+// base64 strings (cosmwasm `Binary`) or as arrays of numbers (`Vec<u8>`)
+type contractBytes []byte
+
+func (b *contractBytes) UnmarshalJSON(data []byte) error {
+	var encoded []byte
+	if err := json.Unmarshal(data, &encoded); err == nil {
+		*b = encoded
+		return nil
+	}
+
+	var numbers []uint8
+	if err := json.Unmarshal(data, &numbers); err != nil {
+		return fmt.Errorf("failed to decode contract bytes: %w", err)
+	}
+	*b = numbers
+	return nil
+}
+
 type contractConfig struct{}
 
+type allowedFinalityProvidersQuery struct {
+	StartAfter *string `json:"start_after,omitempty"`
+	Limit      uint32  `json:"limit"`
+}
+
 func createConfigQueryData() ([]byte, error) {
 	queryData := ContractQueryMsgs{
 		Config: &contractConfig{},
@@ -165,19 +320,66 @@ func createConfigQueryData() ([]byte, error) {
 	return data, nil
 }
 
+func createListPubRandCommitQueryData(fpBtcPkHex string, startAfter *uint64) ([]byte, error) {
+	queryData := ContractQueryMsgs{
+		ListPubRandCommit: &listPubRandCommitQuery{
+			BtcPkHex:   fpBtcPkHex,
+			StartAfter: startAfter,
+			Limit:      listPubRandCommitLimit,
+			Reverse:    true,
+		},
+	}
+	data, err := json.Marshal(queryData)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func createAllowedFinalityProvidersQueryData(startAfter *string) ([]byte, error) {
+	queryData := ContractQueryMsgs{
+		AllowedFinalityProviders: &allowedFinalityProvidersQuery{
+			StartAfter: startAfter,
+			Limit:      listAllowedFinalityProvidersLimit,
+		},
+	}
+	data, err := json.Marshal(queryData)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
 // querySmartContractState queries the smart contract state given the contract address and query data
 func (cwClient *CosmWasmClient) querySmartContractState(
 	queryData []byte,
 ) (*wasmtypes.QuerySmartContractStateResponse, error) {
+	return cwClient.querySmartContractStateAtHeight(queryData, 0)
+}
+
+// querySmartContractStateAtHeight queries the smart contract state as of the given Babylon height.
+// A height of 0 queries the latest state.
+func (cwClient *CosmWasmClient) querySmartContractStateAtHeight(
+	queryData []byte,
+	babylonHeight uint64,
+) (*wasmtypes.QuerySmartContractStateResponse, error) {
+	if babylonHeight > math.MaxInt64 {
+		return nil, fmt.Errorf("babylon height %d exceeds maximum int64 value", babylonHeight)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultTimeout)
 	defer cancel()
 
-	sdkClientCtx := cosmosclient.Context{Client: cwClient.Client}
+	sdkClientCtx := cosmosclient.Context{Client: cwClient.Client}.WithHeight(int64(babylonHeight))
 	wasmQueryClient := wasmtypes.NewQueryClient(sdkClientCtx)
 
 	req := &wasmtypes.QuerySmartContractStateRequest{
 		Address:   cwClient.contractAddr,
 		QueryData: queryData,
 	}
-	return wasmQueryClient.SmartContractState(ctx, req)
+	resp, err := wasmQueryClient.SmartContractState(ctx, req)
+	if err != nil {
+		return nil, types.ClassifyUpstreamError(types.UpstreamCosmWasm, err)
+	}
+	return resp, nil
 }
@@ -0,0 +1,109 @@
+package cwclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// allowedFinalityProvidersMsg mirrors the contract's
+// `QueryMsg::AllowedFinalityProviders { start_after: Option<String>, limit: Option<u32> }`, returning
+// `Vec<String>`. As all cw_serde messages, the contract rejects the fields it doesn't know.
+type allowedFinalityProvidersMsg struct {
+	AllowedFinalityProviders *struct {
+		StartAfter *string `json:"start_after"`
+		Limit      *uint32 `json:"limit"`
+	} `json:"allowed_finality_providers"`
+}
+
+// fakeAllowListNode serves the contract allow-list over ABCI queries, in pages of at most maxLimit keys
+type fakeAllowListNode struct {
+	rpcclient.Client
+	t         *testing.T
+	allowList []string
+	maxLimit  uint32
+	heights   []int64
+}
+
+func (n *fakeAllowListNode) ABCIQueryWithOptions(
+	_ context.Context,
+	_ string,
+	data cmtbytes.HexBytes,
+	opts rpcclient.ABCIQueryOptions,
+) (*coretypes.ResultABCIQuery, error) {
+	var req wasmtypes.QuerySmartContractStateRequest
+	require.NoError(n.t, req.Unmarshal(data))
+	n.heights = append(n.heights, opts.Height)
+
+	var msg allowedFinalityProvidersMsg
+	decoder := json.NewDecoder(bytes.NewReader(req.QueryData))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&msg); err != nil || msg.AllowedFinalityProviders == nil {
+		return nil, fmt.Errorf("unexpected query %s: %v", req.QueryData, err)
+	}
+
+	limit := n.maxLimit
+	if msg.AllowedFinalityProviders.Limit != nil {
+		limit = min(limit, *msg.AllowedFinalityProviders.Limit)
+	}
+	page := []string{}
+	for _, fpPk := range n.allowList {
+		if msg.AllowedFinalityProviders.StartAfter != nil && fpPk <= *msg.AllowedFinalityProviders.StartAfter {
+			continue
+		}
+		if uint32(len(page)) == limit {
+			break
+		}
+		page = append(page, fpPk)
+	}
+
+	pageData, err := json.Marshal(page)
+	require.NoError(n.t, err)
+	resp := wasmtypes.QuerySmartContractStateResponse{Data: pageData}
+	respData, err := resp.Marshal()
+	require.NoError(n.t, err)
+	return &coretypes.ResultABCIQuery{Response: abci.ResponseQuery{Value: respData, Height: opts.Height}}, nil
+}
+
+func TestQueryAllowedFinalityProviders(t *testing.T) {
+	allowList := make([]string, 65)
+	for i := range allowList {
+		allowList[i] = fmt.Sprintf("%064x", i)
+	}
+	sort.Strings(allowList)
+
+	testCases := []struct {
+		name      string
+		allowList []string
+		maxLimit  uint32
+	}{
+		{name: "empty allow-list", allowList: nil, maxLimit: 30},
+		{name: "pages of the requested limit", allowList: allowList, maxLimit: 30},
+		{name: "contract capping the page size below the limit", allowList: allowList, maxLimit: 10},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &fakeAllowListNode{t: t, allowList: tc.allowList, maxLimit: tc.maxLimit}
+			cwClient := NewCosmWasmClient(node, "contract")
+
+			allowedFpPks, err := cwClient.QueryAllowedFinalityProviders(42)
+			require.NoError(t, err)
+			require.Equal(t, tc.allowList, allowedFpPks)
+			// every page is queried at the same Babylon height
+			for _, height := range node.heights {
+				require.Equal(t, int64(42), height)
+			}
+		})
+	}
+}
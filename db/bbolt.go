@@ -3,8 +3,8 @@ package db
 import (
 	"bytes"
 	"encoding/binary"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
 	"time"
 
@@ -23,10 +23,13 @@ var _ IDatabaseHandler = &BBoltHandler{}
 const (
 	blocksBucket          = "blocks"
 	blockHeightsBucket    = "block_heights"
+	evidenceBucket        = "evidence"
+	btcHeadersBucket      = "btc_headers"
 	indexerBucket         = "indexer"
 	earliestBlockKey      = "earliest"
 	latestBlockKey        = "latest"
 	activatedTimestampKey = "activated_timestamp"
+	checkpointKey         = "processing_checkpoint"
 )
 
 //////////////////////////////
@@ -34,8 +37,17 @@ const (
 //////////////////////////////
 
 func NewBBoltHandler(path string, logger *zap.Logger) (*BBoltHandler, error) {
+	return openBBoltHandler(path, false, logger)
+}
+
+// NewReadOnlyBBoltHandler opens the existing bbolt db at path read-only, which other readers can share
+func NewReadOnlyBBoltHandler(path string, logger *zap.Logger) (*BBoltHandler, error) {
+	return openBBoltHandler(path, true, logger)
+}
+
+func openBBoltHandler(path string, readOnly bool, logger *zap.Logger) (*BBoltHandler, error) {
 	// 0600 = read/write permission for owner only
-	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: readOnly})
 	if err != nil {
 		logger.Error("Error opening DB", zap.Error(err))
 		return nil, err
@@ -51,15 +63,30 @@ func NewBBoltHandler(path string, logger *zap.Logger) (*BBoltHandler, error) {
 // METHODS
 //////////////////////////////
 
+// CreateInitialSchema creates the buckets. A new db is stamped with the latest schema version, while
+// an existing one keeps its version for Migrate to upgrade; dbs from a newer release are refused.
 func (bb *BBoltHandler) CreateInitialSchema() error {
 	bb.logger.Info("Initialising DB...")
 	return bb.db.Update(func(tx *bolt.Tx) error {
-		buckets := []string{blocksBucket, blockHeightsBucket, indexerBucket}
+		isNew := tx.Bucket([]byte(indexerBucket)) == nil && tx.Bucket([]byte(blocksBucket)) == nil
+
+		buckets := []string{blocksBucket, blockHeightsBucket, evidenceBucket, btcHeadersBucket, indexerBucket}
 		for _, bucket := range buckets {
 			if err := bb.tryCreateBucket(tx, bucket); err != nil {
 				return err
 			}
 		}
+
+		if isNew {
+			return bb.putSchemaVersion(tx, latestBBoltSchemaVersion())
+		}
+		version, err := bb.getSchemaVersion(tx)
+		if err != nil {
+			return err
+		}
+		if version > latestBBoltSchemaVersion() {
+			return fmt.Errorf("db schema version %d is newer than the latest known version %d", version, latestBBoltSchemaVersion())
+		}
 		return nil
 	})
 }
@@ -73,104 +100,200 @@ func (bb *BBoltHandler) InsertBlocks(blocks []*types.Block) error {
 
 	// Single transaction for all operations
 	return bb.db.Update(func(tx *bolt.Tx) error {
-		blocksBucket := tx.Bucket([]byte(blocksBucket))
-		heightsBucket := tx.Bucket([]byte(blockHeightsBucket))
-		indexBucket := tx.Bucket([]byte(indexerBucket))
-
-		var minHeight, maxHeight uint64 = math.MaxUint64, 0
+		return bb.insertBlocks(tx, blocks)
+	})
+}
 
-		// Insert all blocks
-		for _, block := range blocks {
-			// Update min/max heights
-			if block.BlockHeight < minHeight {
-				bb.logger.Debug("Setting min height to", zap.Uint64("min_height", block.BlockHeight))
-				minHeight = block.BlockHeight
-			}
-			if block.BlockHeight > maxHeight {
-				bb.logger.Debug("Setting max height to", zap.Uint64("max_height", block.BlockHeight))
-				maxHeight = block.BlockHeight
-			}
+// InsertBlocksWithCheckpoint stores the blocks and the processing checkpoint in a single transaction.
+// blocks can be empty to only move the checkpoint.
+func (bb *BBoltHandler) InsertBlocksWithCheckpoint(blocks []*types.Block, checkpoint *types.ProcessingCheckpoint) error {
+	bb.logger.Debug("Inserting blocks with processing checkpoint",
+		zap.Int("count", len(blocks)),
+		zap.Uint64("checkpoint_height", checkpoint.Height),
+		zap.Stringer("checkpoint_outcome", checkpoint.Outcome))
 
-			// Store block data
-			blockBytes, err := json.Marshal(block)
-			if err != nil {
-				bb.logger.Error("Error inserting block", zap.Error(err))
-				return err
-			}
-			bb.logger.Debug("Inserting block to db", zap.Uint64("block_height", block.BlockHeight), zap.String("block_hash", block.BlockHash))
-			if err := blocksBucket.Put(bb.itob(block.BlockHeight), blockBytes); err != nil {
-				bb.logger.Error("Error inserting block to db", zap.Error(err))
+	return bb.db.Update(func(tx *bolt.Tx) error {
+		if len(blocks) > 0 {
+			if err := bb.insertBlocks(tx, blocks); err != nil {
 				return err
 			}
+		}
+		return tx.Bucket([]byte(indexerBucket)).Put([]byte(checkpointKey), encodeCheckpoint(checkpoint))
+	})
+}
+
+// GetProcessingCheckpoint returns the processing checkpoint, nil if none was stored yet
+func (bb *BBoltHandler) GetProcessingCheckpoint() (*types.ProcessingCheckpoint, error) {
+	var checkpoint *types.ProcessingCheckpoint
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket([]byte(indexerBucket)).Get([]byte(checkpointKey))
+		if v == nil {
+			return nil
+		}
+		var err error
+		checkpoint, err = decodeCheckpoint(v)
+		return err
+	})
+	if err != nil {
+		bb.logger.Error("Error getting processing checkpoint", zap.Error(err))
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+func (bb *BBoltHandler) GetBlockByHeight(height uint64) (*types.Block, error) {
+	var block *types.Block
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		var err error
+		block, err = bb.getBlock(tx, height)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (bb *BBoltHandler) GetBlockByHash(hash string) (*types.Block, error) {
+	var block *types.Block
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		var err error
+		block, err = bb.getBlockByHash(tx, hash)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
 
-			// Store height mapping
-			bb.logger.Debug("Inserting height mapping to db", zap.String("block_hash", block.BlockHash), zap.Uint64("block_height", block.BlockHeight))
-			if err := heightsBucket.Put([]byte(block.BlockHash), bb.itob(block.BlockHeight)); err != nil {
-				bb.logger.Error("Error inserting height mapping", zap.Error(err))
+// GetBlocksFromHeight returns up to limit blocks at or above the given height, in ascending
+// height order. Heights without a finalized block are skipped.
+func (bb *BBoltHandler) GetBlocksFromHeight(height uint64, limit uint64) ([]*types.Block, error) {
+	var blocks []*types.Block
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(blocksBucket)).Cursor()
+		for k, v := c.Seek(bb.itob(height)); k != nil && uint64(len(blocks)) < limit; k, v = c.Next() {
+			block, err := decodeBlock(v)
+			if err != nil {
 				return err
 			}
+			blocks = append(blocks, block)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
 
-		// Update earliest block if needed
-		earliestBytes := indexBucket.Get([]byte(earliestBlockKey))
-		if earliestBytes == nil {
-			bb.logger.Debug("Updating earliest block in db", zap.Uint64("block_height", minHeight))
-			if err := indexBucket.Put([]byte(earliestBlockKey), bb.itob(minHeight)); err != nil {
-				bb.logger.Error("Error inserting earliest block", zap.Error(err))
-				return err
+/* ListBlocks returns up to query.Limit blocks matching the query, in its height order
+ *
+ * - the timestamp bounds are resolved to height bounds with cursor seeks, see blockHeightBounds
+ * - the blocks are then read with a single cursor walk from the lower or upper bound
+ */
+func (bb *BBoltHandler) ListBlocks(query types.BlockQuery) ([]*types.Block, error) {
+	var blocks []*types.Block
+	if query.Limit == 0 {
+		return blocks, nil
+	}
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(blocksBucket)).Cursor()
+		blockAt := func(k, v []byte) (*types.Block, error) {
+			if k == nil {
+				return nil, nil
 			}
+			return decodeBlock(v)
+		}
+		first, err := blockAt(c.First())
+		if err != nil {
+			return err
+		}
+		last, err := blockAt(c.Last())
+		if err != nil {
+			return err
+		}
+		lower, upper, err := blockHeightBounds(query, first, last, func(height uint64) (*types.Block, error) {
+			return blockAt(c.Seek(bb.itob(height)))
+		})
+		if err != nil || lower > upper {
+			return err
 		}
 
-		// Update latest block if needed
-		latestBytes := indexBucket.Get([]byte(latestBlockKey))
-		var currentLatest uint64
-		if latestBytes != nil {
-			currentLatest = bb.btoi(latestBytes)
+		var k, v []byte
+		if query.Descending {
+			// the highest stored block at or below the upper bound
+			if k, v = c.Seek(bb.itob(upper)); k == nil {
+				k, v = c.Last()
+			} else if bb.btoi(k) > upper {
+				k, v = c.Prev()
+			}
+		} else {
+			k, v = c.Seek(bb.itob(lower))
 		}
-		if maxHeight > currentLatest {
-			bb.logger.Debug("Updating latest block in db", zap.Uint64("block_height", maxHeight))
-			if err := indexBucket.Put([]byte(latestBlockKey), bb.itob(maxHeight)); err != nil {
-				bb.logger.Error("Error inserting latest block", zap.Error(err))
+		for k != nil && uint64(len(blocks)) < query.Limit {
+			if height := bb.btoi(k); height < lower || height > upper {
+				break
+			}
+			block, err := decodeBlock(v)
+			if err != nil {
 				return err
 			}
+			if matchesTimestamp(query, block) {
+				blocks = append(blocks, block)
+			}
+			if query.Descending {
+				k, v = c.Prev()
+			} else {
+				k, v = c.Next()
+			}
 		}
-
 		return nil
 	})
+	if err != nil {
+		bb.logger.Error("Error listing blocks", zap.Error(err))
+		return nil, err
+	}
+	return blocks, nil
 }
 
-func (bb *BBoltHandler) GetBlockByHeight(height uint64) (*types.Block, error) {
-	var block types.Block
+func (bb *BBoltHandler) GetEvidenceByHeight(height uint64) (*types.FinalityEvidence, error) {
+	var evidence *types.FinalityEvidence
 	err := bb.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(blocksBucket))
+		b := tx.Bucket([]byte(evidenceBucket))
 		v := b.Get(bb.itob(height))
 		if v == nil {
-			return types.ErrBlockNotFound
+			return types.ErrEvidenceNotFound
 		}
-		return json.Unmarshal(v, &block)
+		var err error
+		evidence, err = decodeEvidence(v)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &block, nil
+	return evidence, nil
 }
 
-func (bb *BBoltHandler) GetBlockByHash(hash string) (*types.Block, error) {
+func (bb *BBoltHandler) GetEvidenceByHash(hash string) (*types.FinalityEvidence, error) {
 	// Fetch block number corresponding to hash
 	var blockHeight uint64
 	err := bb.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(blockHeightsBucket))
-		v := b.Get([]byte(hash))
-		if v == nil {
-			return types.ErrBlockNotFound
+		block, err := bb.getBlockByHash(tx, hash)
+		if errors.Is(err, types.ErrBlockNotFound) {
+			return types.ErrEvidenceNotFound
 		}
-		blockHeight = bb.btoi(v)
+		if err != nil {
+			return err
+		}
+		blockHeight = block.BlockHeight
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
-	return bb.GetBlockByHeight(blockHeight)
+	return bb.GetEvidenceByHeight(blockHeight)
 }
 
 func (bb *BBoltHandler) QueryIsBlockFinalizedByHeight(height uint64) (bool, error) {
@@ -185,25 +308,14 @@ func (bb *BBoltHandler) QueryIsBlockFinalizedByHeight(height uint64) (bool, erro
 }
 
 func (bb *BBoltHandler) QueryIsBlockFinalizedByHash(hash string) (bool, error) {
-	// Fetch block number by hash
-	var blockHeight uint64
-	err := bb.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(blockHeightsBucket))
-		res := b.Get([]byte(hash))
-		if len(res) == 0 {
-			return types.ErrBlockNotFound
-		}
-		blockHeight = bb.btoi(res)
-		return nil
-	})
+	_, err := bb.GetBlockByHash(hash)
 	if err != nil {
 		if errors.Is(err, types.ErrBlockNotFound) {
 			return false, nil
 		}
 		return false, err
 	}
-
-	return bb.QueryIsBlockFinalizedByHeight(blockHeight)
+	return true, nil
 }
 
 func (bb *BBoltHandler) QueryEarliestFinalizedBlock() (*types.Block, error) {
@@ -249,6 +361,161 @@ func (bb *BBoltHandler) QueryLatestFinalizedBlock() (*types.Block, error) {
 	return bb.GetBlockByHeight(latestBlockHeight)
 }
 
+// DeleteBlocksFromHeight removes all blocks at or above the given height from the blocks,
+// block_heights and evidence buckets, moves the latest index back to the highest remaining
+// block and rewinds the processing checkpoint below height. This is used to roll back the
+// db after an L2 reorg.
+func (bb *BBoltHandler) DeleteBlocksFromHeight(height uint64) error {
+	bb.logger.Info("Deleting blocks from height", zap.Uint64("from_height", height))
+
+	return bb.db.Update(func(tx *bolt.Tx) error {
+		blocksBucket := tx.Bucket([]byte(blocksBucket))
+		heightsBucket := tx.Bucket([]byte(blockHeightsBucket))
+		evidenceBucket := tx.Bucket([]byte(evidenceBucket))
+		indexBucket := tx.Bucket([]byte(indexerBucket))
+
+		// Collect keys first, as deleting while iterating a cursor may skip entries
+		var heightKeys [][]byte
+		var hashKeys [][]byte
+		c := blocksBucket.Cursor()
+		for k, v := c.Seek(bb.itob(height)); k != nil; k, v = c.Next() {
+			block, err := decodeBlock(v)
+			if err != nil {
+				bb.logger.Error("Error decoding block", zap.Error(err))
+				return err
+			}
+			heightKeys = append(heightKeys, append([]byte(nil), k...))
+			hashKeys = append(hashKeys, hashKey(block.BlockHash))
+		}
+
+		for _, k := range heightKeys {
+			if err := blocksBucket.Delete(k); err != nil {
+				bb.logger.Error("Error deleting block", zap.Error(err))
+				return err
+			}
+			if err := evidenceBucket.Delete(k); err != nil {
+				bb.logger.Error("Error deleting finality evidence", zap.Error(err))
+				return err
+			}
+		}
+		for _, k := range hashKeys {
+			if err := heightsBucket.Delete(k); err != nil {
+				bb.logger.Error("Error deleting height mapping", zap.Error(err))
+				return err
+			}
+		}
+		bb.logger.Debug("Deleted blocks from db", zap.Int("count", len(heightKeys)))
+
+		// Rewind the processing checkpoint, so the deleted blocks are processed again
+		if v := indexBucket.Get([]byte(checkpointKey)); v != nil {
+			checkpoint, err := decodeCheckpoint(v)
+			if err != nil {
+				bb.logger.Error("Error decoding processing checkpoint", zap.Error(err))
+				return err
+			}
+			if rewound := rewoundCheckpoint(checkpoint, height); rewound != nil {
+				if err := indexBucket.Put([]byte(checkpointKey), encodeCheckpoint(rewound)); err != nil {
+					bb.logger.Error("Error rewinding processing checkpoint", zap.Error(err))
+					return err
+				}
+			}
+		}
+
+		// Point the latest index at the highest remaining block, or clear the
+		// indices if no block is left
+		lastKey, _ := blocksBucket.Cursor().Last()
+		if lastKey == nil {
+			if err := indexBucket.Delete([]byte(earliestBlockKey)); err != nil {
+				bb.logger.Error("Error deleting earliest block", zap.Error(err))
+				return err
+			}
+			if err := indexBucket.Delete([]byte(latestBlockKey)); err != nil {
+				bb.logger.Error("Error deleting latest block", zap.Error(err))
+				return err
+			}
+			return nil
+		}
+		if err := indexBucket.Put([]byte(latestBlockKey), append([]byte(nil), lastKey...)); err != nil {
+			bb.logger.Error("Error updating latest block", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+// DeleteBlocksBeforeHeight removes up to limit of the lowest blocks below the given height from the blocks,
+// block_heights and evidence buckets, moves the earliest index up to the lowest remaining block and returns
+// the number of blocks removed. This is used to prune blocks out of the retention window in small batches,
+// so each write transaction stays short.
+func (bb *BBoltHandler) DeleteBlocksBeforeHeight(height uint64, limit uint64) (uint64, error) {
+	var deleted uint64
+	err := bb.db.Update(func(tx *bolt.Tx) error {
+		blocksBucket := tx.Bucket([]byte(blocksBucket))
+		heightsBucket := tx.Bucket([]byte(blockHeightsBucket))
+		evidenceBucket := tx.Bucket([]byte(evidenceBucket))
+		indexBucket := tx.Bucket([]byte(indexerBucket))
+
+		// Collect keys first, as deleting while iterating a cursor may skip entries
+		var heightKeys [][]byte
+		var hashKeys [][]byte
+		c := blocksBucket.Cursor()
+		for k, v := c.First(); k != nil && bb.btoi(k) < height && uint64(len(heightKeys)) < limit; k, v = c.Next() {
+			block, err := decodeBlock(v)
+			if err != nil {
+				bb.logger.Error("Error decoding block", zap.Error(err))
+				return err
+			}
+			heightKeys = append(heightKeys, append([]byte(nil), k...))
+			hashKeys = append(hashKeys, hashKey(block.BlockHash))
+		}
+		if len(heightKeys) == 0 {
+			return nil
+		}
+
+		for _, k := range heightKeys {
+			if err := blocksBucket.Delete(k); err != nil {
+				bb.logger.Error("Error deleting block", zap.Error(err))
+				return err
+			}
+			if err := evidenceBucket.Delete(k); err != nil {
+				bb.logger.Error("Error deleting finality evidence", zap.Error(err))
+				return err
+			}
+		}
+		for _, k := range hashKeys {
+			if err := heightsBucket.Delete(k); err != nil {
+				bb.logger.Error("Error deleting height mapping", zap.Error(err))
+				return err
+			}
+		}
+		deleted = uint64(len(heightKeys))
+
+		// Point the earliest index at the lowest remaining block, or clear the
+		// indices if no block is left
+		firstKey, _ := blocksBucket.Cursor().First()
+		if firstKey == nil {
+			if err := indexBucket.Delete([]byte(earliestBlockKey)); err != nil {
+				bb.logger.Error("Error deleting earliest block", zap.Error(err))
+				return err
+			}
+			if err := indexBucket.Delete([]byte(latestBlockKey)); err != nil {
+				bb.logger.Error("Error deleting latest block", zap.Error(err))
+				return err
+			}
+			return nil
+		}
+		if err := indexBucket.Put([]byte(earliestBlockKey), append([]byte(nil), firstKey...)); err != nil {
+			bb.logger.Error("Error updating earliest block", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
 func (bb *BBoltHandler) GetActivatedTimestamp() (uint64, error) {
 	var timestamp uint64
 	err := bb.db.View(func(tx *bolt.Tx) error {
@@ -273,6 +540,103 @@ func (bb *BBoltHandler) SaveActivatedTimestamp(timestamp uint64) error {
 	})
 }
 
+// InsertBtcHeaders stores BTC headers keyed by height, replacing any header stored at the same height
+func (bb *BBoltHandler) InsertBtcHeaders(headers []*types.BtcHeader) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	bb.logger.Debug("Batch inserting BTC headers to DB", zap.Int("count", len(headers)))
+
+	return bb.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(btcHeadersBucket))
+		for _, header := range headers {
+			if err := b.Put(bb.itob(uint64(header.Height)), encodeBtcHeader(header)); err != nil {
+				bb.logger.Error("Error inserting BTC header", zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (bb *BBoltHandler) GetBtcHeaderByHeight(height uint32) (*types.BtcHeader, error) {
+	var header *types.BtcHeader
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		var err error
+		header, err = bb.getBtcHeader(tx.Bucket([]byte(btcHeadersBucket)), height)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// GetBtcHeightByTimestamp resolves a timestamp to a BTC height from the header index, see searchBtcHeight
+func (bb *BBoltHandler) GetBtcHeightByTimestamp(timestamp uint64) (uint32, error) {
+	var height uint32
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(btcHeadersBucket))
+		c := b.Cursor()
+		var earliest, latest *types.BtcHeader
+		var err error
+		if _, v := c.First(); v != nil {
+			if earliest, err = decodeBtcHeader(v); err != nil {
+				return err
+			}
+		}
+		if _, v := c.Last(); v != nil {
+			if latest, err = decodeBtcHeader(v); err != nil {
+				return err
+			}
+		}
+
+		height, err = searchBtcHeight(earliest, latest, timestamp, func(height uint32) (*types.BtcHeader, error) {
+			return bb.getBtcHeader(b, height)
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+// QueryEarliestBtcHeader returns the lowest indexed BTC header, or nil if the index is empty
+func (bb *BBoltHandler) QueryEarliestBtcHeader() (*types.BtcHeader, error) {
+	return bb.queryBtcHeaderAtEnd(func(c *bolt.Cursor) ([]byte, []byte) { return c.First() })
+}
+
+// QueryLatestBtcHeader returns the highest indexed BTC header, or nil if the index is empty
+func (bb *BBoltHandler) QueryLatestBtcHeader() (*types.BtcHeader, error) {
+	return bb.queryBtcHeaderAtEnd(func(c *bolt.Cursor) ([]byte, []byte) { return c.Last() })
+}
+
+// DeleteBtcHeadersFromHeight removes all BTC headers at or above the given height.
+// This is used to drop headers reorged out of the BTC chain.
+func (bb *BBoltHandler) DeleteBtcHeadersFromHeight(height uint32) error {
+	bb.logger.Info("Deleting BTC headers from height", zap.Uint32("from_height", height))
+
+	return bb.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(btcHeadersBucket))
+
+		// Collect keys first, as deleting while iterating a cursor may skip entries
+		var keys [][]byte
+		c := b.Cursor()
+		for k, _ := c.Seek(bb.itob(uint64(height))); k != nil; k, _ = c.Next() {
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				bb.logger.Error("Error deleting BTC header", zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (bb *BBoltHandler) Close() error {
 	bb.logger.Info("Closing DB...")
 	return bb.db.Close()
@@ -282,6 +646,92 @@ func (bb *BBoltHandler) Close() error {
 // INTERNAL
 //////////////////////////////
 
+// insertBlocks stores the blocks with their height mappings and evidence, and moves the indices to cover them
+func (bb *BBoltHandler) insertBlocks(tx *bolt.Tx, blocks []*types.Block) error {
+	blocksBucket := tx.Bucket([]byte(blocksBucket))
+	heightsBucket := tx.Bucket([]byte(blockHeightsBucket))
+	evidenceBucket := tx.Bucket([]byte(evidenceBucket))
+	indexBucket := tx.Bucket([]byte(indexerBucket))
+
+	var minHeight, maxHeight uint64 = math.MaxUint64, 0
+
+	// Insert all blocks
+	for _, block := range blocks {
+		// Update min/max heights
+		if block.BlockHeight < minHeight {
+			bb.logger.Debug("Setting min height to", zap.Uint64("min_height", block.BlockHeight))
+			minHeight = block.BlockHeight
+		}
+		if block.BlockHeight > maxHeight {
+			bb.logger.Debug("Setting max height to", zap.Uint64("max_height", block.BlockHeight))
+			maxHeight = block.BlockHeight
+		}
+
+		// If a different block was stored at this height (e.g. before an L2 reorg),
+		// drop its height mapping so the stale hash no longer resolves
+		if existing := blocksBucket.Get(bb.itob(block.BlockHeight)); existing != nil {
+			existingBlock, err := decodeBlock(existing)
+			if err != nil {
+				bb.logger.Error("Error decoding existing block", zap.Error(err))
+				return err
+			}
+			if existingBlock.BlockHash != block.BlockHash {
+				bb.logger.Debug("Replacing block at height", zap.Uint64("block_height", block.BlockHeight), zap.String("old_block_hash", existingBlock.BlockHash), zap.String("new_block_hash", block.BlockHash))
+				if err := heightsBucket.Delete(hashKey(existingBlock.BlockHash)); err != nil {
+					bb.logger.Error("Error deleting stale height mapping", zap.Error(err))
+					return err
+				}
+			}
+		}
+
+		// Store block data
+		blockBytes := encodeBlock(block)
+		bb.logger.Debug("Inserting block to db", zap.Uint64("block_height", block.BlockHeight), zap.String("block_hash", block.BlockHash))
+		if err := blocksBucket.Put(bb.itob(block.BlockHeight), blockBytes); err != nil {
+			bb.logger.Error("Error inserting block to db", zap.Error(err))
+			return err
+		}
+
+		// Store height mapping
+		bb.logger.Debug("Inserting height mapping to db", zap.String("block_hash", block.BlockHash), zap.Uint64("block_height", block.BlockHeight))
+		if err := heightsBucket.Put(hashKey(block.BlockHash), bb.itob(block.BlockHeight)); err != nil {
+			bb.logger.Error("Error inserting height mapping", zap.Error(err))
+			return err
+		}
+
+		// Store finality evidence, or drop any evidence left from a replaced block
+		if err := bb.putEvidence(evidenceBucket, block); err != nil {
+			return err
+		}
+	}
+
+	// Update earliest block if needed
+	earliestBytes := indexBucket.Get([]byte(earliestBlockKey))
+	if earliestBytes == nil {
+		bb.logger.Debug("Updating earliest block in db", zap.Uint64("block_height", minHeight))
+		if err := indexBucket.Put([]byte(earliestBlockKey), bb.itob(minHeight)); err != nil {
+			bb.logger.Error("Error inserting earliest block", zap.Error(err))
+			return err
+		}
+	}
+
+	// Update latest block if needed
+	latestBytes := indexBucket.Get([]byte(latestBlockKey))
+	var currentLatest uint64
+	if latestBytes != nil {
+		currentLatest = bb.btoi(latestBytes)
+	}
+	if maxHeight > currentLatest {
+		bb.logger.Debug("Updating latest block in db", zap.Uint64("block_height", maxHeight))
+		if err := indexBucket.Put([]byte(latestBlockKey), bb.itob(maxHeight)); err != nil {
+			bb.logger.Error("Error inserting latest block", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (bb *BBoltHandler) tryCreateBucket(tx *bolt.Tx, bucketName string) error {
 	_, err := tx.CreateBucketIfNotExists([]byte(bucketName))
 	if err != nil {
@@ -290,6 +740,78 @@ func (bb *BBoltHandler) tryCreateBucket(tx *bolt.Tx, bucketName string) error {
 	return err
 }
 
+// putEvidence stores the block's finality evidence under its height, keyed the same
+// way as the blocks bucket. Blocks without evidence clear any stored record.
+func (bb *BBoltHandler) putEvidence(evidenceBucket *bolt.Bucket, block *types.Block) error {
+	if block.Evidence == nil {
+		if err := evidenceBucket.Delete(bb.itob(block.BlockHeight)); err != nil {
+			bb.logger.Error("Error deleting finality evidence", zap.Error(err))
+			return err
+		}
+		return nil
+	}
+
+	evidence := *block.Evidence
+	evidence.BlockHeight = block.BlockHeight
+	evidence.BlockHash = block.BlockHash
+	bb.logger.Debug("Inserting finality evidence to db", zap.Uint64("block_height", block.BlockHeight))
+	if err := evidenceBucket.Put(bb.itob(block.BlockHeight), encodeEvidence(&evidence)); err != nil {
+		bb.logger.Error("Error inserting finality evidence", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (bb *BBoltHandler) getBlock(tx *bolt.Tx, height uint64) (*types.Block, error) {
+	v := tx.Bucket([]byte(blocksBucket)).Get(bb.itob(height))
+	if v == nil {
+		return nil, types.ErrBlockNotFound
+	}
+	return decodeBlock(v)
+}
+
+// getBlockByHash resolves the hash through the block_heights bucket, checking the block found
+// has this very hash, as hashes not in canonical form are keyed by their string bytes
+func (bb *BBoltHandler) getBlockByHash(tx *bolt.Tx, hash string) (*types.Block, error) {
+	v := tx.Bucket([]byte(blockHeightsBucket)).Get(hashKey(hash))
+	if v == nil {
+		return nil, types.ErrBlockNotFound
+	}
+	block, err := bb.getBlock(tx, bb.btoi(v))
+	if err != nil {
+		return nil, err
+	}
+	if block.BlockHash != hash {
+		return nil, types.ErrBlockNotFound
+	}
+	return block, nil
+}
+
+func (bb *BBoltHandler) getBtcHeader(b *bolt.Bucket, height uint32) (*types.BtcHeader, error) {
+	v := b.Get(bb.itob(uint64(height)))
+	if v == nil {
+		return nil, types.ErrBtcHeaderNotFound
+	}
+	return decodeBtcHeader(v)
+}
+
+func (bb *BBoltHandler) queryBtcHeaderAtEnd(seek func(c *bolt.Cursor) ([]byte, []byte)) (*types.BtcHeader, error) {
+	var header *types.BtcHeader
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		k, v := seek(tx.Bucket([]byte(btcHeadersBucket)).Cursor())
+		if k == nil {
+			return nil
+		}
+		var err error
+		header, err = decodeBtcHeader(v)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
 func (bb *BBoltHandler) itob(v uint64) []byte {
 	buf := new(bytes.Buffer)
 	err := binary.Write(buf, binary.BigEndian, v)
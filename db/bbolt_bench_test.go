@@ -0,0 +1,129 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// Compares the JSON encoding of schema version 1 with the binary encoding, e.g.
+// go test ./db -run '^$' -bench . -benchmem
+
+const benchBatchSize = 100
+
+func benchBlock(height uint64) *types.Block {
+	hash := fmt.Sprintf("0x%064x", height)
+	voters := make([]*types.VoterPower, 0, 10)
+	for i := 0; i < 10; i++ {
+		voters = append(voters, &types.VoterPower{FpBtcPkHex: fmt.Sprintf("%064x", i), Power: 1000})
+	}
+	return &types.Block{
+		BlockHeight:    height,
+		BlockHash:      hash,
+		BlockTimestamp: 1700000000 + height,
+		BabylonHeight:  height / 10,
+		Evidence: &types.FinalityEvidence{
+			BlockHeight:   height,
+			BlockHash:     hash,
+			BabylonHeight: height / 10,
+			BtcHeight:     850000,
+			TotalPower:    15000,
+			VotedPower:    10000,
+			Voters:        voters,
+			QuorumRatio:   2.0 / 3,
+		},
+	}
+}
+
+func BenchmarkBlockEncoding(b *testing.B) {
+	block := benchBlock(1)
+
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			blockBytes, _ := json.Marshal(block)
+			evidenceBytes, _ := json.Marshal(block.Evidence)
+			var decoded types.Block
+			var evidence types.FinalityEvidence
+			require.NoError(b, json.Unmarshal(blockBytes, &decoded))
+			require.NoError(b, json.Unmarshal(evidenceBytes, &evidence))
+		}
+		blockBytes, _ := json.Marshal(block)
+		evidenceBytes, _ := json.Marshal(block.Evidence)
+		b.ReportMetric(float64(len(blockBytes)+len(evidenceBytes)), "bytes/record")
+	})
+
+	b.Run("binary", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, err := decodeBlock(encodeBlock(block))
+			require.NoError(b, err)
+			_, err = decodeEvidence(encodeEvidence(block.Evidence))
+			require.NoError(b, err)
+		}
+		b.ReportMetric(float64(len(encodeBlock(block))+len(encodeEvidence(block.Evidence))), "bytes/record")
+	})
+}
+
+// BenchmarkBBoltInsertBlocks inserts batches of blocks with their evidence, and reports the
+// resulting db file size per block
+func BenchmarkBBoltInsertBlocks(b *testing.B) {
+	for _, encoding := range []string{"json", "binary"} {
+		b.Run(encoding, func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), "bench.db")
+			handler, err := NewBBoltHandler(path, zap.NewNop())
+			require.NoError(b, err)
+			defer handler.Close()
+			require.NoError(b, handler.CreateInitialSchema())
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				blocks := make([]*types.Block, 0, benchBatchSize)
+				for j := 0; j < benchBatchSize; j++ {
+					blocks = append(blocks, benchBlock(uint64(i*benchBatchSize+j)))
+				}
+				if encoding == "json" {
+					writeJSONRecords(b, handler, blocks, nil)
+				} else {
+					require.NoError(b, handler.InsertBlocks(blocks))
+				}
+			}
+			b.StopTimer()
+
+			info, err := os.Stat(path)
+			require.NoError(b, err)
+			b.ReportMetric(float64(info.Size())/float64(b.N*benchBatchSize), "file_bytes/block")
+			b.ReportMetric(float64(b.N*benchBatchSize)/b.Elapsed().Seconds(), "blocks/s")
+		})
+	}
+}
+
+// BenchmarkBBoltGetBlockByHash looks up blocks by hash, through the block_heights bucket
+func BenchmarkBBoltGetBlockByHash(b *testing.B) {
+	handler, err := NewBBoltHandler(filepath.Join(b.TempDir(), "bench.db"), zap.NewNop())
+	require.NoError(b, err)
+	defer handler.Close()
+	require.NoError(b, handler.CreateInitialSchema())
+
+	const count = 10 * benchBatchSize
+	for i := 0; i < count; i += benchBatchSize {
+		blocks := make([]*types.Block, 0, benchBatchSize)
+		for j := 0; j < benchBatchSize; j++ {
+			blocks = append(blocks, benchBlock(uint64(i+j)))
+		}
+		require.NoError(b, handler.InsertBlocks(blocks))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := handler.GetBlockByHash(fmt.Sprintf("0x%064x", i%count))
+		require.NoError(b, err)
+	}
+}
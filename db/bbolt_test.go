@@ -1,13 +1,17 @@
 package db
 
 import (
-	"math"
+	"encoding/json"
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/babylonlabs-io/finality-gadget/log"
 	"github.com/babylonlabs-io/finality-gadget/types"
-	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
 	"go.uber.org/zap"
 )
 
@@ -49,277 +53,215 @@ func setupDB(t *testing.T) (*BBoltHandler, func()) {
 	return db, cleanup
 }
 
-func TestInsertBlocks(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	// Create test blocks
-	blocks := []*types.Block{
-		{
-			BlockHeight:    1,
-			BlockHash:      "0x123",
-			BlockTimestamp: 1000,
-		},
-		{
-			BlockHeight:    2,
-			BlockHash:      "0x456",
-			BlockTimestamp: 1050,
-		},
-		{
-			BlockHeight:    3,
-			BlockHash:      "0x789",
-			BlockTimestamp: 1100,
-		},
-	}
-
-	// Test batch insert
-	err := handler.InsertBlocks(blocks)
-	assert.NoError(t, err)
-
-	// Verify all blocks were inserted correctly
-	for _, block := range blocks {
-		// Check by height
-		retrievedBlock, blockErr := handler.GetBlockByHeight(block.BlockHeight)
-		assert.NoError(t, blockErr)
-		assert.Equal(t, block.BlockHeight, retrievedBlock.BlockHeight)
-		assert.Equal(t, block.BlockHash, retrievedBlock.BlockHash)
-		assert.Equal(t, block.BlockTimestamp, retrievedBlock.BlockTimestamp)
-
-		// Check by hash
-		retrievedBlock, err = handler.GetBlockByHash(block.BlockHash)
-		assert.NoError(t, err)
-		assert.Equal(t, block.BlockHeight, retrievedBlock.BlockHeight)
-		assert.Equal(t, block.BlockHash, retrievedBlock.BlockHash)
-		assert.Equal(t, block.BlockTimestamp, retrievedBlock.BlockTimestamp)
-	}
-
-	// Verify earliest and latest blocks
-	earliest, err := handler.QueryEarliestFinalizedBlock()
-	assert.NoError(t, err)
-	assert.Equal(t, uint64(1), earliest.BlockHeight)
-
-	latest, err := handler.QueryLatestFinalizedBlock()
-	assert.NoError(t, err)
-	assert.Equal(t, uint64(3), latest.BlockHeight)
-
-	// Test empty slice
-	err = handler.InsertBlocks([]*types.Block{})
-	assert.NoError(t, err)
-}
-
-func TestGetBlockByHeight(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	// Insert a block
-	block := &types.Block{
-		BlockHeight:    1,
-		BlockHash:      "0x123",
-		BlockTimestamp: 1000,
-	}
-	err := handler.InsertBlocks([]*types.Block{block})
-	assert.NoError(t, err)
-
-	// Retrieve block by height
-	retrievedBlock, err := handler.GetBlockByHeight(block.BlockHeight)
-	assert.NoError(t, err)
-	assert.Equal(t, block.BlockHeight, retrievedBlock.BlockHeight)
-	assert.Equal(t, block.BlockHash, retrievedBlock.BlockHash)
-	assert.Equal(t, block.BlockTimestamp, retrievedBlock.BlockTimestamp)
-}
-
-func TestGetBlockByHeightForNonExistentBlock(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	block, err := handler.GetBlockByHeight(1)
-	assert.Nil(t, block)
-	assert.Equal(t, types.ErrBlockNotFound, err)
-}
-
-func TestGetBlockByHash(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	// Insert a block
-	block := &types.Block{
-		BlockHeight:    1,
-		BlockHash:      "0x123",
-		BlockTimestamp: 1000,
-	}
-	err := handler.InsertBlocks([]*types.Block{block})
-	assert.NoError(t, err)
-
-	// Retrieve block by hash
-	retrievedBlock, err := handler.GetBlockByHash(block.BlockHash)
-	assert.NoError(t, err)
-	assert.Equal(t, block.BlockHeight, retrievedBlock.BlockHeight)
-	assert.Equal(t, block.BlockHash, retrievedBlock.BlockHash)
-	assert.Equal(t, block.BlockTimestamp, retrievedBlock.BlockTimestamp)
+func TestBBoltHandler(t *testing.T) {
+	runConformanceSuite(t, func(t *testing.T) (IDatabaseHandler, func()) {
+		return setupDB(t)
+	})
 }
 
-func TestGetBlockByHashForNonExistentBlock(t *testing.T) {
+func TestBBoltSchemaVersion(t *testing.T) {
 	handler, cleanup := setupDB(t)
 	defer cleanup()
 
-	block, err := handler.GetBlockByHash("0x123")
-	assert.Nil(t, block)
-	assert.Equal(t, types.ErrBlockNotFound, err)
+	// A new db is created at the latest version, with nothing to migrate
+	version, err := handler.SchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, latestBBoltSchemaVersion(), version)
+	migrations, err := handler.Migrate(false)
+	require.NoError(t, err)
+	require.Empty(t, migrations)
+
+	// A db from a newer release is refused
+	require.NoError(t, handler.db.Update(func(tx *bolt.Tx) error {
+		return handler.putSchemaVersion(tx, latestBBoltSchemaVersion()+1)
+	}))
+	require.Error(t, handler.CreateInitialSchema())
 }
 
-func TestQueryIsBlockFinalizedByHeight(t *testing.T) {
+func TestBBoltPendingMigrations(t *testing.T) {
 	handler, cleanup := setupDB(t)
 	defer cleanup()
 
-	// Insert a block
-	block := &types.Block{
-		BlockHeight:    1,
-		BlockHash:      "0x123",
-		BlockTimestamp: 1000,
-	}
-	err := handler.InsertBlocks([]*types.Block{block})
-	assert.NoError(t, err)
-
-	// Retrieve block status by height
-	isFinalized, err := handler.QueryIsBlockFinalizedByHeight(block.BlockHeight)
-	assert.NoError(t, err)
-	assert.Equal(t, isFinalized, true)
+	pending, err := handler.PendingMigrations()
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// A db behind the latest version lists the migrations Migrate would apply
+	require.NoError(t, handler.db.Update(func(tx *bolt.Tx) error {
+		return handler.putSchemaVersion(tx, 1)
+	}))
+	pending, err = handler.PendingMigrations()
+	require.NoError(t, err)
+	require.Len(t, pending, len(bboltMigrations)-1)
+	require.Equal(t, latestBBoltSchemaVersion(), pending[len(pending)-1].Version)
+
+	// A db from a newer release is refused
+	require.NoError(t, handler.db.Update(func(tx *bolt.Tx) error {
+		return handler.putSchemaVersion(tx, latestBBoltSchemaVersion()+1)
+	}))
+	_, err = handler.PendingMigrations()
+	require.Error(t, err)
 }
 
-func TestQueryIsBlockFinalizedByHeightForNonExistentBlock(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	isFinalized, err := handler.QueryIsBlockFinalizedByHeight(1)
-	assert.NoError(t, err)
-	assert.Equal(t, isFinalized, false)
+func TestOpenHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.db")
+
+	// A missing db is reported rather than created
+	_, err := OpenHandler(BBoltBackend, path, true, zap.NewNop())
+	require.ErrorIs(t, err, os.ErrNotExist)
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	handler, err := NewBBoltHandler(path, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, handler.CreateInitialSchema())
+	require.NoError(t, handler.SaveActivatedTimestamp(1000))
+	require.NoError(t, handler.Close())
+
+	// A read-only db can be read but not written
+	readOnly, err := OpenHandler(BBoltBackend, path, true, zap.NewNop())
+	require.NoError(t, err)
+	defer readOnly.Close()
+	timestamp, err := readOnly.GetActivatedTimestamp()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000), timestamp)
+	require.Error(t, readOnly.SaveActivatedTimestamp(2000))
+
+	_, err = OpenHandler(MemoryBackend, "", true, zap.NewNop())
+	require.Error(t, err)
 }
 
-func TestQueryIsBlockFinalizedByHash(t *testing.T) {
+func TestBBoltMigrate(t *testing.T) {
 	handler, cleanup := setupDB(t)
 	defer cleanup()
 
-	// Insert a block
+	// Simulate a db from before schema versioning, with JSON records
 	block := &types.Block{
 		BlockHeight:    1,
-		BlockHash:      "0x123",
+		BlockHash:      "0x" + strings.Repeat("ab", 32),
 		BlockTimestamp: 1000,
+		BabylonHeight:  10,
+		Evidence: &types.FinalityEvidence{
+			BabylonHeight: 10,
+			BtcHeight:     100,
+			TotalPower:    300,
+			VotedPower:    200,
+			Voters:        []*types.VoterPower{{FpBtcPkHex: strings.Repeat("cd", 32), Power: 200}},
+			QuorumRatio:   2.0 / 3,
+		},
 	}
-	err := handler.InsertBlocks([]*types.Block{block})
-	assert.NoError(t, err)
-
-	// Retrieve block status by hash
-	isFinalized, err := handler.QueryIsBlockFinalizedByHash(block.BlockHash)
-	assert.NoError(t, err)
-	assert.Equal(t, isFinalized, true)
+	header := &types.BtcHeader{Height: 100, Hash: strings.Repeat("ef", 32), Timestamp: 900}
+	writeJSONRecords(t, handler, []*types.Block{block}, []*types.BtcHeader{header})
+	require.NoError(t, handler.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(indexerBucket)).Delete([]byte(schemaVersionKey))
+	}))
+	require.NoError(t, handler.CreateInitialSchema())
+	version, err := handler.SchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), version)
+
+	// A dry run reports the pending migrations without applying them
+	migrations, err := handler.Migrate(true)
+	require.NoError(t, err)
+	require.Len(t, migrations, len(bboltMigrations))
+	version, err = handler.SchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), version)
+
+	migrations, err = handler.Migrate(false)
+	require.NoError(t, err)
+	require.Len(t, migrations, len(bboltMigrations))
+	version, err = handler.SchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, latestBBoltSchemaVersion(), version)
+
+	// The records read back the same from the binary encoding
+	storedBlock, err := handler.GetBlockByHash(block.BlockHash)
+	require.NoError(t, err)
+	require.Equal(t, &types.Block{
+		BlockHeight:    block.BlockHeight,
+		BlockHash:      block.BlockHash,
+		BlockTimestamp: block.BlockTimestamp,
+		BabylonHeight:  block.BabylonHeight,
+	}, storedBlock)
+	evidence, err := handler.GetEvidenceByHash(block.BlockHash)
+	require.NoError(t, err)
+	require.Equal(t, block.Evidence.Voters, evidence.Voters)
+	// the observed ratio stored by older releases isn't the configured quorum, so it's reset
+	require.Zero(t, evidence.QuorumRatio)
+	require.Zero(t, evidence.QuorumPower)
+	require.Equal(t, block.Evidence.VotedPower, evidence.VotedPower)
+	require.Equal(t, block.BlockHeight, evidence.BlockHeight)
+	storedHeader, err := handler.GetBtcHeaderByHeight(header.Height)
+	require.NoError(t, err)
+	require.Equal(t, header, storedHeader)
 }
 
-func TestQueryIsBlockFinalizedByHashForNonExistentBlock(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	isFinalized, err := handler.QueryIsBlockFinalizedByHash("0x123")
-	assert.NoError(t, err)
-	assert.Equal(t, isFinalized, false)
-}
-
-func TestQueryEarliestFinalizedBlock(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	// Insert two blocks
-	first := &types.Block{
-		BlockHeight:    1,
-		BlockHash:      "0x123",
-		BlockTimestamp: 1000,
-	}
-	second := &types.Block{
-		BlockHeight:    2,
-		BlockHash:      "0x456",
-		BlockTimestamp: 1050,
-	}
-	third := &types.Block{
-		BlockHeight:    3,
-		BlockHash:      "0x789",
-		BlockTimestamp: 1100,
-	}
-	err := handler.InsertBlocks([]*types.Block{first, second, third})
-	assert.NoError(t, err)
-
-	// Query earliest consecutively finalized block
-	earliestBlock, err := handler.QueryEarliestFinalizedBlock()
-	assert.NoError(t, err)
-	assert.Equal(t, earliestBlock.BlockHeight, first.BlockHeight)
-	assert.Equal(t, earliestBlock.BlockHash, first.BlockHash)
-	assert.Equal(t, earliestBlock.BlockTimestamp, first.BlockTimestamp)
+// writeJSONRecords writes blocks, their evidence and BTC headers in the JSON encoding of schema version 1
+func writeJSONRecords(t testing.TB, handler *BBoltHandler, blocks []*types.Block, headers []*types.BtcHeader) {
+	err := handler.db.Update(func(tx *bolt.Tx) error {
+		for _, block := range blocks {
+			blockBytes, err := json.Marshal(block)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket([]byte(blocksBucket)).Put(handler.itob(block.BlockHeight), blockBytes); err != nil {
+				return err
+			}
+			if err := tx.Bucket([]byte(blockHeightsBucket)).Put([]byte(block.BlockHash), handler.itob(block.BlockHeight)); err != nil {
+				return err
+			}
+			if block.Evidence != nil {
+				evidence := *block.Evidence
+				evidence.BlockHeight = block.BlockHeight
+				evidence.BlockHash = block.BlockHash
+				evidenceBytes, err := json.Marshal(&evidence)
+				if err != nil {
+					return err
+				}
+				if err := tx.Bucket([]byte(evidenceBucket)).Put(handler.itob(block.BlockHeight), evidenceBytes); err != nil {
+					return err
+				}
+			}
+		}
+		for _, header := range headers {
+			headerBytes, err := json.Marshal(header)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket([]byte(btcHeadersBucket)).Put(handler.itob(uint64(header.Height)), headerBytes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
 }
 
-func TestQueryLatestFinalizedBlock(t *testing.T) {
+func TestBBoltMigrateFailure(t *testing.T) {
 	handler, cleanup := setupDB(t)
 	defer cleanup()
 
-	// Insert two blocks
-	first := &types.Block{
-		BlockHeight:    1,
-		BlockHash:      "0x123",
-		BlockTimestamp: 1000,
-	}
-	second := &types.Block{
-		BlockHeight:    2,
-		BlockHash:      "0x456",
-		BlockTimestamp: 1050,
+	latest := latestBBoltSchemaVersion()
+	defer func(migrations []bboltMigration) { bboltMigrations = migrations }(bboltMigrations)
+	bboltMigrations = append(bboltMigrations, bboltMigration{
+		Migration: Migration{Version: latest + 1, Description: "failing migration"},
+		migrate: func(bb *BBoltHandler, tx *bolt.Tx) error {
+			if err := tx.Bucket([]byte(indexerBucket)).Put([]byte(activatedTimestampKey), bb.itob(1000)); err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	})
+
+	// Neither the migration's writes nor the version bump are kept
+	for _, dryRun := range []bool{true, false} {
+		_, err := handler.Migrate(dryRun)
+		require.Error(t, err)
+		version, err := handler.SchemaVersion()
+		require.NoError(t, err)
+		require.Equal(t, latest, version)
+		_, err = handler.GetActivatedTimestamp()
+		require.ErrorIs(t, err, types.ErrActivatedTimestampNotFound)
 	}
-	err := handler.InsertBlocks([]*types.Block{first, second})
-	assert.NoError(t, err)
-
-	// Retrieve latest block
-	latestBlock, err := handler.QueryLatestFinalizedBlock()
-	assert.NoError(t, err)
-	assert.Equal(t, latestBlock.BlockHeight, second.BlockHeight)
-	assert.Equal(t, latestBlock.BlockHash, second.BlockHash)
-	assert.Equal(t, latestBlock.BlockTimestamp, second.BlockTimestamp)
-}
-
-func TestQueryLatestFinalizedBlockNonExistent(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	latestBlock, err := handler.QueryLatestFinalizedBlock()
-	assert.Nil(t, latestBlock)
-	assert.NoError(t, err)
-}
-
-func TestGetActivatedTimestamp(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	// Test when timestamp is not set
-	timestamp, err := handler.GetActivatedTimestamp()
-	assert.Equal(t, uint64(math.MaxUint64), timestamp)
-	assert.Equal(t, types.ErrActivatedTimestampNotFound, err)
-
-	// Set timestamp
-	expectedTimestamp := uint64(1234567890)
-	err = handler.SaveActivatedTimestamp(expectedTimestamp)
-	assert.NoError(t, err)
-
-	// Test when timestamp is set
-	timestamp, err = handler.GetActivatedTimestamp()
-	assert.NoError(t, err)
-	assert.Equal(t, expectedTimestamp, timestamp)
-}
-
-func TestSaveActivatedTimestamp(t *testing.T) {
-	handler, cleanup := setupDB(t)
-	defer cleanup()
-
-	// Set timestamp
-	expectedTimestamp := uint64(1234567890)
-	err := handler.SaveActivatedTimestamp(expectedTimestamp)
-	assert.NoError(t, err)
-
-	// Verify timestamp was saved
-	timestamp, err := handler.GetActivatedTimestamp()
-	assert.NoError(t, err)
-	assert.Equal(t, expectedTimestamp, timestamp)
 }
@@ -0,0 +1,287 @@
+package db
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// CheckIssueKind classifies an inconsistency found by a DB check
+type CheckIssueKind string
+
+const (
+	// IssueCorruptBlock is a block record that doesn't decode, or is stored under another height. Not repairable.
+	IssueCorruptBlock CheckIssueKind = "corrupt_block"
+	// IssueMissingHashMapping is a block whose hash doesn't map to its height
+	IssueMissingHashMapping CheckIssueKind = "missing_hash_mapping"
+	// IssueStaleHashMapping is a hash mapping to a height with no block of that hash
+	IssueStaleHashMapping CheckIssueKind = "stale_hash_mapping"
+	// IssueOrphanEvidence is finality evidence with no block of its height and hash
+	IssueOrphanEvidence CheckIssueKind = "orphan_evidence"
+	// IssueIndexMismatch is an earliest or latest index not matching the lowest or highest stored block
+	IssueIndexMismatch CheckIssueKind = "index_mismatch"
+	// IssueOffInterval is a block stored at a height that isn't a finality signature interval. Not repairable.
+	IssueOffInterval CheckIssueKind = "off_interval_block"
+	// IssueGap is a range of finality signature interval heights missing between two stored blocks.
+	// Not repairable, the blocks have to be processed again.
+	IssueGap CheckIssueKind = "gap"
+)
+
+// maxReportedIssues bounds the issues listed in a check report, all issues are still counted
+const maxReportedIssues = 1000
+
+// CheckOptions configures a DB check
+type CheckOptions struct {
+	// BsnActivationHeight and FinalitySignatureInterval set the heights blocks are stored at,
+	// gaps aren't checked when FinalitySignatureInterval is 0
+	BsnActivationHeight       uint64
+	FinalitySignatureInterval uint64
+	// Repair fixes the repairable issues: hash mappings and evidence are rebuilt from the blocks,
+	// and the earliest and latest indices reset to the stored blocks
+	Repair bool
+}
+
+// CheckIssue is an inconsistency found by a DB check
+type CheckIssue struct {
+	Kind     CheckIssueKind
+	Height   uint64
+	Detail   string
+	Repaired bool
+}
+
+// CheckReport is the result of a DB check
+type CheckReport struct {
+	Blocks         uint64
+	EarliestHeight uint64
+	LatestHeight   uint64
+	// Counts holds the number of issues of each kind, Issues the first maxReportedIssues of them
+	Counts   map[CheckIssueKind]uint64
+	Issues   []CheckIssue
+	Repaired uint64
+}
+
+// Checker is implemented by the db handlers that can check the consistency of their indices
+type Checker interface {
+	// Check scans the DB for inconsistencies between the blocks and their indices
+	Check(opts CheckOptions) (*CheckReport, error)
+}
+
+var _ Checker = &BBoltHandler{}
+
+// Total returns the number of issues found by the check
+func (r *CheckReport) Total() uint64 {
+	var total uint64
+	for _, count := range r.Counts {
+		total += count
+	}
+	return total
+}
+
+// Unrepaired returns the number of issues left after the check
+func (r *CheckReport) Unrepaired() uint64 {
+	return r.Total() - r.Repaired
+}
+
+func (r *CheckReport) add(issue CheckIssue) {
+	if r.Counts == nil {
+		r.Counts = make(map[CheckIssueKind]uint64)
+	}
+	r.Counts[issue.Kind]++
+	if issue.Repaired {
+		r.Repaired++
+	}
+	if len(r.Issues) < maxReportedIssues {
+		r.Issues = append(r.Issues, issue)
+	}
+}
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+/* Check scans the blocks, block_heights, evidence and indexer buckets for inconsistencies
+ *
+ * - every block must be stored under its height, and its hash must map back to that height
+ * - every hash mapping must point to a block with that hash
+ * - every finality evidence must belong to the block stored at its height
+ * - the earliest and latest indices must be the lowest and highest stored heights
+ * - with a finality signature interval, blocks must be at interval heights and no interval height
+ *   may be missing between the earliest and latest blocks
+ * - with Repair, the check runs in a write transaction and fixes what it can; without, it only reads
+ */
+func (bb *BBoltHandler) Check(opts CheckOptions) (*CheckReport, error) {
+	report := &CheckReport{}
+	check := func(tx *bolt.Tx) error {
+		return bb.check(tx, opts, report)
+	}
+
+	var err error
+	if opts.Repair {
+		err = bb.db.Update(check)
+	} else {
+		err = bb.db.View(check)
+	}
+	if err != nil {
+		bb.logger.Error("Error checking DB", zap.Error(err))
+		return nil, err
+	}
+	return report, nil
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+func (bb *BBoltHandler) check(tx *bolt.Tx, opts CheckOptions, report *CheckReport) error {
+	blocksBucket := tx.Bucket([]byte(blocksBucket))
+	heightsBucket := tx.Bucket([]byte(blockHeightsBucket))
+	evidenceBucket := tx.Bucket([]byte(evidenceBucket))
+	indexBucket := tx.Bucket([]byte(indexerBucket))
+
+	// Writes are deferred until the scans are done, as writing while iterating a cursor may skip entries
+	var repairs []func() error
+	repair := func(issue CheckIssue, fix func() error) {
+		if opts.Repair {
+			issue.Repaired = true
+			repairs = append(repairs, fix)
+		}
+		report.add(issue)
+	}
+
+	// Hash mappings pointing to no block of that hash. Scanned first, so their deletions are applied
+	// before the mappings of the blocks are restored
+	err := heightsBucket.ForEach(func(k, v []byte) error {
+		if block := blocksBucket.Get(v); block != nil {
+			if decoded, err := decodeBlock(block); err == nil && bytes.Equal(hashKey(decoded.BlockHash), k) {
+				return nil
+			}
+		}
+		key := append([]byte(nil), k...)
+		issue := CheckIssue{Kind: IssueStaleHashMapping, Detail: fmt.Sprintf("hash key %x maps to no block with that hash", k)}
+		if len(v) == 8 {
+			issue.Height = binary.BigEndian.Uint64(v)
+		}
+		repair(issue, func() error { return heightsBucket.Delete(key) })
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Blocks and their hash mappings
+	var first, prevHeight, prevIntervalHeight uint64
+	hasBlocks, hasIntervalBlocks := false, false
+	err = blocksBucket.ForEach(func(k, v []byte) error {
+		block, err := decodeBlock(v)
+		if err != nil || len(k) != 8 || binary.BigEndian.Uint64(k) != block.BlockHeight {
+			detail := fmt.Sprintf("block record under key %x doesn't decode to a block of that height", k)
+			if err != nil {
+				detail = fmt.Sprintf("%s: %v", detail, err)
+			}
+			report.add(CheckIssue{Kind: IssueCorruptBlock, Detail: detail})
+			return nil
+		}
+		height := block.BlockHeight
+		report.Blocks++
+
+		mapped := heightsBucket.Get(hashKey(block.BlockHash))
+		if mapped == nil || !bytes.Equal(mapped, k) {
+			key := append([]byte(nil), k...)
+			hash := hashKey(block.BlockHash)
+			repair(CheckIssue{
+				Kind:   IssueMissingHashMapping,
+				Height: height,
+				Detail: fmt.Sprintf("hash %s doesn't map to height %d", block.BlockHash, height),
+			}, func() error { return heightsBucket.Put(hash, key) })
+		}
+
+		if opts.FinalitySignatureInterval > 0 {
+			if height < opts.BsnActivationHeight || (height-opts.BsnActivationHeight)%opts.FinalitySignatureInterval != 0 {
+				report.add(CheckIssue{
+					Kind:   IssueOffInterval,
+					Height: height,
+					Detail: fmt.Sprintf("height %d isn't a finality signature interval height", height),
+				})
+			} else {
+				if hasIntervalBlocks && height > prevIntervalHeight+opts.FinalitySignatureInterval {
+					report.add(CheckIssue{
+						Kind:   IssueGap,
+						Height: prevIntervalHeight + opts.FinalitySignatureInterval,
+						Detail: fmt.Sprintf("%d interval heights missing between %d and %d",
+							(height-prevIntervalHeight)/opts.FinalitySignatureInterval-1, prevIntervalHeight, height),
+					})
+				}
+				prevIntervalHeight, hasIntervalBlocks = height, true
+			}
+		}
+
+		if !hasBlocks {
+			first, hasBlocks = height, true
+		}
+		prevHeight = height
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if hasBlocks {
+		report.EarliestHeight, report.LatestHeight = first, prevHeight
+	}
+
+	// Evidence of blocks no longer stored, or of another block at that height
+	err = evidenceBucket.ForEach(func(k, v []byte) error {
+		if block := blocksBucket.Get(k); block != nil {
+			decodedBlock, blockErr := decodeBlock(block)
+			evidence, evidenceErr := decodeEvidence(v)
+			if blockErr == nil && evidenceErr == nil && evidence.BlockHash == decodedBlock.BlockHash {
+				return nil
+			}
+		}
+		key := append([]byte(nil), k...)
+		issue := CheckIssue{Kind: IssueOrphanEvidence, Detail: fmt.Sprintf("evidence under key %x belongs to no stored block", k)}
+		if len(k) == 8 {
+			issue.Height = binary.BigEndian.Uint64(k)
+		}
+		repair(issue, func() error { return evidenceBucket.Delete(key) })
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Earliest and latest indices
+	for _, index := range []struct {
+		key    string
+		height uint64
+	}{{earliestBlockKey, report.EarliestHeight}, {latestBlockKey, report.LatestHeight}} {
+		key := []byte(index.key)
+		stored := indexBucket.Get(key)
+		if !hasBlocks {
+			if stored != nil {
+				repair(CheckIssue{
+					Kind:   IssueIndexMismatch,
+					Detail: fmt.Sprintf("%s index is set with no block stored", index.key),
+				}, func() error { return indexBucket.Delete(key) })
+			}
+			continue
+		}
+		if len(stored) != 8 || binary.BigEndian.Uint64(stored) != index.height {
+			value := bb.itob(index.height)
+			repair(CheckIssue{
+				Kind:   IssueIndexMismatch,
+				Height: index.height,
+				Detail: fmt.Sprintf("%s index is %x, the stored blocks end at height %d", index.key, stored, index.height),
+			}, func() error { return indexBucket.Put(key, value) })
+		}
+	}
+
+	for _, fix := range repairs {
+		if err := fix(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func testCheckBlock(height uint64) *types.Block {
+	return &types.Block{
+		BlockHeight:    height,
+		BlockHash:      fmt.Sprintf("0x%064x", height),
+		BlockTimestamp: 1000 + height,
+		Evidence:       &types.FinalityEvidence{TotalPower: 3, VotedPower: 2},
+	}
+}
+
+func TestBBoltCheck(t *testing.T) {
+	handler, cleanup := setupDB(t)
+	defer cleanup()
+
+	// Blocks at an interval of 5 from the activation height 100
+	opts := CheckOptions{BsnActivationHeight: 100, FinalitySignatureInterval: 5}
+	for height := uint64(100); height <= 150; height += 5 {
+		require.NoError(t, handler.InsertBlocks([]*types.Block{testCheckBlock(height)}))
+	}
+	report, err := handler.Check(opts)
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), report.Blocks)
+	require.Equal(t, uint64(100), report.EarliestHeight)
+	require.Equal(t, uint64(150), report.LatestHeight)
+	require.Zero(t, report.Total())
+
+	// Break the indices
+	require.NoError(t, handler.db.Update(func(tx *bolt.Tx) error {
+		blocks := tx.Bucket([]byte(blocksBucket))
+		heights := tx.Bucket([]byte(blockHeightsBucket))
+		evidence := tx.Bucket([]byte(evidenceBucket))
+		index := tx.Bucket([]byte(indexerBucket))
+		// the hash of 110 no longer maps to it, a stale hash maps to 115
+		require.NoError(t, heights.Delete(hashKey(testCheckBlock(110).BlockHash)))
+		require.NoError(t, heights.Put(hashKey(fmt.Sprintf("0x%064x", 999)), handler.itob(115)))
+		// 125 and 130 are lost, leaving their evidence behind
+		require.NoError(t, blocks.Delete(handler.itob(125)))
+		require.NoError(t, blocks.Delete(handler.itob(130)))
+		// a block off the interval
+		require.NoError(t, blocks.Put(handler.itob(142), encodeBlock(testCheckBlock(142))))
+		require.NoError(t, heights.Put(hashKey(testCheckBlock(142).BlockHash), handler.itob(142)))
+		require.NoError(t, evidence.Put(handler.itob(500), encodeEvidence(&types.FinalityEvidence{BlockHeight: 500})))
+		return index.Put([]byte(latestBlockKey), handler.itob(200))
+	}))
+
+	report, err = handler.Check(opts)
+	require.NoError(t, err)
+	require.Equal(t, map[CheckIssueKind]uint64{
+		IssueMissingHashMapping: 1,
+		// the mappings of the lost blocks, and the stale hash
+		IssueStaleHashMapping: 3,
+		IssueOrphanEvidence:   3,
+		IssueIndexMismatch:    1,
+		IssueOffInterval:      1,
+		IssueGap:              1,
+	}, report.Counts)
+	require.Zero(t, report.Repaired)
+	for _, issue := range report.Issues {
+		if issue.Kind == IssueGap {
+			require.Equal(t, uint64(125), issue.Height)
+		}
+	}
+
+	// Repairing fixes everything but the gap and the block off the interval
+	report, err = handler.Check(CheckOptions{BsnActivationHeight: 100, FinalitySignatureInterval: 5, Repair: true})
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), report.Repaired)
+	require.Equal(t, uint64(2), report.Unrepaired())
+
+	report, err = handler.Check(opts)
+	require.NoError(t, err)
+	require.Equal(t, map[CheckIssueKind]uint64{IssueOffInterval: 1, IssueGap: 1}, report.Counts)
+	block, err := handler.GetBlockByHash(testCheckBlock(110).BlockHash)
+	require.NoError(t, err)
+	require.Equal(t, uint64(110), block.BlockHeight)
+	latest, err := handler.QueryLatestFinalizedBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(150), latest.BlockHeight)
+
+	// Without an interval, gaps aren't checked
+	report, err = handler.Check(CheckOptions{})
+	require.NoError(t, err)
+	require.Zero(t, report.Total())
+}
+
+func TestBBoltCheckEmpty(t *testing.T) {
+	handler, cleanup := setupDB(t)
+	defer cleanup()
+
+	require.NoError(t, handler.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(indexerBucket)).Put([]byte(earliestBlockKey), handler.itob(10))
+	}))
+	report, err := handler.Check(CheckOptions{Repair: true})
+	require.NoError(t, err)
+	require.Equal(t, map[CheckIssueKind]uint64{IssueIndexMismatch: 1}, report.Counts)
+	require.Zero(t, report.Unrepaired())
+
+	earliest, err := handler.QueryEarliestFinalizedBlock()
+	require.ErrorIs(t, err, types.ErrBlockNotFound)
+	require.Nil(t, earliest)
+}
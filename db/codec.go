@@ -0,0 +1,269 @@
+package db
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+)
+
+/* Binary encoding of the records stored by BBoltHandler
+ *
+ * - integers are fixed-width big endian, the float quorum ratio is stored as its IEEE 754 bits
+ * - hashes and keys in their canonical lowercase hex form ("0x" + 64 hex chars for L2 hashes,
+ *   64 hex chars for BTC hashes and FP public keys) are stored as 32 raw bytes behind a one byte tag,
+ *   anything else as a length-prefixed string so every value round trips exactly
+ * - hashes used as keys are the 32 raw bytes alone, other strings are used verbatim; lookups by hash
+ *   check the hash of the record found, so the two can't be confused
+ */
+
+// Tags of the hash encodings
+const (
+	hashTagString     byte = 0 // length-prefixed string
+	hashTagPrefixed   byte = 1 // "0x" + 64 hex chars, as 32 raw bytes
+	hashTagUnprefixed byte = 2 // 64 hex chars, as 32 raw bytes
+)
+
+const rawHashLen = 32
+
+var errTruncatedRecord = errors.New("truncated record")
+
+func encodeBlock(block *types.Block) []byte {
+	buf := make([]byte, 0, 1+rawHashLen+3*8)
+	buf = appendHash(buf, block.BlockHash)
+	buf = binary.BigEndian.AppendUint64(buf, block.BlockHeight)
+	buf = binary.BigEndian.AppendUint64(buf, block.BlockTimestamp)
+	return binary.BigEndian.AppendUint64(buf, block.BabylonHeight)
+}
+
+func decodeBlock(data []byte) (*types.Block, error) {
+	r := &recordReader{data: data}
+	block := &types.Block{
+		BlockHash:      r.hash(),
+		BlockHeight:    r.uint64(),
+		BlockTimestamp: r.uint64(),
+		BabylonHeight:  r.uint64(),
+	}
+	if err := r.done(); err != nil {
+		return nil, fmt.Errorf("failed to decode block: %w", err)
+	}
+	return block, nil
+}
+
+func encodeEvidence(evidence *types.FinalityEvidence) []byte {
+	return encodeEvidenceWith(evidence, true)
+}
+
+// encodeEvidenceV2 encodes evidence with the layout of schema version 2, which has no quorum power
+func encodeEvidenceV2(evidence *types.FinalityEvidence) []byte {
+	return encodeEvidenceWith(evidence, false)
+}
+
+func encodeEvidenceWith(evidence *types.FinalityEvidence, hasQuorumPower bool) []byte {
+	buf := make([]byte, 0, 1+rawHashLen+5*8+4+4+len(evidence.Voters)*(1+rawHashLen+8))
+	buf = binary.BigEndian.AppendUint64(buf, evidence.BlockHeight)
+	buf = appendHash(buf, evidence.BlockHash)
+	buf = binary.BigEndian.AppendUint64(buf, evidence.BabylonHeight)
+	buf = binary.BigEndian.AppendUint32(buf, evidence.BtcHeight)
+	buf = binary.BigEndian.AppendUint64(buf, evidence.TotalPower)
+	buf = binary.BigEndian.AppendUint64(buf, evidence.VotedPower)
+	buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(evidence.QuorumRatio))
+	if hasQuorumPower {
+		buf = binary.BigEndian.AppendUint64(buf, evidence.QuorumPower)
+	}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(evidence.Voters)))
+	for _, voter := range evidence.Voters {
+		buf = appendHash(buf, voter.FpBtcPkHex)
+		buf = binary.BigEndian.AppendUint64(buf, voter.Power)
+	}
+	return buf
+}
+
+func decodeEvidence(data []byte) (*types.FinalityEvidence, error) {
+	return decodeEvidenceWith(data, true)
+}
+
+// decodeEvidenceV2 decodes evidence of schema version 2, which has no quorum power
+func decodeEvidenceV2(data []byte) (*types.FinalityEvidence, error) {
+	return decodeEvidenceWith(data, false)
+}
+
+func decodeEvidenceWith(data []byte, hasQuorumPower bool) (*types.FinalityEvidence, error) {
+	r := &recordReader{data: data}
+	evidence := &types.FinalityEvidence{
+		BlockHeight:   r.uint64(),
+		BlockHash:     r.hash(),
+		BabylonHeight: r.uint64(),
+		BtcHeight:     r.uint32(),
+		TotalPower:    r.uint64(),
+		VotedPower:    r.uint64(),
+		QuorumRatio:   math.Float64frombits(r.uint64()),
+	}
+	if hasQuorumPower {
+		evidence.QuorumPower = r.uint64()
+	}
+	voterCount := r.uint32()
+	// every voter takes at least 10 bytes, bound the count before allocating
+	if r.err == nil && uint64(voterCount)*10 > uint64(len(r.data)) {
+		r.err = errTruncatedRecord
+	}
+	if r.err == nil && voterCount > 0 {
+		evidence.Voters = make([]*types.VoterPower, 0, voterCount)
+		for i := uint32(0); i < voterCount && r.err == nil; i++ {
+			evidence.Voters = append(evidence.Voters, &types.VoterPower{
+				FpBtcPkHex: r.hash(),
+				Power:      r.uint64(),
+			})
+		}
+	}
+	if err := r.done(); err != nil {
+		return nil, fmt.Errorf("failed to decode finality evidence: %w", err)
+	}
+	return evidence, nil
+}
+
+func encodeBtcHeader(header *types.BtcHeader) []byte {
+	buf := make([]byte, 0, 4+1+rawHashLen+8)
+	buf = binary.BigEndian.AppendUint32(buf, header.Height)
+	buf = appendHash(buf, header.Hash)
+	return binary.BigEndian.AppendUint64(buf, header.Timestamp)
+}
+
+func decodeBtcHeader(data []byte) (*types.BtcHeader, error) {
+	r := &recordReader{data: data}
+	header := &types.BtcHeader{
+		Height:    r.uint32(),
+		Hash:      r.hash(),
+		Timestamp: r.uint64(),
+	}
+	if err := r.done(); err != nil {
+		return nil, fmt.Errorf("failed to decode BTC header: %w", err)
+	}
+	return header, nil
+}
+
+func encodeCheckpoint(checkpoint *types.ProcessingCheckpoint) []byte {
+	buf := make([]byte, 0, 8+1+8)
+	buf = binary.BigEndian.AppendUint64(buf, checkpoint.Height)
+	buf = append(buf, byte(checkpoint.Outcome))
+	return binary.BigEndian.AppendUint64(buf, checkpoint.Timestamp)
+}
+
+func decodeCheckpoint(data []byte) (*types.ProcessingCheckpoint, error) {
+	r := &recordReader{data: data}
+	checkpoint := &types.ProcessingCheckpoint{Height: r.uint64()}
+	if b := r.next(1); b != nil {
+		checkpoint.Outcome = types.CheckpointOutcome(b[0])
+	}
+	checkpoint.Timestamp = r.uint64()
+	if err := r.done(); err != nil {
+		return nil, fmt.Errorf("failed to decode processing checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// hashKey returns the key of a hash in the block_heights bucket
+func hashKey(hash string) []byte {
+	if _, raw, ok := parseHash(hash); ok {
+		return raw
+	}
+	return []byte(hash)
+}
+
+// parseHash returns the tag and raw bytes of a hash in canonical form
+func parseHash(hash string) (byte, []byte, bool) {
+	tag, digits := hashTagUnprefixed, hash
+	if strings.HasPrefix(hash, "0x") {
+		tag, digits = hashTagPrefixed, hash[2:]
+	}
+	// only lowercase digits round trip through hex.EncodeToString
+	if len(digits) != 2*rawHashLen || strings.ToLower(digits) != digits {
+		return 0, nil, false
+	}
+	raw, err := hex.DecodeString(digits)
+	if err != nil {
+		return 0, nil, false
+	}
+	return tag, raw, true
+}
+
+func appendHash(buf []byte, hash string) []byte {
+	if tag, raw, ok := parseHash(hash); ok {
+		return append(append(buf, tag), raw...)
+	}
+	buf = append(buf, hashTagString)
+	buf = binary.AppendUvarint(buf, uint64(len(hash)))
+	return append(buf, hash...)
+}
+
+// recordReader decodes fixed-width fields, keeping the first error so fields can be read in sequence
+type recordReader struct {
+	data []byte
+	err  error
+}
+
+func (r *recordReader) next(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.data)) < n {
+		r.err = errTruncatedRecord
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *recordReader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *recordReader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *recordReader) hash() string {
+	tag := r.next(1)
+	if tag == nil {
+		return ""
+	}
+	switch tag[0] {
+	case hashTagPrefixed:
+		return "0x" + hex.EncodeToString(r.next(rawHashLen))
+	case hashTagUnprefixed:
+		return hex.EncodeToString(r.next(rawHashLen))
+	case hashTagString:
+		if r.err != nil {
+			return ""
+		}
+		n, size := binary.Uvarint(r.data)
+		if size <= 0 {
+			r.err = errTruncatedRecord
+			return ""
+		}
+		r.data = r.data[size:]
+		return string(r.next(n))
+	default:
+		r.err = fmt.Errorf("unknown hash encoding %d", tag[0])
+		return ""
+	}
+}
+
+// done returns the first decoding error, or an error if bytes are left over
+func (r *recordReader) done() error {
+	if r.err == nil && len(r.data) != 0 {
+		return fmt.Errorf("%d unexpected trailing bytes", len(r.data))
+	}
+	return r.err
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockCodec(t *testing.T) {
+	for _, hash := range []string{
+		"0x" + strings.Repeat("ab", 32), // canonical, stored as raw bytes
+		"0x" + strings.Repeat("AB", 32), // uppercase, kept verbatim
+		strings.Repeat("ab", 32),        // unprefixed
+		"0x123",                         // short
+		"",
+	} {
+		block := &types.Block{BlockHash: hash, BlockHeight: 42, BlockTimestamp: 1000, BabylonHeight: 7}
+		decoded, err := decodeBlock(encodeBlock(block))
+		require.NoError(t, err)
+		require.Equal(t, block, decoded)
+	}
+
+	// Canonical hashes take 32 bytes rather than their 66 hex chars
+	encoded := encodeBlock(&types.Block{BlockHash: "0x" + strings.Repeat("ab", 32)})
+	require.Len(t, encoded, 1+rawHashLen+3*8)
+	require.Len(t, hashKey("0x"+strings.Repeat("ab", 32)), rawHashLen)
+	require.Equal(t, []byte("0x123"), hashKey("0x123"))
+
+	_, err := decodeBlock(encoded[:len(encoded)-1])
+	require.ErrorIs(t, err, errTruncatedRecord)
+	_, err = decodeBlock(append(encoded, 0))
+	require.Error(t, err)
+}
+
+func TestEvidenceCodec(t *testing.T) {
+	evidence := &types.FinalityEvidence{
+		BlockHeight:   42,
+		BlockHash:     "0x" + strings.Repeat("ab", 32),
+		BabylonHeight: 7,
+		BtcHeight:     100,
+		TotalPower:    300,
+		VotedPower:    200,
+		Voters: []*types.VoterPower{
+			{FpBtcPkHex: strings.Repeat("cd", 32), Power: 150},
+			{FpBtcPkHex: "not-a-key", Power: 50},
+		},
+		QuorumRatio: 2.0 / 3,
+		QuorumPower: 200,
+	}
+	encoded := encodeEvidence(evidence)
+	decoded, err := decodeEvidence(encoded)
+	require.NoError(t, err)
+	require.Equal(t, evidence, decoded)
+
+	_, err = decodeEvidence(encoded[:len(encoded)-1])
+	require.ErrorIs(t, err, errTruncatedRecord)
+}
+
+func TestBtcHeaderCodec(t *testing.T) {
+	header := &types.BtcHeader{Height: 100, Hash: strings.Repeat("ef", 32), Timestamp: 900}
+	decoded, err := decodeBtcHeader(encodeBtcHeader(header))
+	require.NoError(t, err)
+	require.Equal(t, header, decoded)
+}
@@ -0,0 +1,716 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// runConformanceSuite runs the tests every IDatabaseHandler implementation must pass against
+// fresh handlers returned by setup, with their initial schema created
+func runConformanceSuite(t *testing.T, setup func(t *testing.T) (IDatabaseHandler, func())) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, handler IDatabaseHandler)
+	}{
+		{"InsertBlocks", testInsertBlocks},
+		{"GetBlockByHeight", testGetBlockByHeight},
+		{"GetBlockByHeightForNonExistentBlock", testGetBlockByHeightForNonExistentBlock},
+		{"GetBlockByHash", testGetBlockByHash},
+		{"GetBlockByHashForNonExistentBlock", testGetBlockByHashForNonExistentBlock},
+		{"QueryIsBlockFinalizedByHeight", testQueryIsBlockFinalizedByHeight},
+		{"QueryIsBlockFinalizedByHeightForNonExistentBlock", testQueryIsBlockFinalizedByHeightForNonExistentBlock},
+		{"QueryIsBlockFinalizedByHash", testQueryIsBlockFinalizedByHash},
+		{"QueryIsBlockFinalizedByHashForNonExistentBlock", testQueryIsBlockFinalizedByHashForNonExistentBlock},
+		{"QueryEarliestFinalizedBlock", testQueryEarliestFinalizedBlock},
+		{"QueryLatestFinalizedBlock", testQueryLatestFinalizedBlock},
+		{"QueryLatestFinalizedBlockNonExistent", testQueryLatestFinalizedBlockNonExistent},
+		{"GetActivatedTimestamp", testGetActivatedTimestamp},
+		{"SaveActivatedTimestamp", testSaveActivatedTimestamp},
+		{"InsertBlocksReplacesStaleHashMapping", testInsertBlocksReplacesStaleHashMapping},
+		{"DeleteBlocksFromHeight", testDeleteBlocksFromHeight},
+		{"DeleteBlocksBeforeHeight", testDeleteBlocksBeforeHeight},
+		{"ProcessingCheckpoint", testProcessingCheckpoint},
+		{"FinalityEvidence", testFinalityEvidence},
+		{"GetBlocksFromHeight", testGetBlocksFromHeight},
+		{"ListBlocks", testListBlocks},
+		{"BtcHeaderIndex", testBtcHeaderIndex},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, cleanup := setup(t)
+			defer cleanup()
+			tc.test(t, handler)
+		})
+	}
+}
+
+func testInsertBlocks(t *testing.T, handler IDatabaseHandler) {
+
+	// Create test blocks
+	blocks := []*types.Block{
+		{
+			BlockHeight:    1,
+			BlockHash:      "0x123",
+			BlockTimestamp: 1000,
+		},
+		{
+			BlockHeight:    2,
+			BlockHash:      "0x456",
+			BlockTimestamp: 1050,
+		},
+		{
+			BlockHeight:    3,
+			BlockHash:      "0x789",
+			BlockTimestamp: 1100,
+			BabylonHeight:  500,
+		},
+	}
+
+	// Test batch insert
+	err := handler.InsertBlocks(blocks)
+	assert.NoError(t, err)
+
+	// Verify all blocks were inserted correctly
+	for _, block := range blocks {
+		// Check by height
+		retrievedBlock, blockErr := handler.GetBlockByHeight(block.BlockHeight)
+		assert.NoError(t, blockErr)
+		assert.Equal(t, block.BlockHeight, retrievedBlock.BlockHeight)
+		assert.Equal(t, block.BlockHash, retrievedBlock.BlockHash)
+		assert.Equal(t, block.BlockTimestamp, retrievedBlock.BlockTimestamp)
+		assert.Equal(t, block.BabylonHeight, retrievedBlock.BabylonHeight)
+
+		// Check by hash
+		retrievedBlock, err = handler.GetBlockByHash(block.BlockHash)
+		assert.NoError(t, err)
+		assert.Equal(t, block.BlockHeight, retrievedBlock.BlockHeight)
+		assert.Equal(t, block.BlockHash, retrievedBlock.BlockHash)
+		assert.Equal(t, block.BlockTimestamp, retrievedBlock.BlockTimestamp)
+	}
+
+	// Verify earliest and latest blocks
+	earliest, err := handler.QueryEarliestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), earliest.BlockHeight)
+
+	latest, err := handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), latest.BlockHeight)
+
+	// Test empty slice
+	err = handler.InsertBlocks([]*types.Block{})
+	assert.NoError(t, err)
+}
+
+func testGetBlockByHeight(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert a block
+	block := &types.Block{
+		BlockHeight:    1,
+		BlockHash:      "0x123",
+		BlockTimestamp: 1000,
+	}
+	err := handler.InsertBlocks([]*types.Block{block})
+	assert.NoError(t, err)
+
+	// Retrieve block by height
+	retrievedBlock, err := handler.GetBlockByHeight(block.BlockHeight)
+	assert.NoError(t, err)
+	assert.Equal(t, block.BlockHeight, retrievedBlock.BlockHeight)
+	assert.Equal(t, block.BlockHash, retrievedBlock.BlockHash)
+	assert.Equal(t, block.BlockTimestamp, retrievedBlock.BlockTimestamp)
+}
+
+func testGetBlockByHeightForNonExistentBlock(t *testing.T, handler IDatabaseHandler) {
+
+	block, err := handler.GetBlockByHeight(1)
+	assert.Nil(t, block)
+	assert.Equal(t, types.ErrBlockNotFound, err)
+}
+
+func testGetBlockByHash(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert a block
+	block := &types.Block{
+		BlockHeight:    1,
+		BlockHash:      "0x123",
+		BlockTimestamp: 1000,
+	}
+	err := handler.InsertBlocks([]*types.Block{block})
+	assert.NoError(t, err)
+
+	// Retrieve block by hash
+	retrievedBlock, err := handler.GetBlockByHash(block.BlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, block.BlockHeight, retrievedBlock.BlockHeight)
+	assert.Equal(t, block.BlockHash, retrievedBlock.BlockHash)
+	assert.Equal(t, block.BlockTimestamp, retrievedBlock.BlockTimestamp)
+}
+
+func testGetBlockByHashForNonExistentBlock(t *testing.T, handler IDatabaseHandler) {
+
+	block, err := handler.GetBlockByHash("0x123")
+	assert.Nil(t, block)
+	assert.Equal(t, types.ErrBlockNotFound, err)
+}
+
+func testQueryIsBlockFinalizedByHeight(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert a block
+	block := &types.Block{
+		BlockHeight:    1,
+		BlockHash:      "0x123",
+		BlockTimestamp: 1000,
+	}
+	err := handler.InsertBlocks([]*types.Block{block})
+	assert.NoError(t, err)
+
+	// Retrieve block status by height
+	isFinalized, err := handler.QueryIsBlockFinalizedByHeight(block.BlockHeight)
+	assert.NoError(t, err)
+	assert.Equal(t, isFinalized, true)
+}
+
+func testQueryIsBlockFinalizedByHeightForNonExistentBlock(t *testing.T, handler IDatabaseHandler) {
+
+	isFinalized, err := handler.QueryIsBlockFinalizedByHeight(1)
+	assert.NoError(t, err)
+	assert.Equal(t, isFinalized, false)
+}
+
+func testQueryIsBlockFinalizedByHash(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert a block
+	block := &types.Block{
+		BlockHeight:    1,
+		BlockHash:      "0x123",
+		BlockTimestamp: 1000,
+	}
+	err := handler.InsertBlocks([]*types.Block{block})
+	assert.NoError(t, err)
+
+	// Retrieve block status by hash
+	isFinalized, err := handler.QueryIsBlockFinalizedByHash(block.BlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, isFinalized, true)
+}
+
+func testQueryIsBlockFinalizedByHashForNonExistentBlock(t *testing.T, handler IDatabaseHandler) {
+
+	isFinalized, err := handler.QueryIsBlockFinalizedByHash("0x123")
+	assert.NoError(t, err)
+	assert.Equal(t, isFinalized, false)
+}
+
+func testQueryEarliestFinalizedBlock(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert two blocks
+	first := &types.Block{
+		BlockHeight:    1,
+		BlockHash:      "0x123",
+		BlockTimestamp: 1000,
+	}
+	second := &types.Block{
+		BlockHeight:    2,
+		BlockHash:      "0x456",
+		BlockTimestamp: 1050,
+	}
+	third := &types.Block{
+		BlockHeight:    3,
+		BlockHash:      "0x789",
+		BlockTimestamp: 1100,
+	}
+	err := handler.InsertBlocks([]*types.Block{first, second, third})
+	assert.NoError(t, err)
+
+	// Query earliest consecutively finalized block
+	earliestBlock, err := handler.QueryEarliestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, earliestBlock.BlockHeight, first.BlockHeight)
+	assert.Equal(t, earliestBlock.BlockHash, first.BlockHash)
+	assert.Equal(t, earliestBlock.BlockTimestamp, first.BlockTimestamp)
+}
+
+func testQueryLatestFinalizedBlock(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert two blocks
+	first := &types.Block{
+		BlockHeight:    1,
+		BlockHash:      "0x123",
+		BlockTimestamp: 1000,
+	}
+	second := &types.Block{
+		BlockHeight:    2,
+		BlockHash:      "0x456",
+		BlockTimestamp: 1050,
+	}
+	err := handler.InsertBlocks([]*types.Block{first, second})
+	assert.NoError(t, err)
+
+	// Retrieve latest block
+	latestBlock, err := handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, latestBlock.BlockHeight, second.BlockHeight)
+	assert.Equal(t, latestBlock.BlockHash, second.BlockHash)
+	assert.Equal(t, latestBlock.BlockTimestamp, second.BlockTimestamp)
+}
+
+func testQueryLatestFinalizedBlockNonExistent(t *testing.T, handler IDatabaseHandler) {
+
+	latestBlock, err := handler.QueryLatestFinalizedBlock()
+	assert.Nil(t, latestBlock)
+	assert.NoError(t, err)
+}
+
+func testGetActivatedTimestamp(t *testing.T, handler IDatabaseHandler) {
+
+	// Test when timestamp is not set
+	timestamp, err := handler.GetActivatedTimestamp()
+	assert.Equal(t, uint64(math.MaxUint64), timestamp)
+	assert.Equal(t, types.ErrActivatedTimestampNotFound, err)
+
+	// Set timestamp
+	expectedTimestamp := uint64(1234567890)
+	err = handler.SaveActivatedTimestamp(expectedTimestamp)
+	assert.NoError(t, err)
+
+	// Test when timestamp is set
+	timestamp, err = handler.GetActivatedTimestamp()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTimestamp, timestamp)
+}
+
+func testSaveActivatedTimestamp(t *testing.T, handler IDatabaseHandler) {
+
+	// Set timestamp
+	expectedTimestamp := uint64(1234567890)
+	err := handler.SaveActivatedTimestamp(expectedTimestamp)
+	assert.NoError(t, err)
+
+	// Verify timestamp was saved
+	timestamp, err := handler.GetActivatedTimestamp()
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTimestamp, timestamp)
+}
+
+func testInsertBlocksReplacesStaleHashMapping(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert a block
+	oldBlock := &types.Block{
+		BlockHeight:    1,
+		BlockHash:      "0x123",
+		BlockTimestamp: 1000,
+	}
+	err := handler.InsertBlocks([]*types.Block{oldBlock})
+	assert.NoError(t, err)
+
+	// Insert a different block at the same height
+	newBlock := &types.Block{
+		BlockHeight:    1,
+		BlockHash:      "0x456",
+		BlockTimestamp: 1001,
+	}
+	err = handler.InsertBlocks([]*types.Block{newBlock})
+	assert.NoError(t, err)
+
+	// The old hash no longer resolves
+	block, err := handler.GetBlockByHash(oldBlock.BlockHash)
+	assert.Nil(t, block)
+	assert.Equal(t, types.ErrBlockNotFound, err)
+	isFinalized, err := handler.QueryIsBlockFinalizedByHash(oldBlock.BlockHash)
+	assert.NoError(t, err)
+	assert.False(t, isFinalized)
+
+	// The new hash resolves to the new block
+	block, err = handler.GetBlockByHash(newBlock.BlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, newBlock.BlockTimestamp, block.BlockTimestamp)
+}
+
+func testDeleteBlocksFromHeight(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert three blocks
+	blocks := []*types.Block{
+		{
+			BlockHeight:    1,
+			BlockHash:      "0x123",
+			BlockTimestamp: 1000,
+		},
+		{
+			BlockHeight:    2,
+			BlockHash:      "0x456",
+			BlockTimestamp: 1050,
+		},
+		{
+			BlockHeight:    3,
+			BlockHash:      "0x789",
+			BlockTimestamp: 1100,
+		},
+	}
+	err := handler.InsertBlocks(blocks)
+	assert.NoError(t, err)
+
+	// Delete the last two blocks
+	err = handler.DeleteBlocksFromHeight(2)
+	assert.NoError(t, err)
+
+	// Verify deleted blocks are gone from both buckets
+	for _, block := range blocks[1:] {
+		retrievedBlock, err := handler.GetBlockByHeight(block.BlockHeight)
+		assert.Nil(t, retrievedBlock)
+		assert.Equal(t, types.ErrBlockNotFound, err)
+
+		retrievedBlock, err = handler.GetBlockByHash(block.BlockHash)
+		assert.Nil(t, retrievedBlock)
+		assert.Equal(t, types.ErrBlockNotFound, err)
+	}
+
+	// Verify latest index points to the highest remaining block
+	latest, err := handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), latest.BlockHeight)
+	earliest, err := handler.QueryEarliestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), earliest.BlockHeight)
+
+	// Re-insert a block on the new branch
+	err = handler.InsertBlocks([]*types.Block{{
+		BlockHeight:    2,
+		BlockHash:      "0xabc",
+		BlockTimestamp: 1060,
+	}})
+	assert.NoError(t, err)
+	latest, err = handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, "0xabc", latest.BlockHash)
+
+	// Delete all blocks
+	err = handler.DeleteBlocksFromHeight(0)
+	assert.NoError(t, err)
+
+	latest, err = handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+	earliest, err = handler.QueryEarliestFinalizedBlock()
+	assert.Nil(t, earliest)
+	assert.Equal(t, types.ErrBlockNotFound, err)
+}
+
+func testFinalityEvidence(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert a block with finality evidence
+	evidence := &types.FinalityEvidence{
+		BabylonHeight: 222,
+		BtcHeight:     111,
+		TotalPower:    300,
+		VotedPower:    200,
+		Voters: []*types.VoterPower{
+			{FpBtcPkHex: "pk1", Power: 100},
+			{FpBtcPkHex: "pk2", Power: 100},
+		},
+		QuorumRatio: 2.0 / 3,
+		QuorumPower: 200,
+	}
+	block := &types.Block{
+		BlockHeight:    1,
+		BlockHash:      "0x123",
+		BlockTimestamp: 1000,
+		Evidence:       evidence,
+	}
+	err := handler.InsertBlocks([]*types.Block{block})
+	assert.NoError(t, err)
+
+	// Evidence is retrievable by height and hash, and carries the block's height and hash
+	expected := *evidence
+	expected.BlockHeight = block.BlockHeight
+	expected.BlockHash = block.BlockHash
+	retrievedEvidence, err := handler.GetEvidenceByHeight(block.BlockHeight)
+	assert.NoError(t, err)
+	assert.Equal(t, &expected, retrievedEvidence)
+	retrievedEvidence, err = handler.GetEvidenceByHash(block.BlockHash)
+	assert.NoError(t, err)
+	assert.Equal(t, &expected, retrievedEvidence)
+
+	// Evidence is not part of the block record
+	retrievedBlock, err := handler.GetBlockByHeight(block.BlockHeight)
+	assert.NoError(t, err)
+	assert.Nil(t, retrievedBlock.Evidence)
+
+	// Non-existent evidence
+	retrievedEvidence, err = handler.GetEvidenceByHeight(2)
+	assert.Nil(t, retrievedEvidence)
+	assert.Equal(t, types.ErrEvidenceNotFound, err)
+	retrievedEvidence, err = handler.GetEvidenceByHash("0x456")
+	assert.Nil(t, retrievedEvidence)
+	assert.Equal(t, types.ErrEvidenceNotFound, err)
+
+	// Rolling back the block removes its evidence
+	err = handler.DeleteBlocksFromHeight(block.BlockHeight)
+	assert.NoError(t, err)
+	retrievedEvidence, err = handler.GetEvidenceByHeight(block.BlockHeight)
+	assert.Nil(t, retrievedEvidence)
+	assert.Equal(t, types.ErrEvidenceNotFound, err)
+}
+
+func testGetBlocksFromHeight(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert blocks at every other height
+	blocks := []*types.Block{
+		{BlockHeight: 2, BlockHash: "0x123", BlockTimestamp: 1000},
+		{BlockHeight: 4, BlockHash: "0x456", BlockTimestamp: 1050},
+		{BlockHeight: 6, BlockHash: "0x789", BlockTimestamp: 1100},
+	}
+	err := handler.InsertBlocks(blocks)
+	assert.NoError(t, err)
+
+	// Heights without a block are skipped
+	retrievedBlocks, err := handler.GetBlocksFromHeight(3, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[1:], retrievedBlocks)
+
+	// The number of blocks is capped by the limit
+	retrievedBlocks, err = handler.GetBlocksFromHeight(0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks[:2], retrievedBlocks)
+
+	// No blocks above the latest one
+	retrievedBlocks, err = handler.GetBlocksFromHeight(7, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, retrievedBlocks)
+}
+
+func testListBlocks(t *testing.T, handler IDatabaseHandler) {
+	// Nothing to list in an empty db
+	listedBlocks, err := handler.ListBlocks(types.BlockQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, listedBlocks)
+
+	// Blocks at every other height from 2 to 20, 50s apart
+	var blocks []*types.Block
+	for height := uint64(2); height <= 20; height += 2 {
+		blocks = append(blocks, &types.Block{
+			BlockHeight:    height,
+			BlockHash:      fmt.Sprintf("0x%064x", height),
+			BlockTimestamp: 1000 + 25*height,
+		})
+	}
+	assert.NoError(t, handler.InsertBlocks(blocks))
+	reversed := func(blocks []*types.Block) []*types.Block {
+		reversed := slices.Clone(blocks)
+		slices.Reverse(reversed)
+		return reversed
+	}
+
+	tests := []struct {
+		name     string
+		query    types.BlockQuery
+		expected []*types.Block
+	}{
+		{"all", types.BlockQuery{Limit: 100}, blocks},
+		{"all descending", types.BlockQuery{Descending: true, Limit: 100}, reversed(blocks)},
+		{"limit", types.BlockQuery{Limit: 3}, blocks[:3]},
+		{"limit descending", types.BlockQuery{Descending: true, Limit: 3}, reversed(blocks[7:])},
+		{"no limit", types.BlockQuery{}, nil},
+		// heights 5 to 11 hold the blocks at 6, 8 and 10
+		{"heights", types.BlockQuery{FromHeight: 5, ToHeight: 11, Limit: 100}, blocks[2:5]},
+		{"heights descending", types.BlockQuery{FromHeight: 5, ToHeight: 11, Descending: true, Limit: 100}, reversed(blocks[2:5])},
+		{"heights on blocks", types.BlockQuery{FromHeight: 6, ToHeight: 10, Limit: 100}, blocks[2:5]},
+		{"heights above latest", types.BlockQuery{FromHeight: 21, Limit: 100}, nil},
+		{"heights descending above latest", types.BlockQuery{FromHeight: 18, ToHeight: 100, Descending: true, Limit: 100}, reversed(blocks[8:])},
+		// timestamps 1140 to 1260 hold the blocks at 6 (1150) to 10 (1250)
+		{"timestamps", types.BlockQuery{FromTimestamp: 1140, ToTimestamp: 1260, Limit: 100}, blocks[2:5]},
+		{"timestamps on blocks", types.BlockQuery{FromTimestamp: 1150, ToTimestamp: 1250, Limit: 100}, blocks[2:5]},
+		{"timestamps descending", types.BlockQuery{FromTimestamp: 1140, ToTimestamp: 1260, Descending: true, Limit: 2}, reversed(blocks[3:5])},
+		{"timestamps before earliest", types.BlockQuery{ToTimestamp: 1000, Limit: 100}, nil},
+		{"timestamps after latest", types.BlockQuery{FromTimestamp: 2000, Limit: 100}, nil},
+		{"heights and timestamps", types.BlockQuery{FromHeight: 8, ToTimestamp: 1300, Limit: 100}, blocks[3:6]},
+	}
+	for _, tc := range tests {
+		listedBlocks, err := handler.ListBlocks(tc.query)
+		assert.NoError(t, err, tc.name)
+		if len(tc.expected) == 0 {
+			assert.Empty(t, listedBlocks, tc.name)
+		} else {
+			assert.Equal(t, tc.expected, listedBlocks, tc.name)
+		}
+	}
+}
+
+func testBtcHeaderIndex(t *testing.T, handler IDatabaseHandler) {
+
+	// Empty index
+	earliest, err := handler.QueryEarliestBtcHeader()
+	assert.NoError(t, err)
+	assert.Nil(t, earliest)
+	_, err = handler.GetBtcHeightByTimestamp(1000)
+	assert.ErrorIs(t, err, types.ErrBtcHeaderNotFound)
+
+	// Insert headers 100 to 110, 10 seconds apart
+	var headers []*types.BtcHeader
+	for height := uint32(100); height <= 110; height++ {
+		headers = append(headers, &types.BtcHeader{
+			Height:    height,
+			Hash:      fmt.Sprintf("hash%d", height),
+			Timestamp: 1000 + uint64(height-100)*10,
+		})
+	}
+	err = handler.InsertBtcHeaders(headers)
+	assert.NoError(t, err)
+
+	header, err := handler.GetBtcHeaderByHeight(105)
+	assert.NoError(t, err)
+	assert.Equal(t, headers[5], header)
+	_, err = handler.GetBtcHeaderByHeight(111)
+	assert.ErrorIs(t, err, types.ErrBtcHeaderNotFound)
+
+	earliest, err = handler.QueryEarliestBtcHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, headers[0], earliest)
+	latest, err := handler.QueryLatestBtcHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, headers[10], latest)
+
+	// Timestamps resolve to the highest height at or before them
+	for _, tc := range []struct {
+		timestamp uint64
+		height    uint32
+	}{
+		{1000, 100},
+		{1009, 100},
+		{1050, 105},
+		{1055, 105},
+		{1099, 109},
+		{1100, 110},
+	} {
+		height, err := handler.GetBtcHeightByTimestamp(tc.timestamp)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.height, height, "timestamp %d", tc.timestamp)
+	}
+
+	// Timestamps outside the indexed range are not resolved
+	_, err = handler.GetBtcHeightByTimestamp(999)
+	assert.ErrorIs(t, err, types.ErrBtcHeaderNotFound)
+	_, err = handler.GetBtcHeightByTimestamp(1101)
+	assert.ErrorIs(t, err, types.ErrBtcHeaderNotFound)
+
+	// Deleting from a height drops it and everything above
+	err = handler.DeleteBtcHeadersFromHeight(105)
+	assert.NoError(t, err)
+	latest, err = handler.QueryLatestBtcHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, headers[4], latest)
+	_, err = handler.GetBtcHeightByTimestamp(1050)
+	assert.ErrorIs(t, err, types.ErrBtcHeaderNotFound)
+}
+
+func testProcessingCheckpoint(t *testing.T, handler IDatabaseHandler) {
+	// No checkpoint before the first evaluation
+	checkpoint, err := handler.GetProcessingCheckpoint()
+	assert.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	// The checkpoint can move without blocks
+	skipped := &types.ProcessingCheckpoint{Height: 4, Outcome: types.CheckpointSkipped, Timestamp: 1000}
+	assert.NoError(t, handler.InsertBlocksWithCheckpoint(nil, skipped))
+	checkpoint, err = handler.GetProcessingCheckpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, skipped, checkpoint)
+	latest, err := handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+
+	// Blocks are stored with the checkpoint
+	blocks := []*types.Block{
+		{BlockHeight: 5, BlockHash: "0x05", BlockTimestamp: 1005},
+		{BlockHeight: 10, BlockHash: "0x10", BlockTimestamp: 1010},
+	}
+	finalized := &types.ProcessingCheckpoint{Height: 12, Outcome: types.CheckpointFinalized, Timestamp: 1012}
+	assert.NoError(t, handler.InsertBlocksWithCheckpoint(blocks, finalized))
+	checkpoint, err = handler.GetProcessingCheckpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, finalized, checkpoint)
+	latest, err = handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), latest.BlockHeight)
+
+	// Rolling back blocks above the checkpoint keeps it
+	assert.NoError(t, handler.DeleteBlocksFromHeight(13))
+	checkpoint, err = handler.GetProcessingCheckpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, finalized, checkpoint)
+
+	// Rolling back blocks below the checkpoint rewinds it
+	assert.NoError(t, handler.DeleteBlocksFromHeight(10))
+	checkpoint, err = handler.GetProcessingCheckpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), checkpoint.Height)
+	assert.Equal(t, types.CheckpointRolledBack, checkpoint.Outcome)
+	latest, err = handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), latest.BlockHeight)
+}
+
+func testDeleteBlocksBeforeHeight(t *testing.T, handler IDatabaseHandler) {
+
+	// Insert five blocks, the first one with finality evidence
+	var blocks []*types.Block
+	for height := uint64(1); height <= 5; height++ {
+		blocks = append(blocks, &types.Block{
+			BlockHeight:    height,
+			BlockHash:      fmt.Sprintf("0x%d", height),
+			BlockTimestamp: 1000 + height,
+		})
+	}
+	blocks[0].Evidence = &types.FinalityEvidence{TotalPower: 3, VotedPower: 2}
+	err := handler.InsertBlocks(blocks)
+	assert.NoError(t, err)
+
+	// Prune below height 4 in batches of two blocks
+	deleted, err := handler.DeleteBlocksBeforeHeight(4, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), deleted)
+	earliest, err := handler.QueryEarliestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), earliest.BlockHeight)
+
+	deleted, err = handler.DeleteBlocksBeforeHeight(4, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), deleted)
+	deleted, err = handler.DeleteBlocksBeforeHeight(4, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), deleted)
+
+	// Verify pruned blocks and their evidence are gone
+	for _, block := range blocks[:3] {
+		_, err := handler.GetBlockByHeight(block.BlockHeight)
+		assert.Equal(t, types.ErrBlockNotFound, err)
+		_, err = handler.GetBlockByHash(block.BlockHash)
+		assert.Equal(t, types.ErrBlockNotFound, err)
+	}
+	_, err = handler.GetEvidenceByHeight(1)
+	assert.Equal(t, types.ErrEvidenceNotFound, err)
+
+	// Verify the earliest index moved up and the latest one is unchanged
+	earliest, err = handler.QueryEarliestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), earliest.BlockHeight)
+	latest, err := handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), latest.BlockHeight)
+
+	// Pruning every block clears the indices
+	deleted, err = handler.DeleteBlocksBeforeHeight(math.MaxUint64, math.MaxUint64)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), deleted)
+	_, err = handler.QueryEarliestFinalizedBlock()
+	assert.Equal(t, types.ErrBlockNotFound, err)
+	latest, err = handler.QueryLatestFinalizedBlock()
+	assert.NoError(t, err)
+	assert.Nil(t, latest)
+}
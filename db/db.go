@@ -0,0 +1,209 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"go.uber.org/zap"
+)
+
+// Storage backends selectable with the db-backend config option
+const (
+	BBoltBackend    = "bbolt"
+	PebbleBackend   = "pebble"
+	MemoryBackend   = "memory"
+	SQLiteBackend   = "sqlite"
+	PostgresBackend = "postgres"
+)
+
+// Backends lists the supported storage backends, the first one being the default
+var Backends = []string{BBoltBackend, PebbleBackend, MemoryBackend, SQLiteBackend, PostgresBackend}
+
+/* NewHandler opens the db with the given storage backend
+ *
+ * - bbolt (default): single file at path
+ * - pebble: directory at path
+ * - memory: path is ignored and nothing is persisted, meant for tests and local development
+ * - sqlite: single SQLite file at path, for querying a single node with SQL
+ * - postgres: path is the connection string, for shared deployments
+ */
+func NewHandler(backend string, path string, logger *zap.Logger) (IDatabaseHandler, error) {
+	switch backend {
+	case "", BBoltBackend:
+		return NewBBoltHandler(path, logger)
+	case PebbleBackend:
+		return NewPebbleHandler(path, logger)
+	case MemoryBackend:
+		return NewMemoryHandler(logger), nil
+	case SQLiteBackend:
+		return NewSQLiteHandler(path, logger)
+	case PostgresBackend:
+		return NewPostgresHandler(path, logger)
+	default:
+		return nil, fmt.Errorf("unsupported db backend %q, expected one of %v", backend, Backends)
+	}
+}
+
+/* OpenHandler opens the existing db with the given storage backend, for the maintenance commands
+ *
+ * - unlike NewHandler, fails if there is no db file or directory at path rather than creating an empty one,
+ *   so a mistyped path is reported
+ * - with readOnly, the bbolt, pebble and sqlite dbs are opened read-only. Postgres access rights are
+ *   those of the connection's role
+ * - the memory backend persists nothing, so there is nothing to open
+ */
+func OpenHandler(backend string, path string, readOnly bool, logger *zap.Logger) (IDatabaseHandler, error) {
+	switch backend {
+	case "", BBoltBackend, PebbleBackend, SQLiteBackend:
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("no DB found at %s: %w", path, err)
+		}
+	case MemoryBackend:
+		return nil, fmt.Errorf("the %s DB backend persists nothing to open", backend)
+	}
+	if !readOnly {
+		return NewHandler(backend, path, logger)
+	}
+
+	switch backend {
+	case "", BBoltBackend:
+		return NewReadOnlyBBoltHandler(path, logger)
+	case PebbleBackend:
+		return NewReadOnlyPebbleHandler(path, logger)
+	case SQLiteBackend:
+		return NewReadOnlySQLiteHandler(path, logger)
+	default:
+		return NewHandler(backend, path, logger)
+	}
+}
+
+/* searchBtcHeight resolves a timestamp to a BTC height over the contiguous header index [earliest, latest]
+ *
+ * - returns the highest indexed height whose timestamp is at or before the given one,
+ *   binary searching like BitcoinClient.GetBlockHeightByTimestamp does over the node's chain
+ * - returns ErrBtcHeaderNotFound if the index is empty or the timestamp is outside the indexed range,
+ *   as a header before the earliest one or after the latest one may be the answer
+ */
+func searchBtcHeight(
+	earliest *types.BtcHeader,
+	latest *types.BtcHeader,
+	timestamp uint64,
+	getHeader func(height uint32) (*types.BtcHeader, error),
+) (uint32, error) {
+	if earliest == nil || latest == nil || timestamp < earliest.Timestamp || timestamp > latest.Timestamp {
+		return 0, types.ErrBtcHeaderNotFound
+	}
+
+	lowerBound, upperBound := earliest.Height, latest.Height
+	for lowerBound < upperBound {
+		// round up, so the lower bound always moves
+		midHeight := upperBound - (upperBound-lowerBound)/2
+		header, err := getHeader(midHeight)
+		if err != nil {
+			return 0, err
+		}
+		if header.Timestamp <= timestamp {
+			lowerBound = midHeight
+		} else {
+			upperBound = midHeight - 1
+		}
+	}
+	return lowerBound, nil
+}
+
+/* blockHeightBounds returns the inclusive height bounds of the stored blocks matching a block query
+ *
+ * - the timestamp bounds are turned into height bounds with binary searches over the stored blocks,
+ *   as timestamps increase with the height
+ * - first and last are the lowest and highest stored blocks, nil if none is stored
+ * - blockFrom returns the first stored block at or above a height, which exists up to the last one
+ * - returns lower > upper if no stored block can match
+ */
+func blockHeightBounds(
+	query types.BlockQuery,
+	first *types.Block,
+	last *types.Block,
+	blockFrom func(height uint64) (*types.Block, error),
+) (uint64, uint64, error) {
+	lower, upper := query.FromHeight, query.ToHeight
+	if upper == 0 {
+		upper = math.MaxUint64
+	}
+	if first == nil || last == nil {
+		return 1, 0, nil
+	}
+	if query.FromTimestamp > 0 {
+		height, found, err := firstBlockSince(query.FromTimestamp, first, last, blockFrom)
+		if err != nil || !found {
+			return 1, 0, err
+		}
+		lower = max(lower, height)
+	}
+	if query.ToTimestamp > 0 && query.ToTimestamp < math.MaxUint64 {
+		height, found, err := firstBlockSince(query.ToTimestamp+1, first, last, blockFrom)
+		if err != nil {
+			return 1, 0, err
+		}
+		if found {
+			if height == 0 {
+				return 1, 0, nil
+			}
+			upper = min(upper, height-1)
+		}
+	}
+	return lower, upper, nil
+}
+
+// firstBlockSince returns the lowest stored height with a timestamp at or after the given one,
+// and false if every stored block is older
+func firstBlockSince(
+	timestamp uint64,
+	first *types.Block,
+	last *types.Block,
+	blockFrom func(height uint64) (*types.Block, error),
+) (uint64, bool, error) {
+	if last.BlockTimestamp < timestamp {
+		return 0, false, nil
+	}
+	lowerBound, upperBound := first.BlockHeight, last.BlockHeight
+	for lowerBound < upperBound {
+		midHeight := lowerBound + (upperBound-lowerBound)/2
+		// the first block stored at or above midHeight, as there may be gaps
+		block, err := blockFrom(midHeight)
+		if err != nil {
+			return 0, false, err
+		}
+		if block.BlockTimestamp >= timestamp {
+			upperBound = midHeight
+		} else {
+			lowerBound = block.BlockHeight + 1
+		}
+	}
+	// the first block stored at or above lowerBound is the answer
+	block, err := blockFrom(lowerBound)
+	if err != nil {
+		return 0, false, err
+	}
+	return block.BlockHeight, true, nil
+}
+
+// matchesTimestamp returns true if the block is within the timestamp bounds of the query
+func matchesTimestamp(query types.BlockQuery, block *types.Block) bool {
+	return block.BlockTimestamp >= query.FromTimestamp && (query.ToTimestamp == 0 || block.BlockTimestamp <= query.ToTimestamp)
+}
+
+// rewoundCheckpoint returns the processing checkpoint moved below the blocks deleted from height on, so they
+// are processed again, or nil if the checkpoint is already below them
+func rewoundCheckpoint(checkpoint *types.ProcessingCheckpoint, height uint64) *types.ProcessingCheckpoint {
+	if checkpoint == nil || checkpoint.Height < height {
+		return nil
+	}
+	return &types.ProcessingCheckpoint{
+		Height:    max(height, 1) - 1,
+		Outcome:   types.CheckpointRolledBack,
+		Timestamp: uint64(time.Now().Unix()),
+	}
+}
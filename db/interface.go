@@ -5,13 +5,27 @@ import "github.com/babylonlabs-io/finality-gadget/types"
 type IDatabaseHandler interface {
 	CreateInitialSchema() error
 	InsertBlocks(block []*types.Block) error
+	InsertBlocksWithCheckpoint(blocks []*types.Block, checkpoint *types.ProcessingCheckpoint) error
+	GetProcessingCheckpoint() (*types.ProcessingCheckpoint, error)
 	GetBlockByHeight(height uint64) (*types.Block, error)
 	GetBlockByHash(hash string) (*types.Block, error)
+	GetBlocksFromHeight(height uint64, limit uint64) ([]*types.Block, error)
+	ListBlocks(query types.BlockQuery) ([]*types.Block, error)
+	GetEvidenceByHeight(height uint64) (*types.FinalityEvidence, error)
+	GetEvidenceByHash(hash string) (*types.FinalityEvidence, error)
 	QueryIsBlockFinalizedByHeight(height uint64) (bool, error)
 	QueryIsBlockFinalizedByHash(hash string) (bool, error)
 	QueryEarliestFinalizedBlock() (*types.Block, error)
 	QueryLatestFinalizedBlock() (*types.Block, error)
+	DeleteBlocksFromHeight(height uint64) error
+	DeleteBlocksBeforeHeight(height uint64, limit uint64) (uint64, error)
 	GetActivatedTimestamp() (uint64, error)
 	SaveActivatedTimestamp(timestamp uint64) error
+	InsertBtcHeaders(headers []*types.BtcHeader) error
+	GetBtcHeaderByHeight(height uint32) (*types.BtcHeader, error)
+	GetBtcHeightByTimestamp(timestamp uint64) (uint32, error)
+	QueryEarliestBtcHeader() (*types.BtcHeader, error)
+	QueryLatestBtcHeader() (*types.BtcHeader, error)
+	DeleteBtcHeadersFromHeight(height uint32) error
 	Close() error
 }
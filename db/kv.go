@@ -0,0 +1,638 @@
+package db
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"go.uber.org/zap"
+)
+
+// KVHandler implements IDatabaseHandler on top of an ordered key-value store, with the same
+// buckets and encoding as BBoltHandler. Each bucket is a key prefix.
+type KVHandler struct {
+	store  kvStore
+	logger *zap.Logger
+	// writeMutex serializes writes, as they read the state they update
+	writeMutex sync.Mutex
+}
+
+var _ IDatabaseHandler = &KVHandler{}
+
+// kvWrite collects the writes of one atomic update. Reads through it see its own pending writes.
+type kvWrite struct {
+	store   kvStore
+	ops     []kvOp
+	pending map[string][]byte
+}
+
+//////////////////////////////
+// CONSTRUCTOR
+//////////////////////////////
+
+func newKVHandler(store kvStore, logger *zap.Logger) *KVHandler {
+	return &KVHandler{
+		store:  store,
+		logger: logger,
+	}
+}
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+// CreateInitialSchema is a no-op, as buckets are key prefixes that need no setup
+func (kv *KVHandler) CreateInitialSchema() error {
+	kv.logger.Info("Initialising DB...")
+	return nil
+}
+
+func (kv *KVHandler) InsertBlocks(blocks []*types.Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	kv.logger.Info("Batch inserting blocks to DB", zap.Int("count", len(blocks)))
+
+	return kv.update(func(w *kvWrite) error {
+		return kv.insertBlocks(w, blocks)
+	})
+}
+
+// InsertBlocksWithCheckpoint stores the blocks and the processing checkpoint in a single write.
+// blocks can be empty to only move the checkpoint.
+func (kv *KVHandler) InsertBlocksWithCheckpoint(blocks []*types.Block, checkpoint *types.ProcessingCheckpoint) error {
+	kv.logger.Debug("Inserting blocks with processing checkpoint",
+		zap.Int("count", len(blocks)),
+		zap.Uint64("checkpoint_height", checkpoint.Height),
+		zap.Stringer("checkpoint_outcome", checkpoint.Outcome))
+
+	return kv.update(func(w *kvWrite) error {
+		if len(blocks) > 0 {
+			if err := kv.insertBlocks(w, blocks); err != nil {
+				return err
+			}
+		}
+		return kv.putCheckpoint(w, checkpoint)
+	})
+}
+
+// GetProcessingCheckpoint returns the processing checkpoint, nil if none was stored yet
+func (kv *KVHandler) GetProcessingCheckpoint() (*types.ProcessingCheckpoint, error) {
+	v, err := kv.store.get(bucketKey(indexerBucket, []byte(checkpointKey)))
+	if err != nil || v == nil {
+		return nil, err
+	}
+	var checkpoint types.ProcessingCheckpoint
+	if err := json.Unmarshal(v, &checkpoint); err != nil {
+		kv.logger.Error("Error decoding processing checkpoint", zap.Error(err))
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (kv *KVHandler) GetBlockByHeight(height uint64) (*types.Block, error) {
+	v, err := kv.store.get(bucketKey(blocksBucket, itob(height)))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, types.ErrBlockNotFound
+	}
+	var block types.Block
+	if err := json.Unmarshal(v, &block); err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (kv *KVHandler) GetBlockByHash(hash string) (*types.Block, error) {
+	height, err := kv.getHeightByHash(hash, types.ErrBlockNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return kv.GetBlockByHeight(height)
+}
+
+// GetBlocksFromHeight returns up to limit blocks at or above the given height, in ascending
+// height order. Heights without a finalized block are skipped.
+func (kv *KVHandler) GetBlocksFromHeight(height uint64, limit uint64) ([]*types.Block, error) {
+	var blocks []*types.Block
+	if limit == 0 {
+		return blocks, nil
+	}
+	err := kv.iterateBucket(blocksBucket, itob(height), nil, false, func(_, v []byte) (bool, error) {
+		var block types.Block
+		if err := json.Unmarshal(v, &block); err != nil {
+			return false, err
+		}
+		blocks = append(blocks, &block)
+		return uint64(len(blocks)) < limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// ListBlocks returns up to query.Limit blocks matching the query, in its height order
+func (kv *KVHandler) ListBlocks(query types.BlockQuery) ([]*types.Block, error) {
+	var blocks []*types.Block
+	if query.Limit == 0 {
+		return blocks, nil
+	}
+	// blockFrom returns the first block at or above lower, or the last one with reverse
+	blockFrom := func(lower []byte, reverse bool) (*types.Block, error) {
+		var block *types.Block
+		err := kv.iterateBucket(blocksBucket, lower, nil, reverse, func(_, v []byte) (bool, error) {
+			block = &types.Block{}
+			return false, json.Unmarshal(v, block)
+		})
+		return block, err
+	}
+	first, err := blockFrom(nil, false)
+	if err != nil {
+		return nil, err
+	}
+	last, err := blockFrom(nil, true)
+	if err != nil {
+		return nil, err
+	}
+	lower, upper, err := blockHeightBounds(query, first, last, func(height uint64) (*types.Block, error) {
+		return blockFrom(itob(height), false)
+	})
+	if err != nil || lower > upper {
+		return blocks, err
+	}
+
+	var upperKey []byte
+	if upper < math.MaxUint64 {
+		upperKey = itob(upper + 1)
+	}
+	err = kv.iterateBucket(blocksBucket, itob(lower), upperKey, query.Descending, func(_, v []byte) (bool, error) {
+		var block types.Block
+		if err := json.Unmarshal(v, &block); err != nil {
+			return false, err
+		}
+		if matchesTimestamp(query, &block) {
+			blocks = append(blocks, &block)
+		}
+		return uint64(len(blocks)) < query.Limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func (kv *KVHandler) GetEvidenceByHeight(height uint64) (*types.FinalityEvidence, error) {
+	v, err := kv.store.get(bucketKey(evidenceBucket, itob(height)))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, types.ErrEvidenceNotFound
+	}
+	var evidence types.FinalityEvidence
+	if err := json.Unmarshal(v, &evidence); err != nil {
+		return nil, err
+	}
+	return &evidence, nil
+}
+
+func (kv *KVHandler) GetEvidenceByHash(hash string) (*types.FinalityEvidence, error) {
+	height, err := kv.getHeightByHash(hash, types.ErrEvidenceNotFound)
+	if err != nil {
+		return nil, err
+	}
+	return kv.GetEvidenceByHeight(height)
+}
+
+func (kv *KVHandler) QueryIsBlockFinalizedByHeight(height uint64) (bool, error) {
+	_, err := kv.GetBlockByHeight(height)
+	if err != nil {
+		if errors.Is(err, types.ErrBlockNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (kv *KVHandler) QueryIsBlockFinalizedByHash(hash string) (bool, error) {
+	height, err := kv.getHeightByHash(hash, types.ErrBlockNotFound)
+	if err != nil {
+		if errors.Is(err, types.ErrBlockNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return kv.QueryIsBlockFinalizedByHeight(height)
+}
+
+func (kv *KVHandler) QueryEarliestFinalizedBlock() (*types.Block, error) {
+	v, err := kv.store.get(bucketKey(indexerBucket, []byte(earliestBlockKey)))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, types.ErrBlockNotFound
+	}
+	return kv.GetBlockByHeight(btoi(v))
+}
+
+func (kv *KVHandler) QueryLatestFinalizedBlock() (*types.Block, error) {
+	v, err := kv.store.get(bucketKey(indexerBucket, []byte(latestBlockKey)))
+	if err != nil {
+		kv.logger.Error("Error getting latest block", zap.Error(err))
+		return nil, err
+	}
+	// If no latest block has been stored yet, return nil
+	if v == nil {
+		return nil, nil
+	}
+	return kv.GetBlockByHeight(btoi(v))
+}
+
+// DeleteBlocksFromHeight removes all blocks at or above the given height, with their height
+// mappings and evidence, moves the latest index back to the highest remaining block and rewinds
+// the processing checkpoint below height. This is used to roll back the db after an L2 reorg.
+func (kv *KVHandler) DeleteBlocksFromHeight(height uint64) error {
+	kv.logger.Info("Deleting blocks from height", zap.Uint64("from_height", height))
+
+	return kv.update(func(w *kvWrite) error {
+		err := kv.iterateBucket(blocksBucket, itob(height), nil, false, func(k, v []byte) (bool, error) {
+			var block types.Block
+			if err := json.Unmarshal(v, &block); err != nil {
+				kv.logger.Error("Error decoding block", zap.Error(err))
+				return false, err
+			}
+			w.delete(k)
+			w.delete(bucketKey(evidenceBucket, itob(block.BlockHeight)))
+			w.delete(bucketKey(blockHeightsBucket, []byte(block.BlockHash)))
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// Rewind the processing checkpoint, so the deleted blocks are processed again
+		checkpointBytes, err := w.get(bucketKey(indexerBucket, []byte(checkpointKey)))
+		if err != nil {
+			return err
+		}
+		if checkpointBytes != nil {
+			var checkpoint types.ProcessingCheckpoint
+			if err := json.Unmarshal(checkpointBytes, &checkpoint); err != nil {
+				kv.logger.Error("Error decoding processing checkpoint", zap.Error(err))
+				return err
+			}
+			if rewound := rewoundCheckpoint(&checkpoint, height); rewound != nil {
+				if err := kv.putCheckpoint(w, rewound); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Point the latest index at the highest remaining block, or clear the
+		// indices if no block is left
+		var lastHeight []byte
+		err = kv.iterateBucket(blocksBucket, nil, itob(height), true, func(k, _ []byte) (bool, error) {
+			lastHeight = k[len(blocksBucket)+1:]
+			return false, nil
+		})
+		if err != nil {
+			return err
+		}
+		if lastHeight == nil {
+			w.delete(bucketKey(indexerBucket, []byte(earliestBlockKey)))
+			w.delete(bucketKey(indexerBucket, []byte(latestBlockKey)))
+			return nil
+		}
+		w.put(bucketKey(indexerBucket, []byte(latestBlockKey)), lastHeight)
+		return nil
+	})
+}
+
+// DeleteBlocksBeforeHeight removes up to limit of the lowest blocks below the given height, with their
+// height mappings and evidence, moves the earliest index up to the lowest remaining block and returns
+// the number of blocks removed. This is used to prune blocks out of the retention window in small batches.
+func (kv *KVHandler) DeleteBlocksBeforeHeight(height uint64, limit uint64) (uint64, error) {
+	var deleted uint64
+	err := kv.update(func(w *kvWrite) error {
+		var lastDeleted []byte
+		err := kv.iterateBucket(blocksBucket, nil, itob(height), false, func(k, v []byte) (bool, error) {
+			if deleted >= limit {
+				return false, nil
+			}
+			var block types.Block
+			if err := json.Unmarshal(v, &block); err != nil {
+				kv.logger.Error("Error decoding block", zap.Error(err))
+				return false, err
+			}
+			w.delete(k)
+			w.delete(bucketKey(evidenceBucket, itob(block.BlockHeight)))
+			w.delete(bucketKey(blockHeightsBucket, []byte(block.BlockHash)))
+			lastDeleted = k[len(blocksBucket)+1:]
+			deleted++
+			return true, nil
+		})
+		if err != nil || lastDeleted == nil {
+			return err
+		}
+
+		// Point the earliest index at the lowest remaining block, or clear the
+		// indices if no block is left
+		var firstHeight []byte
+		err = kv.iterateBucket(blocksBucket, itob(btoi(lastDeleted)+1), nil, false, func(k, _ []byte) (bool, error) {
+			firstHeight = k[len(blocksBucket)+1:]
+			return false, nil
+		})
+		if err != nil {
+			return err
+		}
+		if firstHeight == nil {
+			w.delete(bucketKey(indexerBucket, []byte(earliestBlockKey)))
+			w.delete(bucketKey(indexerBucket, []byte(latestBlockKey)))
+			return nil
+		}
+		w.put(bucketKey(indexerBucket, []byte(earliestBlockKey)), firstHeight)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func (kv *KVHandler) GetActivatedTimestamp() (uint64, error) {
+	v, err := kv.store.get(bucketKey(indexerBucket, []byte(activatedTimestampKey)))
+	if err != nil {
+		return math.MaxUint64, err
+	}
+	if v == nil {
+		return math.MaxUint64, types.ErrActivatedTimestampNotFound
+	}
+	return btoi(v), nil
+}
+
+func (kv *KVHandler) SaveActivatedTimestamp(timestamp uint64) error {
+	return kv.update(func(w *kvWrite) error {
+		w.put(bucketKey(indexerBucket, []byte(activatedTimestampKey)), itob(timestamp))
+		return nil
+	})
+}
+
+// InsertBtcHeaders stores BTC headers keyed by height, replacing any header stored at the same height
+func (kv *KVHandler) InsertBtcHeaders(headers []*types.BtcHeader) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	kv.logger.Debug("Batch inserting BTC headers to DB", zap.Int("count", len(headers)))
+
+	return kv.update(func(w *kvWrite) error {
+		for _, header := range headers {
+			headerBytes, err := json.Marshal(header)
+			if err != nil {
+				kv.logger.Error("Error encoding BTC header", zap.Error(err))
+				return err
+			}
+			w.put(bucketKey(btcHeadersBucket, itob(uint64(header.Height))), headerBytes)
+		}
+		return nil
+	})
+}
+
+func (kv *KVHandler) GetBtcHeaderByHeight(height uint32) (*types.BtcHeader, error) {
+	v, err := kv.store.get(bucketKey(btcHeadersBucket, itob(uint64(height))))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, types.ErrBtcHeaderNotFound
+	}
+	var header types.BtcHeader
+	if err := json.Unmarshal(v, &header); err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// GetBtcHeightByTimestamp resolves a timestamp to a BTC height from the header index, see searchBtcHeight
+func (kv *KVHandler) GetBtcHeightByTimestamp(timestamp uint64) (uint32, error) {
+	earliest, err := kv.QueryEarliestBtcHeader()
+	if err != nil {
+		return 0, err
+	}
+	latest, err := kv.QueryLatestBtcHeader()
+	if err != nil {
+		return 0, err
+	}
+	return searchBtcHeight(earliest, latest, timestamp, kv.GetBtcHeaderByHeight)
+}
+
+// QueryEarliestBtcHeader returns the lowest indexed BTC header, or nil if the index is empty
+func (kv *KVHandler) QueryEarliestBtcHeader() (*types.BtcHeader, error) {
+	return kv.queryBtcHeaderAtEnd(false)
+}
+
+// QueryLatestBtcHeader returns the highest indexed BTC header, or nil if the index is empty
+func (kv *KVHandler) QueryLatestBtcHeader() (*types.BtcHeader, error) {
+	return kv.queryBtcHeaderAtEnd(true)
+}
+
+// DeleteBtcHeadersFromHeight removes all BTC headers at or above the given height.
+// This is used to drop headers reorged out of the BTC chain.
+func (kv *KVHandler) DeleteBtcHeadersFromHeight(height uint32) error {
+	kv.logger.Info("Deleting BTC headers from height", zap.Uint32("from_height", height))
+
+	return kv.update(func(w *kvWrite) error {
+		return kv.iterateBucket(btcHeadersBucket, itob(uint64(height)), nil, false, func(k, _ []byte) (bool, error) {
+			w.delete(k)
+			return true, nil
+		})
+	})
+}
+
+func (kv *KVHandler) Close() error {
+	kv.logger.Info("Closing DB...")
+	return kv.store.close()
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+// update runs fn and applies its writes atomically, unless it fails
+func (kv *KVHandler) update(fn func(w *kvWrite) error) error {
+	kv.writeMutex.Lock()
+	defer kv.writeMutex.Unlock()
+
+	w := &kvWrite{store: kv.store, pending: make(map[string][]byte)}
+	if err := fn(w); err != nil {
+		return err
+	}
+	if len(w.ops) == 0 {
+		return nil
+	}
+	return kv.store.apply(w.ops)
+}
+
+// insertBlocks stores the blocks with their height mappings and evidence, and moves the indices to cover them
+func (kv *KVHandler) insertBlocks(w *kvWrite, blocks []*types.Block) error {
+	var minHeight, maxHeight uint64 = math.MaxUint64, 0
+
+	for _, block := range blocks {
+		minHeight = min(minHeight, block.BlockHeight)
+		maxHeight = max(maxHeight, block.BlockHeight)
+
+		// If a different block was stored at this height (e.g. before an L2 reorg),
+		// drop its height mapping so the stale hash no longer resolves
+		existing, err := w.get(bucketKey(blocksBucket, itob(block.BlockHeight)))
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			var existingBlock types.Block
+			if err := json.Unmarshal(existing, &existingBlock); err != nil {
+				kv.logger.Error("Error decoding existing block", zap.Error(err))
+				return err
+			}
+			if existingBlock.BlockHash != block.BlockHash {
+				w.delete(bucketKey(blockHeightsBucket, []byte(existingBlock.BlockHash)))
+			}
+		}
+
+		blockBytes, err := json.Marshal(block)
+		if err != nil {
+			kv.logger.Error("Error inserting block", zap.Error(err))
+			return err
+		}
+		w.put(bucketKey(blocksBucket, itob(block.BlockHeight)), blockBytes)
+		w.put(bucketKey(blockHeightsBucket, []byte(block.BlockHash)), itob(block.BlockHeight))
+
+		// Store finality evidence, or drop any evidence left from a replaced block
+		if block.Evidence == nil {
+			w.delete(bucketKey(evidenceBucket, itob(block.BlockHeight)))
+			continue
+		}
+		evidence := *block.Evidence
+		evidence.BlockHeight = block.BlockHeight
+		evidence.BlockHash = block.BlockHash
+		evidenceBytes, err := json.Marshal(&evidence)
+		if err != nil {
+			kv.logger.Error("Error encoding finality evidence", zap.Error(err))
+			return err
+		}
+		w.put(bucketKey(evidenceBucket, itob(block.BlockHeight)), evidenceBytes)
+	}
+
+	// Update earliest block if needed
+	earliestBytes, err := w.get(bucketKey(indexerBucket, []byte(earliestBlockKey)))
+	if err != nil {
+		return err
+	}
+	if earliestBytes == nil {
+		w.put(bucketKey(indexerBucket, []byte(earliestBlockKey)), itob(minHeight))
+	}
+
+	// Update latest block if needed
+	latestBytes, err := w.get(bucketKey(indexerBucket, []byte(latestBlockKey)))
+	if err != nil {
+		return err
+	}
+	var currentLatest uint64
+	if latestBytes != nil {
+		currentLatest = btoi(latestBytes)
+	}
+	if maxHeight > currentLatest {
+		w.put(bucketKey(indexerBucket, []byte(latestBlockKey)), itob(maxHeight))
+	}
+	return nil
+}
+
+func (kv *KVHandler) putCheckpoint(w *kvWrite, checkpoint *types.ProcessingCheckpoint) error {
+	checkpointBytes, err := json.Marshal(checkpoint)
+	if err != nil {
+		kv.logger.Error("Error encoding processing checkpoint", zap.Error(err))
+		return err
+	}
+	w.put(bucketKey(indexerBucket, []byte(checkpointKey)), checkpointBytes)
+	return nil
+}
+
+// iterateBucket iterates over the keys of the bucket in [lower, upper), nil bounds meaning the
+// start and end of the bucket. fn gets the full store key, including the bucket prefix.
+func (kv *KVHandler) iterateBucket(bucket string, lower []byte, upper []byte, reverse bool, fn func(k, v []byte) (bool, error)) error {
+	storeLower := bucketKey(bucket, lower)
+	var storeUpper []byte
+	if upper != nil {
+		storeUpper = bucketKey(bucket, upper)
+	} else {
+		// all keys of the bucket sort below the bucket name followed by the next byte after the separator
+		storeUpper = append([]byte(bucket), bucketSeparator+1)
+	}
+	return kv.store.iterate(storeLower, storeUpper, reverse, fn)
+}
+
+func (kv *KVHandler) getHeightByHash(hash string, notFoundErr error) (uint64, error) {
+	v, err := kv.store.get(bucketKey(blockHeightsBucket, []byte(hash)))
+	if err != nil {
+		return 0, err
+	}
+	if v == nil {
+		return 0, notFoundErr
+	}
+	return btoi(v), nil
+}
+
+func (kv *KVHandler) queryBtcHeaderAtEnd(reverse bool) (*types.BtcHeader, error) {
+	var header *types.BtcHeader
+	err := kv.iterateBucket(btcHeadersBucket, nil, nil, reverse, func(_, v []byte) (bool, error) {
+		header = &types.BtcHeader{}
+		return false, json.Unmarshal(v, header)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func (w *kvWrite) get(key []byte) ([]byte, error) {
+	if value, ok := w.pending[string(key)]; ok {
+		return value, nil
+	}
+	return w.store.get(key)
+}
+
+func (w *kvWrite) put(key []byte, value []byte) {
+	w.ops = append(w.ops, kvOp{key: key, value: value})
+	w.pending[string(key)] = value
+}
+
+func (w *kvWrite) delete(key []byte) {
+	w.ops = append(w.ops, kvOp{key: key})
+	w.pending[string(key)] = nil
+}
+
+// bucketSeparator separates the bucket name from the key within the bucket
+const bucketSeparator byte = 0x00
+
+func bucketKey(bucket string, key []byte) []byte {
+	k := make([]byte, 0, len(bucket)+1+len(key))
+	k = append(k, bucket...)
+	k = append(k, bucketSeparator)
+	return append(k, key...)
+}
+
+func itob(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
+
+func btoi(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
+}
@@ -0,0 +1,20 @@
+package db
+
+// kvStore is an ordered key-value store that KVHandler keeps its buckets in
+type kvStore interface {
+	// get returns a copy of the value stored at key, or nil if there is none
+	get(key []byte) ([]byte, error)
+	// iterate calls fn with the keys in [lower, upper) and their values, in ascending order or
+	// descending if reverse, until fn returns false. A nil upper bound means no upper bound.
+	// fn may keep the key and value, but must not access the store.
+	iterate(lower []byte, upper []byte, reverse bool, fn func(key, value []byte) (bool, error)) error
+	// apply writes all ops atomically
+	apply(ops []kvOp) error
+	close() error
+}
+
+// kvOp is a write to a kvStore, a delete if value is nil
+type kvOp struct {
+	key   []byte
+	value []byte
+}
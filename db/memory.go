@@ -0,0 +1,89 @@
+package db
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// memoryStore is a kvStore that keeps everything in a map, for tests and local development
+type memoryStore struct {
+	mutex sync.RWMutex
+	data  map[string][]byte
+}
+
+//////////////////////////////
+// CONSTRUCTOR
+//////////////////////////////
+
+// NewMemoryHandler returns a db handler that keeps everything in memory, lost on close
+func NewMemoryHandler(logger *zap.Logger) *KVHandler {
+	return newKVHandler(&memoryStore{data: make(map[string][]byte)}, logger)
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+func (ms *memoryStore) get(key []byte) ([]byte, error) {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	value, ok := ms.data[string(key)]
+	if !ok {
+		return nil, nil
+	}
+	return bytes.Clone(value), nil
+}
+
+func (ms *memoryStore) iterate(lower []byte, upper []byte, reverse bool, fn func(key, value []byte) (bool, error)) error {
+	// copy the range first, so fn runs without holding the lock
+	type entry struct{ key, value []byte }
+	var entries []entry
+	ms.mutex.RLock()
+	for key, value := range ms.data {
+		k := []byte(key)
+		if bytes.Compare(k, lower) < 0 || (upper != nil && bytes.Compare(k, upper) >= 0) {
+			continue
+		}
+		entries = append(entries, entry{key: k, value: bytes.Clone(value)})
+	}
+	ms.mutex.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if reverse {
+			return bytes.Compare(entries[i].key, entries[j].key) > 0
+		}
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	for _, e := range entries {
+		next, err := fn(e.key, e.value)
+		if err != nil {
+			return err
+		}
+		if !next {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (ms *memoryStore) apply(ops []kvOp) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	for _, op := range ops {
+		if op.value == nil {
+			delete(ms.data, string(op.key))
+			continue
+		}
+		ms.data[string(op.key)] = bytes.Clone(op.value)
+	}
+	return nil
+}
+
+func (ms *memoryStore) close() error {
+	return nil
+}
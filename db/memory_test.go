@@ -0,0 +1,21 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/babylonlabs-io/finality-gadget/log"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMemoryHandler(t *testing.T) {
+	runConformanceSuite(t, func(t *testing.T) (IDatabaseHandler, func()) {
+		logger, err := log.NewRootLogger("console", zap.DebugLevel)
+		require.NoError(t, err)
+
+		handler := NewMemoryHandler(logger)
+		require.NoError(t, handler.CreateInitialSchema())
+
+		return handler, func() { handler.Close() }
+	})
+}
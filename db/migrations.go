@@ -0,0 +1,288 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+// schemaVersionKey stores the schema version of a bbolt db in the indexer bucket.
+// Dbs created before schema versioning have no version and are at version 0.
+const schemaVersionKey = "schema_version"
+
+// Migration describes a step bringing the stored schema to Version
+type Migration struct {
+	Version     uint64
+	Description string
+}
+
+// Migrator is implemented by the db handlers whose stored schema is versioned.
+// Migrations are run at startup, after CreateInitialSchema.
+type Migrator interface {
+	// SchemaVersion returns the schema version of the db
+	SchemaVersion() (uint64, error)
+	// PendingMigrations returns the migrations not applied to the db yet, without writing anything.
+	// Dbs from a newer release are refused.
+	PendingMigrations() ([]Migration, error)
+	// Migrate applies the pending migrations in order and returns them. With dryRun, the
+	// migrations are run and rolled back, so nothing is written.
+	Migrate(dryRun bool) ([]Migration, error)
+}
+
+var _ Migrator = &BBoltHandler{}
+
+type bboltMigration struct {
+	Migration
+	migrate func(bb *BBoltHandler, tx *bolt.Tx) error
+}
+
+// bboltMigrations are applied in ascending version order. Never edit a released migration, append a new one.
+var bboltMigrations = []bboltMigration{
+	{
+		Migration: Migration{Version: 1, Description: "versioned schema with JSON encoded blocks, evidence and BTC headers"},
+		// the buckets are created by CreateInitialSchema, so dbs from before versioning only need the version recorded
+		migrate: func(bb *BBoltHandler, tx *bolt.Tx) error { return nil },
+	},
+	{
+		Migration: Migration{Version: 2, Description: "binary encoded blocks, evidence and BTC headers, raw hash keys"},
+		migrate:   migrateJSONToBinary,
+	},
+	{
+		Migration: Migration{Version: 3, Description: "finality evidence records the configured quorum and its threshold power"},
+		migrate:   migrateEvidenceQuorum,
+	},
+}
+
+// errDryRun rolls back the transaction of a dry run
+var errDryRun = errors.New("dry run")
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+// SchemaVersion returns the schema version stored in the indexer bucket, 0 if there is none
+func (bb *BBoltHandler) SchemaVersion() (uint64, error) {
+	var version uint64
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		var err error
+		version, err = bb.getSchemaVersion(tx)
+		return err
+	})
+	return version, err
+}
+
+// PendingMigrations returns the migrations not applied to the db yet, or an error if it's from a newer release
+func (bb *BBoltHandler) PendingMigrations() ([]Migration, error) {
+	version, err := bb.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > latestBBoltSchemaVersion() {
+		return nil, fmt.Errorf("db schema version %d is newer than the latest known version %d", version, latestBBoltSchemaVersion())
+	}
+	var pending []Migration
+	for _, m := range pendingBBoltMigrations(version) {
+		pending = append(pending, m.Migration)
+	}
+	return pending, nil
+}
+
+/* Migrate brings the db to the latest schema version
+ *
+ * - each migration runs in its own transaction together with the version bump, so an
+ *   interrupted upgrade resumes from the last applied migration
+ * - with dryRun, all pending migrations run in a single transaction that is rolled back,
+ *   which checks they would succeed on the existing data without writing anything
+ */
+func (bb *BBoltHandler) Migrate(dryRun bool) ([]Migration, error) {
+	version, err := bb.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	pending := pendingBBoltMigrations(version)
+	if len(pending) == 0 {
+		bb.logger.Info("DB schema is up to date", zap.Uint64("schema_version", version))
+		return nil, nil
+	}
+
+	applied := make([]Migration, 0, len(pending))
+	for _, m := range pending {
+		applied = append(applied, m.Migration)
+	}
+
+	if dryRun {
+		err := bb.db.Update(func(tx *bolt.Tx) error {
+			for _, m := range pending {
+				bb.logger.Info("Dry running DB migration", zap.Uint64("version", m.Version), zap.String("description", m.Description))
+				if err := bb.applyMigration(tx, m); err != nil {
+					return err
+				}
+			}
+			return errDryRun
+		})
+		if !errors.Is(err, errDryRun) {
+			return nil, err
+		}
+		return applied, nil
+	}
+
+	for _, m := range pending {
+		bb.logger.Info("Applying DB migration", zap.Uint64("version", m.Version), zap.String("description", m.Description))
+		if err := bb.db.Update(func(tx *bolt.Tx) error { return bb.applyMigration(tx, m) }); err != nil {
+			return nil, err
+		}
+	}
+	return applied, nil
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+// latestBBoltSchemaVersion is the version of the schema written by this release
+func latestBBoltSchemaVersion() uint64 {
+	return bboltMigrations[len(bboltMigrations)-1].Version
+}
+
+func pendingBBoltMigrations(version uint64) []bboltMigration {
+	var pending []bboltMigration
+	for _, m := range bboltMigrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+func (bb *BBoltHandler) applyMigration(tx *bolt.Tx, m bboltMigration) error {
+	if err := m.migrate(bb, tx); err != nil {
+		bb.logger.Error("Error applying DB migration", zap.Uint64("version", m.Version), zap.Error(err))
+		return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Description, err)
+	}
+	return bb.putSchemaVersion(tx, m.Version)
+}
+
+/* migrateJSONToBinary re-encodes the JSON records of schema version 1 with the binary encoding
+ *
+ * - blocks, evidence and BTC headers are decoded from JSON and re-encoded in place
+ * - the block_heights bucket is rebuilt from the blocks, keyed by raw hashes, which also
+ *   drops any stale hash mapping left by older releases
+ */
+func migrateJSONToBinary(bb *BBoltHandler, tx *bolt.Tx) error {
+	var blocks []*types.Block
+	err := reencodeBucket(tx, blocksBucket, func(v []byte) ([]byte, error) {
+		var block types.Block
+		if err := json.Unmarshal(v, &block); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, &block)
+		return encodeBlock(&block), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate blocks: %w", err)
+	}
+
+	err = reencodeBucket(tx, evidenceBucket, func(v []byte) ([]byte, error) {
+		var evidence types.FinalityEvidence
+		if err := json.Unmarshal(v, &evidence); err != nil {
+			return nil, err
+		}
+		return encodeEvidenceV2(&evidence), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate finality evidence: %w", err)
+	}
+
+	err = reencodeBucket(tx, btcHeadersBucket, func(v []byte) ([]byte, error) {
+		var header types.BtcHeader
+		if err := json.Unmarshal(v, &header); err != nil {
+			return nil, err
+		}
+		return encodeBtcHeader(&header), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate BTC headers: %w", err)
+	}
+
+	if err := tx.DeleteBucket([]byte(blockHeightsBucket)); err != nil {
+		return err
+	}
+	heightsBucket, err := tx.CreateBucket([]byte(blockHeightsBucket))
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		if err := heightsBucket.Put(hashKey(block.BlockHash), bb.itob(block.BlockHeight)); err != nil {
+			return err
+		}
+	}
+	bb.logger.Info("Migrated DB records to the binary encoding", zap.Int("blocks", len(blocks)))
+	return nil
+}
+
+/* migrateEvidenceQuorum re-encodes the evidence of schema version 2 with the quorum power
+ *
+ * - the quorum ratio of older evidence is the observed voted power over total power, not the configured
+ *   quorum, and the quorum in force when it was recorded is unknown, so both are reset to 0
+ * - the observed ratio is still derivable from the voted and total power
+ */
+func migrateEvidenceQuorum(bb *BBoltHandler, tx *bolt.Tx) error {
+	err := reencodeBucket(tx, evidenceBucket, func(v []byte) ([]byte, error) {
+		evidence, err := decodeEvidenceV2(v)
+		if err != nil {
+			return nil, err
+		}
+		evidence.QuorumRatio = 0
+		return encodeEvidence(evidence), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate finality evidence: %w", err)
+	}
+	return nil
+}
+
+// reencodeBucket replaces every value of the bucket with its re-encoding
+func reencodeBucket(tx *bolt.Tx, bucket string, reencode func(v []byte) ([]byte, error)) error {
+	b := tx.Bucket([]byte(bucket))
+
+	// Collect the records first, as writing while iterating a cursor may skip entries
+	var keys, values [][]byte
+	err := b.ForEach(func(k, v []byte) error {
+		value, err := reencode(v)
+		if err != nil {
+			return fmt.Errorf("record %x: %w", k, err)
+		}
+		keys = append(keys, append([]byte(nil), k...))
+		values = append(values, value)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := range keys {
+		if err := b.Put(keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bb *BBoltHandler) getSchemaVersion(tx *bolt.Tx) (uint64, error) {
+	b := tx.Bucket([]byte(indexerBucket))
+	if b == nil {
+		return 0, nil
+	}
+	v := b.Get([]byte(schemaVersionKey))
+	if v == nil {
+		return 0, nil
+	}
+	return bb.btoi(v), nil
+}
+
+func (bb *BBoltHandler) putSchemaVersion(tx *bolt.Tx, version uint64) error {
+	return tx.Bucket([]byte(indexerBucket)).Put([]byte(schemaVersionKey), bb.itob(version))
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cockroachdb/pebble"
+	"go.uber.org/zap"
+)
+
+// pebbleStore is a kvStore backed by a Pebble (LSM tree) database directory
+type pebbleStore struct {
+	db *pebble.DB
+}
+
+//////////////////////////////
+// CONSTRUCTOR
+//////////////////////////////
+
+// NewPebbleHandler opens a db handler backed by the Pebble database in the directory at path,
+// creating it if needed. Like bbolt, Pebble locks the directory while it's open.
+func NewPebbleHandler(path string, logger *zap.Logger) (*KVHandler, error) {
+	return openPebbleHandler(path, false, logger)
+}
+
+// NewReadOnlyPebbleHandler opens the existing Pebble database in the directory at path read-only
+func NewReadOnlyPebbleHandler(path string, logger *zap.Logger) (*KVHandler, error) {
+	return openPebbleHandler(path, true, logger)
+}
+
+func openPebbleHandler(path string, readOnly bool, logger *zap.Logger) (*KVHandler, error) {
+	db, err := pebble.Open(path, &pebble.Options{ReadOnly: readOnly})
+	if err != nil {
+		logger.Error("Error opening DB", zap.Error(err))
+		return nil, err
+	}
+	return newKVHandler(&pebbleStore{db: db}, logger), nil
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+func (ps *pebbleStore) get(key []byte) ([]byte, error) {
+	value, closer, err := ps.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	return bytes.Clone(value), nil
+}
+
+func (ps *pebbleStore) iterate(lower []byte, upper []byte, reverse bool, fn func(key, value []byte) (bool, error)) error {
+	iter, err := ps.db.NewIter(&pebble.IterOptions{LowerBound: lower, UpperBound: upper})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	valid, step := iter.First, iter.Next
+	if reverse {
+		valid, step = iter.Last, iter.Prev
+	}
+	for ok := valid(); ok; ok = step() {
+		// the iterator reuses its buffers, so hand out copies
+		next, err := fn(bytes.Clone(iter.Key()), bytes.Clone(iter.Value()))
+		if err != nil {
+			return err
+		}
+		if !next {
+			break
+		}
+	}
+	return iter.Error()
+}
+
+func (ps *pebbleStore) apply(ops []kvOp) error {
+	batch := ps.db.NewBatch()
+	defer batch.Close()
+
+	for _, op := range ops {
+		var err error
+		if op.value == nil {
+			err = batch.Delete(op.key, nil)
+		} else {
+			err = batch.Set(op.key, op.value, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+func (ps *pebbleStore) close() error {
+	return ps.db.Close()
+}
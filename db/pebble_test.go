@@ -0,0 +1,39 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/babylonlabs-io/finality-gadget/log"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPebbleHandler(t *testing.T) {
+	runConformanceSuite(t, func(t *testing.T) (IDatabaseHandler, func()) {
+		logger, err := log.NewRootLogger("console", zap.DebugLevel)
+		require.NoError(t, err)
+
+		handler, err := NewPebbleHandler(filepath.Join(t.TempDir(), "pebble"), logger)
+		require.NoError(t, err)
+		require.NoError(t, handler.CreateInitialSchema())
+
+		return handler, func() { handler.Close() }
+	})
+}
+
+func TestPebbleHandlerPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pebble")
+
+	handler, err := NewPebbleHandler(path, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, handler.SaveActivatedTimestamp(1000))
+	require.NoError(t, handler.Close())
+
+	handler, err = NewPebbleHandler(path, zap.NewNop())
+	require.NoError(t, err)
+	defer handler.Close()
+	timestamp, err := handler.GetActivatedTimestamp()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000), timestamp)
+}
@@ -0,0 +1,321 @@
+package db
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	bolt "go.etcd.io/bbolt"
+	"go.uber.org/zap"
+)
+
+/* Snapshots are gzipped tar archives of two entries
+ *
+ * - snapshotDBEntry: the db file, copied from a read transaction so it's consistent while the daemon runs
+ * - snapshotManifestEntry: the SnapshotManifest, written last as it holds the checksum of the db file
+ *
+ * Restoring writes the db file and its manifest next to it. The daemon validates the manifest against
+ * its config and the db on its first start, then removes it.
+ */
+
+const (
+	snapshotManifestVersion = 1
+	snapshotDBEntry         = "finalitygadget.db"
+	snapshotManifestEntry   = "manifest.json"
+)
+
+// SnapshotManifest describes a db snapshot and what it was taken for
+type SnapshotManifest struct {
+	Version         int       `json:"version"`
+	ChainID         string    `json:"chain_id"`
+	ContractAddress string    `json:"contract_address"`
+	Backend         string    `json:"backend"`
+	SchemaVersion   uint64    `json:"schema_version"`
+	EarliestHeight  uint64    `json:"earliest_height"`
+	LatestHeight    uint64    `json:"latest_height"`
+	CreatedAt       time.Time `json:"created_at"`
+	Size            int64     `json:"size"`
+	// Checksum is the hex encoded SHA-256 of the db file
+	Checksum string `json:"checksum"`
+}
+
+// Snapshotter is implemented by the db handlers that can take a snapshot while serving reads and writes
+type Snapshotter interface {
+	// WriteSnapshot writes a consistent snapshot of the db to w, for the given chain ID and contract address
+	WriteSnapshot(w io.Writer, chainID string, contractAddress string) (*SnapshotManifest, error)
+}
+
+var _ Snapshotter = &BBoltHandler{}
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+// WriteSnapshot streams the db from a read transaction, which doesn't block block inserts
+func (bb *BBoltHandler) WriteSnapshot(w io.Writer, chainID string, contractAddress string) (*SnapshotManifest, error) {
+	manifest := &SnapshotManifest{
+		Version:         snapshotManifestVersion,
+		ChainID:         chainID,
+		ContractAddress: contractAddress,
+		Backend:         BBoltBackend,
+		CreatedAt:       time.Now().UTC(),
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err := bb.db.View(func(tx *bolt.Tx) error {
+		indexBucket := tx.Bucket([]byte(indexerBucket))
+		if indexBucket == nil {
+			return errors.New("the DB has no schema, it was never opened by the daemon")
+		}
+		var err error
+		if manifest.SchemaVersion, err = bb.getSchemaVersion(tx); err != nil {
+			return err
+		}
+		if v := indexBucket.Get([]byte(earliestBlockKey)); v != nil {
+			manifest.EarliestHeight = bb.btoi(v)
+		}
+		if v := indexBucket.Get([]byte(latestBlockKey)); v != nil {
+			manifest.LatestHeight = bb.btoi(v)
+		}
+
+		manifest.Size = tx.Size()
+		err = tw.WriteHeader(&tar.Header{
+			Name:    snapshotDBEntry,
+			Mode:    0600,
+			Size:    manifest.Size,
+			ModTime: manifest.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		hasher := sha256.New()
+		if _, err := tx.WriteTo(io.MultiWriter(tw, hasher)); err != nil {
+			return err
+		}
+		manifest.Checksum = hex.EncodeToString(hasher.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		bb.logger.Error("Error writing DB snapshot", zap.Error(err))
+		return nil, err
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:    snapshotManifestEntry,
+		Mode:    0600,
+		Size:    int64(len(manifestBytes)),
+		ModTime: manifest.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(manifestBytes); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	bb.logger.Info("Wrote DB snapshot",
+		zap.Uint64("earliest_height", manifest.EarliestHeight),
+		zap.Uint64("latest_height", manifest.LatestHeight),
+		zap.Int64("size", manifest.Size))
+	return manifest, nil
+}
+
+/* RestoreSnapshot extracts a snapshot to a new bbolt db file at path
+ *
+ * - the db file is written to a temporary file first, and only moved to path once its size and
+ *   checksum match the manifest
+ * - the manifest is written next to the db, see SnapshotManifestPath, for the daemon to validate on start
+ * - an existing db at path is never overwritten
+ */
+func RestoreSnapshot(r io.Reader, path string) (*SnapshotManifest, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("a DB already exists at %s", path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	tmpPath := path + ".restore"
+	f, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmpPath)
+	manifest, err := readSnapshot(r, f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(SnapshotManifestPath(path), manifestBytes, 0600); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(SnapshotManifestPath(path))
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// VerifySnapshot reads a whole snapshot and returns its manifest if the db file matches it
+func VerifySnapshot(r io.Reader) (*SnapshotManifest, error) {
+	return readSnapshot(r, io.Discard)
+}
+
+// SnapshotManifestPath returns where the manifest of a db restored at path is stored until the daemon validates it
+func SnapshotManifestPath(path string) string {
+	return path + ".manifest.json"
+}
+
+/* ReadRestoredSnapshotManifest returns the manifest of the snapshot the db at path was restored from, if the
+ * daemon hasn't validated it yet, or nil
+ *
+ * - the manifest must be for the given chain ID and contract address
+ * - the db file must still match the manifest's checksum, so this must be called before the db is opened
+ */
+func ReadRestoredSnapshotManifest(path string, chainID string, contractAddress string) (*SnapshotManifest, error) {
+	manifestBytes, err := os.ReadFile(SnapshotManifestPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest SnapshotManifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest: %w", err)
+	}
+	if manifest.ChainID != chainID {
+		return nil, fmt.Errorf("snapshot is for chain ID %q, expected %q", manifest.ChainID, chainID)
+	}
+	if manifest.ContractAddress != contractAddress {
+		return nil, fmt.Errorf("snapshot is for contract %q, expected %q", manifest.ContractAddress, contractAddress)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, f)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.checkFile(size, hex.EncodeToString(hasher.Sum(nil))); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// CheckRestoredDB checks the db restored from the snapshot holds the height range of its manifest
+func (m *SnapshotManifest) CheckRestoredDB(handler IDatabaseHandler) error {
+	var earliestHeight, latestHeight uint64
+	earliest, err := handler.QueryEarliestFinalizedBlock()
+	if err != nil && !errors.Is(err, types.ErrBlockNotFound) {
+		return err
+	}
+	if earliest != nil {
+		earliestHeight = earliest.BlockHeight
+	}
+	latest, err := handler.QueryLatestFinalizedBlock()
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		latestHeight = latest.BlockHeight
+	}
+	if earliestHeight != m.EarliestHeight || latestHeight != m.LatestHeight {
+		return fmt.Errorf("restored DB holds heights [%d, %d], the snapshot manifest [%d, %d]",
+			earliestHeight, latestHeight, m.EarliestHeight, m.LatestHeight)
+	}
+	return nil
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+func (m *SnapshotManifest) checkFile(size int64, checksum string) error {
+	if m.Version != snapshotManifestVersion {
+		return fmt.Errorf("unsupported snapshot manifest version %d", m.Version)
+	}
+	if m.Backend != BBoltBackend {
+		return fmt.Errorf("unsupported snapshot backend %q", m.Backend)
+	}
+	if size != m.Size || checksum != m.Checksum {
+		return fmt.Errorf("snapshot DB checksum mismatch: got %d bytes with sha256 %s, expected %d bytes with sha256 %s",
+			size, checksum, m.Size, m.Checksum)
+	}
+	return nil
+}
+
+// readSnapshot copies the db file of a snapshot to w, and returns the manifest once the db file is checked against it
+func readSnapshot(r io.Reader, w io.Writer) (*SnapshotManifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	// The db file comes first
+	if err := nextSnapshotEntry(tr, snapshotDBEntry); err != nil {
+		return nil, err
+	}
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, hasher), tr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot DB: %w", err)
+	}
+
+	// Then the manifest
+	if err := nextSnapshotEntry(tr, snapshotManifestEntry); err != nil {
+		return nil, err
+	}
+	var manifest SnapshotManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest: %w", err)
+	}
+	if err := manifest.checkFile(size, hex.EncodeToString(hasher.Sum(nil))); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+func nextSnapshotEntry(tr *tar.Reader, name string) error {
+	header, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("invalid snapshot, expected %s: %w", name, err)
+	}
+	if header.Name != name {
+		return fmt.Errorf("invalid snapshot: unexpected entry %q, expected %s", header.Name, name)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const (
+	testChainID         = "bbn-test"
+	testContractAddress = "bbn1contract"
+)
+
+// writeTestSnapshot returns a snapshot of a db holding blocks [from, to]
+func writeTestSnapshot(t *testing.T, from uint64, to uint64) []byte {
+	handler, cleanup := setupDB(t)
+	defer cleanup()
+	for height := from; height <= to; height++ {
+		require.NoError(t, handler.InsertBlocks([]*types.Block{{
+			BlockHeight:    height,
+			BlockHash:      fmt.Sprintf("0x%064x", height),
+			BlockTimestamp: 1000 + height,
+		}}))
+	}
+
+	var snapshot bytes.Buffer
+	manifest, err := handler.WriteSnapshot(&snapshot, testChainID, testContractAddress)
+	require.NoError(t, err)
+	require.Equal(t, testChainID, manifest.ChainID)
+	require.Equal(t, testContractAddress, manifest.ContractAddress)
+	require.Equal(t, from, manifest.EarliestHeight)
+	require.Equal(t, to, manifest.LatestHeight)
+	require.Equal(t, latestBBoltSchemaVersion(), manifest.SchemaVersion)
+	return snapshot.Bytes()
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	snapshot := writeTestSnapshot(t, 5, 20)
+
+	manifest, err := VerifySnapshot(bytes.NewReader(snapshot))
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), manifest.LatestHeight)
+
+	path := filepath.Join(t.TempDir(), "restored.db")
+	_, err = RestoreSnapshot(bytes.NewReader(snapshot), path)
+	require.NoError(t, err)
+	require.FileExists(t, SnapshotManifestPath(path))
+
+	// An existing db is never overwritten
+	_, err = RestoreSnapshot(bytes.NewReader(snapshot), path)
+	require.Error(t, err)
+
+	// The manifest is checked against the config and the db file before the db is opened
+	_, err = ReadRestoredSnapshotManifest(path, "other-chain", testContractAddress)
+	require.Error(t, err)
+	_, err = ReadRestoredSnapshotManifest(path, testChainID, "bbn1other")
+	require.Error(t, err)
+	restored, err := ReadRestoredSnapshotManifest(path, testChainID, testContractAddress)
+	require.NoError(t, err)
+	require.Equal(t, manifest, restored)
+
+	handler, err := NewBBoltHandler(path, zap.NewNop())
+	require.NoError(t, err)
+	defer handler.Close()
+	require.NoError(t, handler.CreateInitialSchema())
+	require.NoError(t, restored.CheckRestoredDB(handler))
+	block, err := handler.GetBlockByHeight(12)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("0x%064x", 12), block.BlockHash)
+
+	// The height range must match the db
+	require.NoError(t, handler.InsertBlocks([]*types.Block{{BlockHeight: 21, BlockHash: fmt.Sprintf("0x%064x", 21)}}))
+	require.Error(t, restored.CheckRestoredDB(handler))
+}
+
+func TestSnapshotCorruption(t *testing.T) {
+	snapshot := writeTestSnapshot(t, 1, 3)
+
+	// A truncated snapshot doesn't restore, and leaves nothing behind
+	dir := t.TempDir()
+	path := filepath.Join(dir, "truncated.db")
+	_, err := RestoreSnapshot(bytes.NewReader(snapshot[:len(snapshot)/2]), path)
+	require.Error(t, err)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// A db file changed after the restore fails the checksum
+	path = filepath.Join(dir, "changed.db")
+	_, err = RestoreSnapshot(bytes.NewReader(snapshot), path)
+	require.NoError(t, err)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = ReadRestoredSnapshotManifest(path, testChainID, testContractAddress)
+	require.ErrorContains(t, err, "checksum mismatch")
+
+	// Without a manifest, the db isn't from a pending restore
+	require.NoError(t, os.Remove(SnapshotManifestPath(path)))
+	manifest, err := ReadRestoredSnapshotManifest(path, testChainID, testContractAddress)
+	require.NoError(t, err)
+	require.Nil(t, manifest)
+}
@@ -0,0 +1,846 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	_ "github.com/lib/pq"           // registers the postgres driver
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 driver
+	"go.uber.org/zap"
+)
+
+// SQL drivers supported by SQLHandler
+const (
+	sqliteDriver   = "sqlite3"
+	postgresDriver = "postgres"
+)
+
+// SQLHandler implements IDatabaseHandler on top of SQLite or Postgres, so finalized blocks
+// and their vote history can be queried with SQL. Queries are written with ? placeholders,
+// which are rebound to $n for Postgres.
+type SQLHandler struct {
+	db     *sql.DB
+	driver string
+	logger *zap.Logger
+}
+
+var _ IDatabaseHandler = &SQLHandler{}
+
+var _ Migrator = &SQLHandler{}
+
+// The processing checkpoint is stored as one indexer row per field
+const (
+	checkpointHeightKey    = "checkpoint_height"
+	checkpointOutcomeKey   = "checkpoint_outcome"
+	checkpointTimestampKey = "checkpoint_timestamp"
+)
+
+type sqlMigration struct {
+	Migration
+	statements string
+}
+
+// sqlMigrations are applied in ascending version order. Never edit a released migration, append a new one.
+var sqlMigrations = []sqlMigration{{
+	Migration: Migration{Version: 1, Description: "initial schema"},
+	statements: `CREATE TABLE blocks (
+		block_height BIGINT PRIMARY KEY,
+		block_hash TEXT NOT NULL,
+		block_timestamp BIGINT NOT NULL,
+		babylon_height BIGINT NOT NULL DEFAULT 0
+	);
+	CREATE INDEX blocks_block_hash_idx ON blocks (block_hash);
+	CREATE INDEX blocks_block_timestamp_idx ON blocks (block_timestamp);
+
+	CREATE TABLE finality_evidence (
+		block_height BIGINT PRIMARY KEY,
+		block_hash TEXT NOT NULL,
+		babylon_height BIGINT NOT NULL,
+		btc_height BIGINT NOT NULL,
+		total_power BIGINT NOT NULL,
+		voted_power BIGINT NOT NULL,
+		quorum_ratio DOUBLE PRECISION NOT NULL
+	);
+
+	CREATE TABLE finality_votes (
+		block_height BIGINT NOT NULL,
+		position INTEGER NOT NULL,
+		fp_btc_pk_hex TEXT NOT NULL,
+		power BIGINT NOT NULL,
+		PRIMARY KEY (block_height, position)
+	);
+	CREATE INDEX finality_votes_fp_btc_pk_hex_idx ON finality_votes (fp_btc_pk_hex, block_height);
+
+	CREATE TABLE btc_headers (
+		height BIGINT PRIMARY KEY,
+		hash TEXT NOT NULL,
+		block_time BIGINT NOT NULL
+	);
+	CREATE INDEX btc_headers_block_time_idx ON btc_headers (block_time);
+
+	CREATE TABLE indexer (
+		key TEXT PRIMARY KEY,
+		value BIGINT NOT NULL
+	);`,
+}, {
+	// the quorum ratio of older evidence is the observed voted power over total power, not the configured
+	// quorum, and the quorum in force when it was recorded is unknown, so both are reset to 0
+	Migration: Migration{Version: 2, Description: "finality evidence records the configured quorum and its threshold power"},
+	statements: `ALTER TABLE finality_evidence ADD COLUMN quorum_power BIGINT NOT NULL DEFAULT 0;
+	UPDATE finality_evidence SET quorum_ratio = 0;`,
+}}
+
+//////////////////////////////
+// CONSTRUCTOR
+//////////////////////////////
+
+// NewSQLiteHandler opens a db handler backed by the SQLite database file at path, creating it if needed
+func NewSQLiteHandler(path string, logger *zap.Logger) (*SQLHandler, error) {
+	// wait for locks held by other connections, e.g. a reader running analytics, rather than failing
+	dsn := "file:" + path + "?_busy_timeout=5000&_journal_mode=WAL"
+	handler, err := newSQLHandler(sqliteDriver, dsn, logger)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite has a single writer, so serialize access through one connection
+	handler.db.SetMaxOpenConns(1)
+	return handler, nil
+}
+
+// NewReadOnlySQLiteHandler opens the existing SQLite database file at path read-only
+func NewReadOnlySQLiteHandler(path string, logger *zap.Logger) (*SQLHandler, error) {
+	dsn := "file:" + path + "?_busy_timeout=5000&mode=ro"
+	return newSQLHandler(sqliteDriver, dsn, logger)
+}
+
+// NewPostgresHandler opens a db handler backed by the Postgres database at the given connection string
+func NewPostgresHandler(url string, logger *zap.Logger) (*SQLHandler, error) {
+	return newSQLHandler(postgresDriver, url, logger)
+}
+
+func newSQLHandler(driver string, dsn string, logger *zap.Logger) (*SQLHandler, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		logger.Error("Error opening DB", zap.Error(err))
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		logger.Error("Error connecting to DB", zap.Error(err))
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLHandler{
+		db:     db,
+		driver: driver,
+		logger: logger,
+	}, nil
+}
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+// CreateInitialSchema creates the schema_migrations table and, on a new db, the tables of the latest
+// schema version. An existing db keeps its version for Migrate to upgrade; dbs from a newer release are refused.
+func (sh *SQLHandler) CreateInitialSchema() error {
+	sh.logger.Info("Initialising DB...")
+
+	if _, err := sh.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	version, err := sh.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		_, err := sh.Migrate(false)
+		return err
+	}
+	if latest := sqlMigrations[len(sqlMigrations)-1].Version; version > latest {
+		return fmt.Errorf("db schema version %d is newer than the latest known version %d", version, latest)
+	}
+	return nil
+}
+
+// SchemaVersion returns the highest migration applied to the db, 0 if there is none
+func (sh *SQLHandler) SchemaVersion() (uint64, error) {
+	var version uint64
+	if err := sh.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to query schema version: %w", err)
+	}
+	return version, nil
+}
+
+// PendingMigrations returns the migrations not applied to the db yet, or an error if it's from a newer release
+func (sh *SQLHandler) PendingMigrations() ([]Migration, error) {
+	version, err := sh.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if latest := sqlMigrations[len(sqlMigrations)-1].Version; version > latest {
+		return nil, fmt.Errorf("db schema version %d is newer than the latest known version %d", version, latest)
+	}
+	var pending []Migration
+	for _, m := range sqlMigrations {
+		if m.Version > version {
+			pending = append(pending, m.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies the pending migrations, each in its own transaction with its version. With dryRun, they
+// run in a single transaction that is rolled back, as both SQLite and Postgres roll back schema changes.
+func (sh *SQLHandler) Migrate(dryRun bool) ([]Migration, error) {
+	version, err := sh.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	var pending []sqlMigration
+	var applied []Migration
+	for _, m := range sqlMigrations {
+		if m.Version > version {
+			pending = append(pending, m)
+			applied = append(applied, m.Migration)
+		}
+	}
+	if len(pending) == 0 {
+		sh.logger.Info("DB schema is up to date", zap.Uint64("schema_version", version))
+		return nil, nil
+	}
+
+	if dryRun {
+		err := sh.update(func(tx *sql.Tx) error {
+			for _, m := range pending {
+				sh.logger.Info("Dry running DB migration", zap.Uint64("version", m.Version), zap.String("description", m.Description))
+				if err := sh.applyMigration(tx, m); err != nil {
+					return err
+				}
+			}
+			return errDryRun
+		})
+		if !errors.Is(err, errDryRun) {
+			return nil, err
+		}
+		return applied, nil
+	}
+
+	for _, m := range pending {
+		sh.logger.Info("Applying DB migration", zap.Uint64("version", m.Version), zap.String("description", m.Description))
+		if err := sh.update(func(tx *sql.Tx) error { return sh.applyMigration(tx, m) }); err != nil {
+			return nil, err
+		}
+	}
+	return applied, nil
+}
+
+func (sh *SQLHandler) InsertBlocks(blocks []*types.Block) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	sh.logger.Info("Batch inserting blocks to DB", zap.Int("count", len(blocks)))
+
+	return sh.update(func(tx *sql.Tx) error {
+		return sh.insertBlocks(tx, blocks)
+	})
+}
+
+// InsertBlocksWithCheckpoint stores the blocks and the processing checkpoint in a single transaction.
+// blocks can be empty to only move the checkpoint.
+func (sh *SQLHandler) InsertBlocksWithCheckpoint(blocks []*types.Block, checkpoint *types.ProcessingCheckpoint) error {
+	sh.logger.Debug("Inserting blocks with processing checkpoint",
+		zap.Int("count", len(blocks)),
+		zap.Uint64("checkpoint_height", checkpoint.Height),
+		zap.Stringer("checkpoint_outcome", checkpoint.Outcome))
+
+	return sh.update(func(tx *sql.Tx) error {
+		if len(blocks) > 0 {
+			if err := sh.insertBlocks(tx, blocks); err != nil {
+				return err
+			}
+		}
+		return sh.putCheckpoint(tx, checkpoint)
+	})
+}
+
+// GetProcessingCheckpoint returns the processing checkpoint, nil if none was stored yet
+func (sh *SQLHandler) GetProcessingCheckpoint() (*types.ProcessingCheckpoint, error) {
+	checkpoint, err := sh.getCheckpoint(sh.db)
+	if err != nil {
+		sh.logger.Error("Error getting processing checkpoint", zap.Error(err))
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+func (sh *SQLHandler) GetBlockByHeight(height uint64) (*types.Block, error) {
+	return sh.getBlock(`WHERE block_height = ?`, height)
+}
+
+func (sh *SQLHandler) GetBlockByHash(hash string) (*types.Block, error) {
+	return sh.getBlock(`WHERE block_hash = ? ORDER BY block_height DESC LIMIT 1`, hash)
+}
+
+// GetBlocksFromHeight returns up to limit blocks at or above the given height, in ascending
+// height order. Heights without a finalized block are skipped.
+func (sh *SQLHandler) GetBlocksFromHeight(height uint64, limit uint64) ([]*types.Block, error) {
+	rows, err := sh.db.Query(sh.rebind(`
+		SELECT block_height, block_hash, block_timestamp, babylon_height FROM blocks
+		WHERE block_height >= ? ORDER BY block_height LIMIT ?`), height, min(limit, math.MaxInt64))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []*types.Block
+	for rows.Next() {
+		var block types.Block
+		if err := rows.Scan(&block.BlockHeight, &block.BlockHash, &block.BlockTimestamp, &block.BabylonHeight); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, &block)
+	}
+	return blocks, rows.Err()
+}
+
+// ListBlocks returns up to query.Limit blocks matching the query, in its height order. The timestamp
+// bounds use the index on block_timestamp.
+func (sh *SQLHandler) ListBlocks(query types.BlockQuery) ([]*types.Block, error) {
+	var blocks []*types.Block
+	if query.Limit == 0 {
+		return blocks, nil
+	}
+	toHeight, toTimestamp := query.ToHeight, query.ToTimestamp
+	if toHeight == 0 {
+		toHeight = math.MaxInt64
+	}
+	if toTimestamp == 0 {
+		toTimestamp = math.MaxInt64
+	}
+	order := "ASC"
+	if query.Descending {
+		order = "DESC"
+	}
+	rows, err := sh.db.Query(sh.rebind(`
+		SELECT block_height, block_hash, block_timestamp, babylon_height FROM blocks
+		WHERE block_height >= ? AND block_height <= ? AND block_timestamp >= ? AND block_timestamp <= ?
+		ORDER BY block_height `+order+` LIMIT ?`),
+		min(query.FromHeight, math.MaxInt64), min(toHeight, math.MaxInt64),
+		min(query.FromTimestamp, math.MaxInt64), min(toTimestamp, math.MaxInt64),
+		min(query.Limit, math.MaxInt64))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var block types.Block
+		if err := rows.Scan(&block.BlockHeight, &block.BlockHash, &block.BlockTimestamp, &block.BabylonHeight); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, &block)
+	}
+	return blocks, rows.Err()
+}
+
+func (sh *SQLHandler) GetEvidenceByHeight(height uint64) (*types.FinalityEvidence, error) {
+	var evidence types.FinalityEvidence
+	err := sh.db.QueryRow(sh.rebind(`
+		SELECT block_height, block_hash, babylon_height, btc_height, total_power, voted_power, quorum_ratio,
+			quorum_power
+		FROM finality_evidence WHERE block_height = ?`), height).Scan(
+		&evidence.BlockHeight,
+		&evidence.BlockHash,
+		&evidence.BabylonHeight,
+		&evidence.BtcHeight,
+		&evidence.TotalPower,
+		&evidence.VotedPower,
+		&evidence.QuorumRatio,
+		&evidence.QuorumPower,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, types.ErrEvidenceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := sh.db.Query(sh.rebind(`
+		SELECT fp_btc_pk_hex, power FROM finality_votes WHERE block_height = ? ORDER BY position`), height)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var voter types.VoterPower
+		if err := rows.Scan(&voter.FpBtcPkHex, &voter.Power); err != nil {
+			return nil, err
+		}
+		evidence.Voters = append(evidence.Voters, &voter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &evidence, nil
+}
+
+func (sh *SQLHandler) GetEvidenceByHash(hash string) (*types.FinalityEvidence, error) {
+	block, err := sh.GetBlockByHash(hash)
+	if errors.Is(err, types.ErrBlockNotFound) {
+		return nil, types.ErrEvidenceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sh.GetEvidenceByHeight(block.BlockHeight)
+}
+
+func (sh *SQLHandler) QueryIsBlockFinalizedByHeight(height uint64) (bool, error) {
+	_, err := sh.GetBlockByHeight(height)
+	if err != nil {
+		if errors.Is(err, types.ErrBlockNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (sh *SQLHandler) QueryIsBlockFinalizedByHash(hash string) (bool, error) {
+	_, err := sh.GetBlockByHash(hash)
+	if err != nil {
+		if errors.Is(err, types.ErrBlockNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (sh *SQLHandler) QueryEarliestFinalizedBlock() (*types.Block, error) {
+	height, err := sh.getIndex(sh.db, earliestBlockKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, types.ErrBlockNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sh.GetBlockByHeight(height)
+}
+
+func (sh *SQLHandler) QueryLatestFinalizedBlock() (*types.Block, error) {
+	height, err := sh.getIndex(sh.db, latestBlockKey)
+	// If no latest block has been stored yet, return nil
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		sh.logger.Error("Error getting latest block", zap.Error(err))
+		return nil, err
+	}
+	return sh.GetBlockByHeight(height)
+}
+
+// DeleteBlocksFromHeight removes all blocks at or above the given height with their evidence and votes, moves the
+// latest index back to the highest remaining block and rewinds the processing checkpoint below height. This is used
+// to roll back the db after an L2 reorg.
+func (sh *SQLHandler) DeleteBlocksFromHeight(height uint64) error {
+	sh.logger.Info("Deleting blocks from height", zap.Uint64("from_height", height))
+
+	return sh.update(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(sh.rebind(`DELETE FROM blocks WHERE block_height >= ?`), height); err != nil {
+			sh.logger.Error("Error deleting blocks", zap.Error(err))
+			return err
+		}
+		if err := sh.deleteEvidenceFromHeight(tx, height, math.MaxInt64); err != nil {
+			return err
+		}
+
+		// Rewind the processing checkpoint, so the deleted blocks are processed again
+		checkpoint, err := sh.getCheckpoint(tx)
+		if err != nil {
+			return err
+		}
+		if rewound := rewoundCheckpoint(checkpoint, height); rewound != nil {
+			if err := sh.putCheckpoint(tx, rewound); err != nil {
+				return err
+			}
+		}
+
+		// Point the latest index at the highest remaining block, or clear the
+		// indices if no block is left
+		var lastHeight sql.NullInt64
+		if err := tx.QueryRow(`SELECT MAX(block_height) FROM blocks`).Scan(&lastHeight); err != nil {
+			return err
+		}
+		if !lastHeight.Valid {
+			_, err := tx.Exec(sh.rebind(`DELETE FROM indexer WHERE key IN (?, ?)`), earliestBlockKey, latestBlockKey)
+			return err
+		}
+		return sh.putIndex(tx, latestBlockKey, uint64(lastHeight.Int64))
+	})
+}
+
+// DeleteBlocksBeforeHeight removes up to limit of the lowest blocks below the given height with their evidence
+// and votes, moves the earliest index up to the lowest remaining block and returns the number of blocks removed.
+// This is used to prune blocks out of the retention window in small batches.
+func (sh *SQLHandler) DeleteBlocksBeforeHeight(height uint64, limit uint64) (uint64, error) {
+	var deleted uint64
+	err := sh.update(func(tx *sql.Tx) error {
+		// Find the height below which the batch deletes, as not every backend supports DELETE ... LIMIT
+		var lastHeight sql.NullInt64
+		err := tx.QueryRow(sh.rebind(`
+			SELECT MAX(block_height) FROM (
+				SELECT block_height FROM blocks WHERE block_height < ? ORDER BY block_height LIMIT ?
+			) batch`), min(height, math.MaxInt64), min(limit, math.MaxInt64)).Scan(&lastHeight)
+		if err != nil {
+			return err
+		}
+		if !lastHeight.Valid {
+			return nil
+		}
+
+		res, err := tx.Exec(sh.rebind(`DELETE FROM blocks WHERE block_height <= ?`), lastHeight.Int64)
+		if err != nil {
+			sh.logger.Error("Error deleting blocks", zap.Error(err))
+			return err
+		}
+		count, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		deleted = uint64(count)
+		if err := sh.deleteEvidenceFromHeight(tx, 0, uint64(lastHeight.Int64)); err != nil {
+			return err
+		}
+
+		// Point the earliest index at the lowest remaining block, or clear the
+		// indices if no block is left
+		var firstHeight sql.NullInt64
+		if err := tx.QueryRow(`SELECT MIN(block_height) FROM blocks`).Scan(&firstHeight); err != nil {
+			return err
+		}
+		if !firstHeight.Valid {
+			_, err := tx.Exec(sh.rebind(`DELETE FROM indexer WHERE key IN (?, ?)`), earliestBlockKey, latestBlockKey)
+			return err
+		}
+		return sh.putIndex(tx, earliestBlockKey, uint64(firstHeight.Int64))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func (sh *SQLHandler) GetActivatedTimestamp() (uint64, error) {
+	timestamp, err := sh.getIndex(sh.db, activatedTimestampKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return math.MaxUint64, types.ErrActivatedTimestampNotFound
+	}
+	if err != nil {
+		return math.MaxUint64, err
+	}
+	return timestamp, nil
+}
+
+func (sh *SQLHandler) SaveActivatedTimestamp(timestamp uint64) error {
+	return sh.putIndex(sh.db, activatedTimestampKey, timestamp)
+}
+
+// InsertBtcHeaders stores BTC headers keyed by height, replacing any header stored at the same height
+func (sh *SQLHandler) InsertBtcHeaders(headers []*types.BtcHeader) error {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	sh.logger.Debug("Batch inserting BTC headers to DB", zap.Int("count", len(headers)))
+
+	return sh.update(func(tx *sql.Tx) error {
+		for _, header := range headers {
+			_, err := tx.Exec(sh.rebind(`
+				INSERT INTO btc_headers (height, hash, block_time) VALUES (?, ?, ?)
+				ON CONFLICT (height) DO UPDATE SET hash = excluded.hash, block_time = excluded.block_time`),
+				header.Height, header.Hash, header.Timestamp)
+			if err != nil {
+				sh.logger.Error("Error inserting BTC header", zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (sh *SQLHandler) GetBtcHeaderByHeight(height uint32) (*types.BtcHeader, error) {
+	return sh.getBtcHeader(`WHERE height = ?`, height)
+}
+
+// GetBtcHeightByTimestamp resolves a timestamp to a BTC height from the header index, see searchBtcHeight
+func (sh *SQLHandler) GetBtcHeightByTimestamp(timestamp uint64) (uint32, error) {
+	earliest, err := sh.QueryEarliestBtcHeader()
+	if err != nil {
+		return 0, err
+	}
+	latest, err := sh.QueryLatestBtcHeader()
+	if err != nil {
+		return 0, err
+	}
+	return searchBtcHeight(earliest, latest, timestamp, sh.GetBtcHeaderByHeight)
+}
+
+// QueryEarliestBtcHeader returns the lowest indexed BTC header, or nil if the index is empty
+func (sh *SQLHandler) QueryEarliestBtcHeader() (*types.BtcHeader, error) {
+	header, err := sh.getBtcHeader(`ORDER BY height LIMIT 1`)
+	if errors.Is(err, types.ErrBtcHeaderNotFound) {
+		return nil, nil
+	}
+	return header, err
+}
+
+// QueryLatestBtcHeader returns the highest indexed BTC header, or nil if the index is empty
+func (sh *SQLHandler) QueryLatestBtcHeader() (*types.BtcHeader, error) {
+	header, err := sh.getBtcHeader(`ORDER BY height DESC LIMIT 1`)
+	if errors.Is(err, types.ErrBtcHeaderNotFound) {
+		return nil, nil
+	}
+	return header, err
+}
+
+// DeleteBtcHeadersFromHeight removes all BTC headers at or above the given height.
+// This is used to drop headers reorged out of the BTC chain.
+func (sh *SQLHandler) DeleteBtcHeadersFromHeight(height uint32) error {
+	sh.logger.Info("Deleting BTC headers from height", zap.Uint32("from_height", height))
+
+	_, err := sh.db.Exec(sh.rebind(`DELETE FROM btc_headers WHERE height >= ?`), height)
+	return err
+}
+
+func (sh *SQLHandler) Close() error {
+	sh.logger.Info("Closing DB...")
+	return sh.db.Close()
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+// sqlQuerier is implemented by both *sql.DB and *sql.Tx
+type sqlQuerier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// update runs fn in a transaction, committed if fn succeeds and rolled back otherwise
+func (sh *SQLHandler) update(fn func(tx *sql.Tx) error) error {
+	tx, err := sh.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			sh.logger.Error("Error rolling back transaction", zap.Error(rollbackErr))
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func (sh *SQLHandler) applyMigration(tx *sql.Tx, m sqlMigration) error {
+	// both lib/pq and go-sqlite3 run every statement of a multi-statement Exec
+	if _, err := tx.Exec(m.statements); err != nil {
+		sh.logger.Error("Error applying DB migration", zap.Uint64("version", m.Version), zap.Error(err))
+		return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Description, err)
+	}
+	_, err := tx.Exec(sh.rebind(`INSERT INTO schema_migrations (version) VALUES (?)`), m.Version)
+	return err
+}
+
+// rebind replaces the ? placeholders of the query with $1, $2... for Postgres
+func (sh *SQLHandler) rebind(query string) string {
+	if sh.driver != postgresDriver {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (sh *SQLHandler) getBlock(where string, args ...any) (*types.Block, error) {
+	var block types.Block
+	err := sh.db.QueryRow(sh.rebind(`SELECT block_height, block_hash, block_timestamp, babylon_height FROM blocks `+where), args...).
+		Scan(&block.BlockHeight, &block.BlockHash, &block.BlockTimestamp, &block.BabylonHeight)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, types.ErrBlockNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (sh *SQLHandler) getBtcHeader(where string, args ...any) (*types.BtcHeader, error) {
+	var header types.BtcHeader
+	err := sh.db.QueryRow(sh.rebind(`SELECT height, hash, block_time FROM btc_headers `+where), args...).
+		Scan(&header.Height, &header.Hash, &header.Timestamp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, types.ErrBtcHeaderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &header, nil
+}
+
+// insertEvidence stores the block's finality evidence and votes under its height and hash
+func (sh *SQLHandler) insertEvidence(tx *sql.Tx, block *types.Block) error {
+	evidence := block.Evidence
+	_, err := tx.Exec(sh.rebind(`
+		INSERT INTO finality_evidence
+			(block_height, block_hash, babylon_height, btc_height, total_power, voted_power, quorum_ratio,
+			quorum_power)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+		block.BlockHeight, block.BlockHash, evidence.BabylonHeight, evidence.BtcHeight,
+		evidence.TotalPower, evidence.VotedPower, evidence.QuorumRatio, evidence.QuorumPower)
+	if err != nil {
+		sh.logger.Error("Error inserting finality evidence", zap.Error(err))
+		return err
+	}
+	for i, voter := range evidence.Voters {
+		_, err := tx.Exec(sh.rebind(`
+			INSERT INTO finality_votes (block_height, position, fp_btc_pk_hex, power) VALUES (?, ?, ?, ?)`),
+			block.BlockHeight, i, voter.FpBtcPkHex, voter.Power)
+		if err != nil {
+			sh.logger.Error("Error inserting finality vote", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteEvidenceFromHeight removes the evidence and votes of the blocks in [fromHeight, toHeight]
+func (sh *SQLHandler) deleteEvidenceFromHeight(tx *sql.Tx, fromHeight uint64, toHeight uint64) error {
+	for _, table := range []string{"finality_evidence", "finality_votes"} {
+		_, err := tx.Exec(sh.rebind(`DELETE FROM `+table+` WHERE block_height >= ? AND block_height <= ?`), fromHeight, toHeight)
+		if err != nil {
+			sh.logger.Error("Error deleting finality evidence", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// insertBlocks stores the blocks with their evidence and votes, and moves the indices to cover them
+func (sh *SQLHandler) insertBlocks(tx *sql.Tx, blocks []*types.Block) error {
+	var minHeight, maxHeight uint64 = math.MaxUint64, 0
+
+	for _, block := range blocks {
+		minHeight = min(minHeight, block.BlockHeight)
+		maxHeight = max(maxHeight, block.BlockHeight)
+
+		// Replacing a block at the same height (e.g. after an L2 reorg) drops the stale hash with it
+		_, err := tx.Exec(sh.rebind(`
+			INSERT INTO blocks (block_height, block_hash, block_timestamp, babylon_height) VALUES (?, ?, ?, ?)
+			ON CONFLICT (block_height) DO UPDATE SET
+				block_hash = excluded.block_hash,
+				block_timestamp = excluded.block_timestamp,
+				babylon_height = excluded.babylon_height`),
+			block.BlockHeight, block.BlockHash, block.BlockTimestamp, block.BabylonHeight)
+		if err != nil {
+			sh.logger.Error("Error inserting block to db", zap.Error(err))
+			return err
+		}
+
+		// Store finality evidence, or drop any evidence left from a replaced block
+		if err := sh.deleteEvidenceFromHeight(tx, block.BlockHeight, block.BlockHeight); err != nil {
+			return err
+		}
+		if block.Evidence != nil {
+			if err := sh.insertEvidence(tx, block); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Update earliest block if needed
+	if _, err := sh.getIndex(tx, earliestBlockKey); errors.Is(err, sql.ErrNoRows) {
+		if err := sh.putIndex(tx, earliestBlockKey, minHeight); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	// Update latest block if needed
+	currentLatest, err := sh.getIndex(tx, latestBlockKey)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if maxHeight > currentLatest {
+		return sh.putIndex(tx, latestBlockKey, maxHeight)
+	}
+	return nil
+}
+
+// getCheckpoint returns the processing checkpoint, nil if none was stored yet
+func (sh *SQLHandler) getCheckpoint(q sqlQuerier) (*types.ProcessingCheckpoint, error) {
+	height, err := sh.getIndex(q, checkpointHeightKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	outcome, err := sh.getIndex(q, checkpointOutcomeKey)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := sh.getIndex(q, checkpointTimestampKey)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ProcessingCheckpoint{
+		Height:    height,
+		Outcome:   types.CheckpointOutcome(outcome),
+		Timestamp: timestamp,
+	}, nil
+}
+
+func (sh *SQLHandler) putCheckpoint(q sqlQuerier, checkpoint *types.ProcessingCheckpoint) error {
+	if err := sh.putIndex(q, checkpointHeightKey, checkpoint.Height); err != nil {
+		return err
+	}
+	if err := sh.putIndex(q, checkpointOutcomeKey, uint64(checkpoint.Outcome)); err != nil {
+		return err
+	}
+	return sh.putIndex(q, checkpointTimestampKey, checkpoint.Timestamp)
+}
+
+func (sh *SQLHandler) getIndex(q sqlQuerier, key string) (uint64, error) {
+	var value uint64
+	err := q.QueryRow(sh.rebind(`SELECT value FROM indexer WHERE key = ?`), key).Scan(&value)
+	return value, err
+}
+
+func (sh *SQLHandler) putIndex(q sqlQuerier, key string, value uint64) error {
+	_, err := q.Exec(sh.rebind(`
+		INSERT INTO indexer (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`), key, value)
+	if err != nil {
+		sh.logger.Error("Error updating index", zap.String("key", key), zap.Error(err))
+	}
+	return err
+}
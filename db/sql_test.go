@@ -0,0 +1,112 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/babylonlabs-io/finality-gadget/log"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSQLiteHandler(t *testing.T) {
+	runConformanceSuite(t, func(t *testing.T) (IDatabaseHandler, func()) {
+		logger, err := log.NewRootLogger("console", zap.DebugLevel)
+		require.NoError(t, err)
+
+		handler, err := NewSQLiteHandler(filepath.Join(t.TempDir(), "data.sqlite"), logger)
+		require.NoError(t, err)
+		require.NoError(t, handler.CreateInitialSchema())
+
+		return handler, func() { handler.Close() }
+	})
+}
+
+// TestPostgresHandler runs against the Postgres db at FG_TEST_POSTGRES_URL, which it wipes, and is skipped if unset
+func TestPostgresHandler(t *testing.T) {
+	url := os.Getenv("FG_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("FG_TEST_POSTGRES_URL not set")
+	}
+
+	runConformanceSuite(t, func(t *testing.T) (IDatabaseHandler, func()) {
+		logger, err := log.NewRootLogger("console", zap.DebugLevel)
+		require.NoError(t, err)
+
+		handler, err := NewPostgresHandler(url, logger)
+		require.NoError(t, err)
+		_, err = handler.db.Exec(`DROP TABLE IF EXISTS schema_migrations, blocks, finality_evidence, finality_votes, btc_headers, indexer`)
+		require.NoError(t, err)
+		require.NoError(t, handler.CreateInitialSchema())
+
+		return handler, func() { handler.Close() }
+	})
+}
+
+func TestSQLiteHandlerMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.sqlite")
+
+	handler, err := NewSQLiteHandler(path, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, handler.CreateInitialSchema())
+	require.NoError(t, handler.SaveActivatedTimestamp(1000))
+	require.NoError(t, handler.Close())
+
+	// Reopening applies no migration twice and keeps the data
+	handler, err = NewSQLiteHandler(path, zap.NewNop())
+	require.NoError(t, err)
+	defer handler.Close()
+	require.NoError(t, handler.CreateInitialSchema())
+
+	version, err := handler.SchemaVersion()
+	require.NoError(t, err)
+	require.Equal(t, sqlMigrations[len(sqlMigrations)-1].Version, version)
+	pending, err := handler.PendingMigrations()
+	require.NoError(t, err)
+	require.Empty(t, pending)
+	timestamp, err := handler.GetActivatedTimestamp()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000), timestamp)
+
+	// A db migrated by a newer release is refused
+	_, err = handler.db.Exec(`INSERT INTO schema_migrations (version) VALUES (?)`, version+1)
+	require.NoError(t, err)
+	require.Error(t, handler.CreateInitialSchema())
+	_, err = handler.PendingMigrations()
+	require.Error(t, err)
+}
+
+func TestSQLiteHandlerEvidenceQuorumMigration(t *testing.T) {
+	handler, err := NewSQLiteHandler(filepath.Join(t.TempDir(), "data.sqlite"), zap.NewNop())
+	require.NoError(t, err)
+	defer handler.Close()
+
+	// Simulate a db at schema version 1, whose evidence stores the observed ratio
+	latest := sqlMigrations
+	sqlMigrations = latest[:1]
+	err = handler.CreateInitialSchema()
+	sqlMigrations = latest
+	require.NoError(t, err)
+	_, err = handler.db.Exec(`
+		INSERT INTO finality_evidence
+			(block_height, block_hash, babylon_height, btc_height, total_power, voted_power, quorum_ratio)
+		VALUES (1, '0x123', 10, 100, 300, 250, ?)`, 250.0/300.0)
+	require.NoError(t, err)
+
+	_, err = handler.Migrate(false)
+	require.NoError(t, err)
+	evidence, err := handler.GetEvidenceByHeight(1)
+	require.NoError(t, err)
+	require.Equal(t, uint64(250), evidence.VotedPower)
+	require.Zero(t, evidence.QuorumRatio)
+	require.Zero(t, evidence.QuorumPower)
+}
+
+func TestSQLRebind(t *testing.T) {
+	sh := &SQLHandler{driver: postgresDriver}
+	require.Equal(t, "SELECT a FROM t WHERE b = $1 AND c > $2", sh.rebind("SELECT a FROM t WHERE b = ? AND c > ?"))
+
+	sh = &SQLHandler{driver: sqliteDriver}
+	require.Equal(t, "SELECT a FROM t WHERE b = ?", sh.rebind("SELECT a FROM t WHERE b = ?"))
+}
@@ -2,12 +2,22 @@ package ethl2client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 
+	"github.com/babylonlabs-io/finality-gadget/types"
 	"github.com/ethereum/go-ethereum/common"
 	eth "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// JSON-RPC error codes of calls the L2 node will never serve
+const (
+	rpcMethodNotFoundCode = -32601
+	rpcInvalidParamsCode  = -32602
 )
 
 type EthL2Client struct {
@@ -34,14 +44,48 @@ func NewEthL2Client(rpcHostAddr string) (*EthL2Client, error) {
 //////////////////////////////
 
 func (c *EthL2Client) HeaderByNumber(ctx context.Context, number *big.Int) (*eth.Header, error) {
-	return c.client.HeaderByNumber(ctx, number)
+	header, err := c.client.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return header, nil
 }
 
 func (ec *EthL2Client) TransactionReceipt(ctx context.Context, txHash string) (*eth.Receipt, error) {
 	hash := common.HexToHash(txHash)
-	return ec.client.TransactionReceipt(ctx, hash)
+	receipt, err := ec.client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return receipt, nil
 }
 
 func (c *EthL2Client) Close() {
 	c.client.Close()
 }
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+// classifyError marks the error of an L2 RPC call as permanent if the node rejects the request itself,
+// ie. bad credentials, a wrong endpoint or an unsupported method, and as transient otherwise
+func classifyError(err error) error {
+	var httpErr ethrpc.HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusMethodNotAllowed:
+			return types.NewUpstreamError(types.UpstreamL2, types.ErrorClassPermanent, err)
+		}
+		return types.NewUpstreamError(types.UpstreamL2, types.ErrorClassTransient, err)
+	}
+
+	var rpcErr ethrpc.Error
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.ErrorCode() {
+		case rpcMethodNotFoundCode, rpcInvalidParamsCode:
+			return types.NewUpstreamError(types.UpstreamL2, types.ErrorClassPermanent, err)
+		}
+	}
+	return types.ClassifyUpstreamError(types.UpstreamL2, err)
+}
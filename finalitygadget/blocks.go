@@ -0,0 +1,91 @@
+package finalitygadget
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+)
+
+const (
+	// defaultListLimit is the page size of block listings that don't set one
+	defaultListLimit = 100
+	// maxListLimit bounds the page size of block listings
+	maxListLimit = 1000
+)
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+/* ListFinalizedBlocks returns a page of the finalized blocks matching the query, by querying the local db
+ *
+ * - the limit defaults to defaultListLimit and is capped at maxListLimit
+ * - cursor is the NextCursor of the previous page, empty for the first page; it holds the height the
+ *   listing resumes from, so pages stay consistent while new blocks are finalized
+ * - NextCursor is only set if more blocks match after the page
+ */
+func (fg *FinalityGadget) ListFinalizedBlocks(query types.BlockQuery, cursor string) (*types.BlockPage, error) {
+	if query.ToHeight != 0 && query.FromHeight > query.ToHeight {
+		return nil, fmt.Errorf("%w: from height %d is above to height %d", types.ErrInvalidBlockRange, query.FromHeight, query.ToHeight)
+	}
+	if query.ToTimestamp != 0 && query.FromTimestamp > query.ToTimestamp {
+		return nil, fmt.Errorf("%w: from timestamp %d is after to timestamp %d", types.ErrInvalidBlockRange, query.FromTimestamp, query.ToTimestamp)
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+	query.Limit = min(query.Limit, maxListLimit)
+
+	if cursor != "" {
+		height, err := decodeListCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if query.Descending {
+			if query.ToHeight == 0 || height < query.ToHeight {
+				query.ToHeight = height
+			}
+		} else {
+			query.FromHeight = max(query.FromHeight, height)
+		}
+	}
+
+	// Fetch one more block than the limit, to know if there is a next page
+	limit := query.Limit
+	query.Limit++
+	blocks, err := fg.db.ListBlocks(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &types.BlockPage{Blocks: blocks}
+	if uint64(len(blocks)) > limit {
+		page.Blocks = blocks[:limit]
+		page.NextCursor = encodeListCursor(blocks[limit].BlockHeight)
+	}
+	return page, nil
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+// encodeListCursor returns the cursor resuming a listing at the given height. Cursors are opaque to clients.
+func encodeListCursor(height uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(height, 10)))
+}
+
+func decodeListCursor(cursor string) (uint64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", types.ErrInvalidCursor, cursor)
+	}
+	height, err := strconv.ParseUint(string(decoded), 10, 64)
+	// the L2 genesis block is never finalized by the gadget, so no cursor holds a height of 0
+	if err != nil || height == 0 {
+		return 0, fmt.Errorf("%w: %q", types.ErrInvalidCursor, cursor)
+	}
+	return height, nil
+}
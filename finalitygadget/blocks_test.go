@@ -0,0 +1,70 @@
+package finalitygadget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// listAllPages lists the blocks matching the query page by page, and returns their heights
+func listAllPages(t *testing.T, fg *FinalityGadget, query types.BlockQuery) []uint64 {
+	var heights []uint64
+	cursor := ""
+	for {
+		page, err := fg.ListFinalizedBlocks(query, cursor)
+		require.NoError(t, err)
+		require.LessOrEqual(t, uint64(len(page.Blocks)), query.Limit)
+		for _, block := range page.Blocks {
+			heights = append(heights, block.BlockHeight)
+		}
+		if page.NextCursor == "" {
+			return heights
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func TestListFinalizedBlocks(t *testing.T) {
+	now := time.Now()
+	fg := &FinalityGadget{
+		db:     setupRetentionDB(t, 10, 30, now),
+		logger: zap.NewNop(),
+	}
+
+	// Ascending pages of 4 over [12, 25]
+	heights := listAllPages(t, fg, types.BlockQuery{FromHeight: 12, ToHeight: 25, Limit: 4})
+	require.Len(t, heights, 14)
+	require.Equal(t, uint64(12), heights[0])
+	require.Equal(t, uint64(25), heights[13])
+
+	// Descending pages of 3 over the whole db
+	heights = listAllPages(t, fg, types.BlockQuery{Descending: true, Limit: 3})
+	require.Len(t, heights, 21)
+	require.Equal(t, uint64(30), heights[0])
+	require.Equal(t, uint64(10), heights[20])
+
+	// Timestamps of the last 5 minutes hold the blocks from 25
+	page, err := fg.ListFinalizedBlocks(types.BlockQuery{FromTimestamp: uint64(now.Add(-5 * time.Minute).Unix())}, "")
+	require.NoError(t, err)
+	require.Len(t, page.Blocks, 6)
+	require.Equal(t, uint64(25), page.Blocks[0].BlockHeight)
+	require.Empty(t, page.NextCursor)
+
+	// The limit defaults and is capped
+	page, err = fg.ListFinalizedBlocks(types.BlockQuery{Limit: maxListLimit + 1}, "")
+	require.NoError(t, err)
+	require.Len(t, page.Blocks, 21)
+
+	// Invalid queries
+	_, err = fg.ListFinalizedBlocks(types.BlockQuery{FromHeight: 20, ToHeight: 10}, "")
+	require.ErrorIs(t, err, types.ErrInvalidBlockRange)
+	_, err = fg.ListFinalizedBlocks(types.BlockQuery{FromTimestamp: 20, ToTimestamp: 10}, "")
+	require.ErrorIs(t, err, types.ErrInvalidBlockRange)
+	_, err = fg.ListFinalizedBlocks(types.BlockQuery{}, "not a cursor")
+	require.ErrorIs(t, err, types.ErrInvalidCursor)
+	_, err = fg.ListFinalizedBlocks(types.BlockQuery{}, encodeListCursor(0))
+	require.ErrorIs(t, err, types.ErrInvalidCursor)
+}
@@ -0,0 +1,196 @@
+package finalitygadget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/metrics"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"go.uber.org/zap"
+)
+
+const (
+	// btcHeaderIndexDepth is how many BTC blocks below the tip the header index starts at when it's empty.
+	// Older timestamps are resolved over RPC.
+	btcHeaderIndexDepth = 2016
+	// btcReorgDepth is how many of the latest indexed headers are re-checked against the BTC node for reorgs
+	btcReorgDepth = 6
+	// btcHeaderSyncBatchSize is the number of headers fetched from the BTC node per db write
+	btcHeaderSyncBatchSize = 100
+)
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+// SyncBtcHeaders keeps the local BTC header index in sync with the BTC node, every poll interval until ctx is done
+func (fg *FinalityGadget) SyncBtcHeaders(ctx context.Context) {
+	ticker := time.NewTicker(fg.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := fg.syncBtcHeaders(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			fg.markDegraded(componentBtcHeaderSync, err)
+			fg.logger.Error("Failed to sync BTC header index", zap.Error(err))
+		} else if err == nil {
+			fg.clearDegraded(componentBtcHeaderSync)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+/* syncBtcHeaders brings the BTC header index up to the BTC node's tip
+ *
+ * - an empty index starts btcHeaderIndexDepth blocks below the tip
+ * - the latest btcReorgDepth indexed headers are re-checked first, and headers reorged out are dropped
+ * - if none of them is still on the node's chain, the index is rebuilt from scratch
+ * - new headers must link to the previous one, so a reorg during the sync is caught on the next round
+ * - the index is used for lookups once it has been synced once
+ */
+func (fg *FinalityGadget) syncBtcHeaders(ctx context.Context) error {
+	tip, err := fg.btcClient.GetBlockCount()
+	if err != nil {
+		return err
+	}
+
+	latest, err := fg.db.QueryLatestBtcHeader()
+	if err != nil {
+		return err
+	}
+	nextHeight := btcHeaderIndexStart(tip)
+	prevHash := ""
+	if latest != nil {
+		nextHeight, err = fg.dropReorgedBtcHeaders(latest, tip)
+		if err != nil {
+			return err
+		}
+		if nextHeight > 0 {
+			prev, err := fg.db.GetBtcHeaderByHeight(nextHeight - 1)
+			if err != nil && !errors.Is(err, types.ErrBtcHeaderNotFound) {
+				return err
+			}
+			if prev != nil {
+				prevHash = prev.Hash
+			}
+		}
+	}
+
+	for nextHeight <= tip {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		endHeight := min(tip, nextHeight+btcHeaderSyncBatchSize-1)
+		headers := make([]*types.BtcHeader, 0, endHeight-nextHeight+1)
+		for height := nextHeight; height <= endHeight; height++ {
+			hash, err := fg.btcClient.GetBlockHashByHeight(height)
+			if err != nil {
+				return err
+			}
+			header, err := fg.btcClient.GetBlockHeaderByHash(hash)
+			if err != nil {
+				return err
+			}
+			if prevHash != "" && header.PrevBlock.String() != prevHash {
+				return fmt.Errorf("BTC block %d (%s) doesn't extend the indexed chain, the BTC chain changed during the sync", height, hash)
+			}
+			timestamp := header.Timestamp.Unix()
+			if timestamp < 0 {
+				return fmt.Errorf("negative timestamp encountered: %d", timestamp)
+			}
+			headers = append(headers, &types.BtcHeader{Height: height, Hash: hash.String(), Timestamp: uint64(timestamp)})
+			prevHash = hash.String()
+		}
+		if err := fg.db.InsertBtcHeaders(headers); err != nil {
+			return err
+		}
+		metrics.BtcHeaderIndexLatestHeight.Set(float64(endHeight))
+		fg.logger.Debug("Synced BTC headers",
+			zap.Uint32("start_height", nextHeight),
+			zap.Uint32("end_height", endHeight),
+			zap.Uint32("tip_height", tip))
+		nextHeight = endHeight + 1
+	}
+
+	fg.btcHeaderIndexReady.Store(true)
+	return nil
+}
+
+// dropReorgedBtcHeaders removes the indexed headers that are no longer on the BTC node's chain
+// and returns the height to resume syncing from
+func (fg *FinalityGadget) dropReorgedBtcHeaders(latest *types.BtcHeader, tip uint32) (uint32, error) {
+	// the node's tip may be below the latest indexed header after a reorg to a shorter chain
+	checkHeight := min(latest.Height, tip)
+	for i := uint32(0); i < btcReorgDepth && i <= checkHeight; i++ {
+		height := checkHeight - i
+		stored, err := fg.db.GetBtcHeaderByHeight(height)
+		if errors.Is(err, types.ErrBtcHeaderNotFound) {
+			// below the earliest indexed header
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		hash, err := fg.btcClient.GetBlockHashByHeight(height)
+		if err != nil {
+			return 0, err
+		}
+		if hash.String() != stored.Hash {
+			continue
+		}
+
+		if height < latest.Height {
+			fg.logger.Warn("BTC reorg detected, dropping reorged headers from the index",
+				zap.Uint32("fork_height", height+1),
+				zap.Uint32("latest_indexed_height", latest.Height))
+			if err := fg.db.DeleteBtcHeadersFromHeight(height + 1); err != nil {
+				return 0, err
+			}
+		}
+		return height + 1, nil
+	}
+
+	fg.logger.Warn("BTC reorg deeper than the re-checked headers, rebuilding the header index",
+		zap.Uint32("latest_indexed_height", latest.Height),
+		zap.Uint32("tip_height", tip))
+	if err := fg.db.DeleteBtcHeadersFromHeight(0); err != nil {
+		return 0, err
+	}
+	return btcHeaderIndexStart(tip), nil
+}
+
+// getBtcHeightByTimestamp resolves a timestamp to a BTC height from the local header index,
+// falling back to a binary search over RPC if the index doesn't cover the timestamp yet
+func (fg *FinalityGadget) getBtcHeightByTimestamp(timestamp uint64) (uint32, error) {
+	if fg.btcHeaderIndexReady.Load() {
+		height, err := fg.db.GetBtcHeightByTimestamp(timestamp)
+		if err == nil {
+			metrics.BtcHeightLookupsTotal.WithLabelValues("index").Inc()
+			return height, nil
+		}
+		if !errors.Is(err, types.ErrBtcHeaderNotFound) {
+			fg.logger.Warn("Failed to resolve BTC height from the header index, falling back to RPC",
+				zap.Uint64("timestamp", timestamp),
+				zap.Error(err))
+		}
+	}
+
+	metrics.BtcHeightLookupsTotal.WithLabelValues("rpc").Inc()
+	return fg.btcClient.GetBlockHeightByTimestamp(timestamp)
+}
+
+// btcHeaderIndexStart returns the height an empty header index starts at
+func btcHeaderIndexStart(tip uint32) uint32 {
+	return tip - min(tip, btcHeaderIndexDepth-1)
+}
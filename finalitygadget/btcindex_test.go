@@ -0,0 +1,135 @@
+package finalitygadget
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/db"
+	"github.com/babylonlabs-io/finality-gadget/testutil/mocks"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+// testBtcChain is a linked chain of BTC headers served by a mock BTC client
+type testBtcChain struct {
+	headers []*wire.BlockHeader
+}
+
+// extend appends headers from the given height, replacing the headers above it. The nonce
+// distinguishes the branches, so a reorged header gets another hash at the same height.
+func (c *testBtcChain) extend(fromHeight uint32, count int, nonce uint32) {
+	c.headers = c.headers[:fromHeight]
+	for i := 0; i < count; i++ {
+		header := &wire.BlockHeader{
+			Timestamp: time.Unix(1_700_000_000+int64(len(c.headers))*600, 0),
+			Nonce:     nonce,
+		}
+		if len(c.headers) > 0 {
+			header.PrevBlock = c.headers[len(c.headers)-1].BlockHash()
+		}
+		c.headers = append(c.headers, header)
+	}
+}
+
+func (c *testBtcChain) mockClient(ctl *gomock.Controller) *mocks.MockIBitcoinClient {
+	client := mocks.NewMockIBitcoinClient(ctl)
+	client.EXPECT().GetBlockCount().DoAndReturn(func() (uint32, error) {
+		return uint32(len(c.headers) - 1), nil
+	}).AnyTimes()
+	client.EXPECT().GetBlockHashByHeight(gomock.Any()).DoAndReturn(func(height uint32) (*chainhash.Hash, error) {
+		hash := c.headers[height].BlockHash()
+		return &hash, nil
+	}).AnyTimes()
+	client.EXPECT().GetBlockHeaderByHash(gomock.Any()).DoAndReturn(func(hash *chainhash.Hash) (*wire.BlockHeader, error) {
+		for _, header := range c.headers {
+			if header.BlockHash() == *hash {
+				return header, nil
+			}
+		}
+		return nil, nil
+	}).AnyTimes()
+	return client
+}
+
+func TestSyncBtcHeaders(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	require.NoError(t, err)
+	defer dbHandler.Close()
+	require.NoError(t, dbHandler.CreateInitialSchema())
+
+	chain := &testBtcChain{}
+	chain.extend(0, 11, 0)
+	fg := &FinalityGadget{
+		btcClient: chain.mockClient(ctl),
+		db:        dbHandler,
+		logger:    zap.NewNop(),
+	}
+
+	// the index isn't used before the first sync
+	require.False(t, fg.btcHeaderIndexReady.Load())
+
+	require.NoError(t, fg.syncBtcHeaders(context.Background()))
+	require.True(t, fg.btcHeaderIndexReady.Load())
+	latest, err := dbHandler.QueryLatestBtcHeader()
+	require.NoError(t, err)
+	require.Equal(t, uint32(10), latest.Height)
+	require.Equal(t, chain.headers[10].BlockHash().String(), latest.Hash)
+
+	// timestamps within the index are resolved locally
+	height, err := fg.getBtcHeightByTimestamp(uint64(chain.headers[7].Timestamp.Unix()) + 1)
+	require.NoError(t, err)
+	require.Equal(t, uint32(7), height)
+
+	// a reorg of the last blocks replaces the reorged headers
+	chain.extend(8, 4, 1)
+	require.NoError(t, fg.syncBtcHeaders(context.Background()))
+	for height := uint32(0); height <= 11; height++ {
+		header, err := dbHandler.GetBtcHeaderByHeight(height)
+		require.NoError(t, err)
+		require.Equal(t, chain.headers[height].BlockHash().String(), header.Hash)
+	}
+
+	// a reorg deeper than the re-checked headers rebuilds the index
+	chain.extend(1, 12, 2)
+	require.NoError(t, fg.syncBtcHeaders(context.Background()))
+	for height := uint32(0); height <= 12; height++ {
+		header, err := dbHandler.GetBtcHeaderByHeight(height)
+		require.NoError(t, err)
+		require.Equal(t, chain.headers[height].BlockHash().String(), header.Hash)
+	}
+}
+
+func TestGetBtcHeightByTimestampFallsBackToRPC(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	require.NoError(t, err)
+	defer dbHandler.Close()
+	require.NoError(t, dbHandler.CreateInitialSchema())
+
+	chain := &testBtcChain{}
+	chain.extend(0, 5, 0)
+	mockBTCClient := chain.mockClient(ctl)
+	fg := &FinalityGadget{
+		btcClient: mockBTCClient,
+		db:        dbHandler,
+		logger:    zap.NewNop(),
+	}
+	require.NoError(t, fg.syncBtcHeaders(context.Background()))
+
+	// a timestamp after the latest indexed header may belong to a block the index hasn't synced yet
+	timestamp := uint64(chain.headers[4].Timestamp.Unix()) + 1
+	mockBTCClient.EXPECT().GetBlockHeightByTimestamp(timestamp).Return(uint32(5), nil).Times(1)
+	height, err := fg.getBtcHeightByTimestamp(timestamp)
+	require.NoError(t, err)
+	require.Equal(t, uint32(5), height)
+}
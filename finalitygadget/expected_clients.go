@@ -19,15 +19,22 @@ type IBitcoinClient interface {
 }
 
 type IBabylonClient interface {
-	QueryAllFpBtcPubKeys(consumerId string) ([]string, error)
+	QueryAllFpBtcPubKeys(consumerId string, babylonHeight uint64) ([]string, error)
 	QueryMultiFpPower(fpPubkeyHexList []string, btcHeight uint32) (map[string]uint64, error)
 	QueryEarliestActiveDelBtcHeight(fpPubkeyHexList []string) (uint32, error)
+	QueryBabylonHeightByTimestamp(targetTimestamp uint64) (uint64, error)
+	QueryLatestHeight() (uint64, error)
+	QueryLastFinalizedEpoch() (uint64, error)
+	QueryEpochInterval() (uint64, error)
 }
 
 type ICosmWasmClient interface {
-	QueryListOfVotedFinalityProviders(queryParams *types.Block) ([]string, error)
+	QueryBlockVoters(queryParams *types.Block) ([]*types.BlockVoter, error)
+	QueryBlockVotersAtHeight(queryParams *types.Block, babylonHeight uint64) ([]*types.BlockVoter, error)
+	QueryPubRandCommitForHeight(fpBtcPkHex string, height uint64) (*types.PubRandCommit, error)
 	QueryConsumerId() (string, error)
 	QueryConfig() (*types.ContractConfig, error)
+	QueryAllowedFinalityProviders(babylonHeight uint64) ([]string, error)
 }
 
 type IEthL2Client interface {
@@ -2,17 +2,22 @@ package finalitygadget
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	bbnclient "github.com/babylonlabs-io/babylon/v3/client/client"
 	bbncfg "github.com/babylonlabs-io/babylon/v3/client/config"
+	"github.com/babylonlabs-io/babylon/v3/crypto/eots"
+	bbntypes "github.com/babylonlabs-io/babylon/v3/types"
 	fgbbnclient "github.com/babylonlabs-io/finality-gadget/bbnclient"
 	"github.com/babylonlabs-io/finality-gadget/btcclient"
 	"github.com/babylonlabs-io/finality-gadget/config"
@@ -40,9 +45,42 @@ type FinalityGadget struct {
 	mutex  sync.Mutex
 
 	pollInterval        time.Duration
-	lastProcessedHeight uint64
+	lastProcessedHeight atomic.Uint64
 	batchSize           uint64
+	concurrency         uint64
+	verifyEotsSigs      bool
+	quorum              types.QuorumRule
+	fpPowerCache        *fpPowerCache
+	pubRandCommitCache  *pubRandCommitCache
+	btcHeaderIndexReady atomic.Bool
 	contractConfig      *types.ContractConfig
+	retentionBlocks     uint64
+	retentionPeriod     time.Duration
+	pruneInterval       time.Duration
+
+	subscribers      map[*blockSubscriber]struct{}
+	subscribersMutex sync.Mutex
+
+	degraded      map[string]*types.ComponentFailure
+	degradedMutex sync.Mutex
+
+	startTime       time.Time
+	readinessMaxLag uint64
+	processing      atomic.Bool
+	// lastPollTime is the unix time in nanoseconds of the last successful poll, l2HeadHeight the L2 head it saw
+	lastPollTime atomic.Int64
+	l2HeadHeight atomic.Uint64
+	// lastFinalizedTime is the unix time in nanoseconds the finalized height last advanced, 0 until the first commit
+	lastFinalizedTime atomic.Int64
+	// heartbeat is the unix time in nanoseconds the block processing last showed progress, successful or not
+	heartbeat atomic.Int64
+	// dependencyChecks holds the readiness checks in flight per dependency
+	dependencyChecks      map[string]*dependencyCheck
+	dependencyChecksMutex sync.Mutex
+
+	stallMaxDuration time.Duration
+	stallMaxLag      uint64
+	alertNotifier    *webhookNotifier
 }
 
 //////////////////////////////
@@ -58,7 +96,10 @@ func NewFinalityGadget(cfg *config.Config, db db.IDatabaseHandler, logger *zap.L
 		&bbnConfig,
 		logger,
 	)
-	bbnClient := fgbbnclient.NewBabylonClient(babylonClient.QueryClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Babylon client: %w", err)
+	}
+	bbnClient, err := fgbbnclient.NewBabylonClient(babylonClient.QueryClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Babylon client: %w", err)
 	}
@@ -99,25 +140,69 @@ func NewFinalityGadget(cfg *config.Config, db db.IDatabaseHandler, logger *zap.L
 		return nil, fmt.Errorf("failed to query contract config: %w", err)
 	}
 
+	quorum, err := resolveQuorumRule(cfg, contractConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	fpPowerCache, err := newFpPowerCache(fpPowerCacheSize, fpPowerCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	pubRandCommitCache, err := newPubRandCommitCache(pubRandCommitCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
 	// Determine the starting block height
 	lastProcessedHeight, err := determineStartingHeight(cfg, db, contractConfig, logger)
 	if err != nil {
 		return nil, err
 	}
 
+	pruneInterval := cfg.PruneInterval
+	if pruneInterval == 0 {
+		pruneInterval = defaultPruneInterval
+	}
+
+	// Evaluate as many heights in parallel as a batch holds, unless set
+	concurrency := cfg.ProcessingConcurrency
+	if concurrency == 0 {
+		concurrency = cfg.BatchSize
+	}
+
+	readinessMaxLag := cfg.ReadinessMaxLag
+	if readinessMaxLag == 0 {
+		readinessMaxLag = defaultReadinessMaxLag
+	}
+
 	// Create finality gadget
-	return &FinalityGadget{
-		btcClient:           btcClient,
-		bbnClient:           bbnClient,
-		cwClient:            cwClient,
-		l2Client:            l2Client,
-		db:                  db,
-		pollInterval:        cfg.PollInterval,
-		batchSize:           cfg.BatchSize,
-		lastProcessedHeight: lastProcessedHeight,
-		logger:              logger,
-		contractConfig:      contractConfig,
-	}, nil
+	fg := &FinalityGadget{
+		btcClient:          btcClient,
+		bbnClient:          bbnClient,
+		cwClient:           cwClient,
+		l2Client:           l2Client,
+		db:                 db,
+		pollInterval:       cfg.PollInterval,
+		batchSize:          cfg.BatchSize,
+		concurrency:        concurrency,
+		verifyEotsSigs:     cfg.VerifyEotsSigs,
+		quorum:             quorum,
+		fpPowerCache:       fpPowerCache,
+		pubRandCommitCache: pubRandCommitCache,
+		logger:             logger,
+		contractConfig:     contractConfig,
+		retentionBlocks:    cfg.RetentionBlocks,
+		retentionPeriod:    time.Duration(cfg.RetentionDays) * 24 * time.Hour,
+		pruneInterval:      pruneInterval,
+		startTime:          time.Now(),
+		readinessMaxLag:    readinessMaxLag,
+		stallMaxDuration:   cfg.StallMaxDuration,
+		stallMaxLag:        cfg.StallMaxLag,
+		alertNotifier:      newWebhookNotifier(cfg.AlertWebhooks, logger),
+	}
+	fg.lastProcessedHeight.Store(lastProcessedHeight)
+	return fg, nil
 }
 
 //////////////////////////////
@@ -133,13 +218,17 @@ func NewFinalityGadget(cfg *config.Config, db db.IDatabaseHandler, logger *zap.L
  *
  * - to check if the block is finalized, we need to:
  *   - get the consumer chain id
- *   - get all the FPs pubkey for the consumer chain
+ *   - convert the L2 block timestamp to Babylon height
+ *   - get all the FPs pubkey for the consumer chain at this Babylon height
+ *   - keep only the FPs in the contract allow-list at this Babylon height
  *   - convert the L2 block timestamp to BTC height
  *   - get all FPs voting power at this BTC height
+ *   - zero the voting power of FPs without timestamped public randomness for this height
  *   - calculate total voting power
  *   - get all FPs that voted this L2 block with the same height and hash
+ *   - if enabled, drop votes whose EOTS signature fails local verification
  *   - calculate voted voting power
- *   - check if the voted voting power is more than 2/3 of the total voting power
+ *   - check if the voted voting power reaches the quorum (2/3 of the total voting power by default)
  *
  * TODO: This query function should not track metrics. Callers should handle metrics tracking
  * by calling trackFinalityProviderMetrics() when appropriate for their use case.
@@ -152,55 +241,45 @@ func (fg *FinalityGadget) QueryIsBlockBabylonFinalizedFromBabylon(block *types.B
 	// trim prefix 0x for the L2 block hash
 	block.BlockHash = strings.TrimPrefix(block.BlockHash, "0x")
 
-	// get all FPs pubkey for the consumer chain
-	allFpPks, err := fg.queryAllFpBtcPubKeys()
-	if err != nil {
-		return false, err
-	}
-
-	// convert the L2 timestamp to BTC height
-	btcblockHeight, err := fg.btcClient.GetBlockHeightByTimestamp(block.BlockTimestamp)
-	if err != nil {
-		return false, err
+	tally, err := fg.tallyVotes(block)
+	if tally != nil {
+		// record the Babylon height used to resolve the FP set, so it's stored alongside the block
+		block.BabylonHeight = tally.babylonHeight
 	}
-
-	// get all FPs voting power at this BTC height
-	allFpPower, err := fg.bbnClient.QueryMultiFpPower(allFpPks, btcblockHeight)
 	if err != nil {
 		return false, err
 	}
-
-	// calculate total voting power
-	var totalPower uint64 = 0
-	for _, power := range allFpPower {
-		totalPower += power
+	if tally.votes == nil {
+		return false, nil
 	}
-
-	// no FP has voting power for the consumer chain
-	if totalPower == 0 {
-		return false, types.ErrNoFpHasVotingPower
+	votedFpPks := make([]string, len(tally.votes))
+	for i, vote := range tally.votes {
+		votedFpPks[i] = vote.FpBtcPkHex
 	}
 
-	// get all FPs that voted this (L2 block height, L2 block hash) combination
-	votedFpPks, err := fg.cwClient.QueryListOfVotedFinalityProviders(block)
-	if err != nil {
-		return false, err
-	}
-	if votedFpPks == nil {
-		return false, nil
-	}
-	// calculate voted voting power
-	var votedPower uint64 = 0
+	// record the inputs of the quorum check, so they're stored alongside the block
+	voters := make([]*types.VoterPower, 0, len(votedFpPks))
 	for _, key := range votedFpPks {
-		if power, exists := allFpPower[key]; exists {
-			votedPower += power
+		if power, exists := tally.fpPower[key]; exists {
+			voters = append(voters, &types.VoterPower{FpBtcPkHex: key, Power: power})
 		}
 	}
+	block.Evidence = &types.FinalityEvidence{
+		BlockHeight:   block.BlockHeight,
+		BlockHash:     block.BlockHash,
+		BabylonHeight: tally.babylonHeight,
+		BtcHeight:     tally.btcHeight,
+		TotalPower:    tally.totalPower,
+		VotedPower:    tally.votedPower,
+		Voters:        voters,
+		QuorumRatio:   tally.quorum.Ratio(),
+		QuorumPower:   tally.quorum.Threshold(tally.totalPower),
+	}
 
 	// Track latest voting power per FP (bounded metrics - only latest values)
 	// Clear old metrics first to prevent memory leaks when FPs are removed
 	metrics.FpLatestVotingPower.Reset()
-	for fpPubkey, power := range allFpPower {
+	for fpPubkey, power := range tally.fpPower {
 		metrics.FpLatestVotingPower.WithLabelValues(fpPubkey).Set(float64(power))
 	}
 
@@ -209,11 +288,6 @@ func (fg *FinalityGadget) QueryIsBlockBabylonFinalizedFromBabylon(block *types.B
 		metrics.FpLatestBlockVoted.WithLabelValues(votedFpPk).Set(float64(block.BlockHeight))
 	}
 
-	// Track FP voting behavior in metrics
-	for _, votedFpPk := range votedFpPks {
-		metrics.FpLatestBlockVoted.WithLabelValues(votedFpPk).Set(float64(block.BlockHeight))
-	}
-
 	// Track missed blocks for FPs that didn't vote
 	votedFpSet := make(map[string]bool)
 	for _, fpPk := range votedFpPks {
@@ -221,18 +295,14 @@ func (fg *FinalityGadget) QueryIsBlockBabylonFinalizedFromBabylon(block *types.B
 	}
 
 	// For each FP with voting power, check if they voted
-	for fpPubkey := range allFpPower {
+	for fpPubkey := range tally.fpPower {
 		if !votedFpSet[fpPubkey] {
 			// This FP missed this block
 			metrics.FpMissedBlocks.WithLabelValues(fpPubkey).Inc()
 		}
 	}
 
-	// quorom < 2/3
-	if votedPower*3 < totalPower*2 {
-		return false, nil
-	}
-	return true, nil
+	return tally.hasQuorum(), nil
 }
 
 // QueryIsBlockBabylonFinalized queries the finality status of a given block height from the internal db
@@ -433,6 +503,186 @@ func (fg *FinalityGadget) QueryChainSyncStatus() (*types.ChainSyncStatus, error)
 	}, nil
 }
 
+func (fg *FinalityGadget) GetFinalityEvidenceByHeight(height uint64) (*types.FinalityEvidence, error) {
+	return fg.db.GetEvidenceByHeight(height)
+}
+
+func (fg *FinalityGadget) GetFinalityEvidenceByHash(hash string) (*types.FinalityEvidence, error) {
+	return fg.db.GetEvidenceByHash(normalizeBlockHash(hash))
+}
+
+/* QueryFinalityProof builds a self-contained proof bundle that the finalized block at the given height is BTC-finalized
+ *
+ * - the block and its finality evidence must be stored in the local db. The voting power of the FPs that voted and
+ *   the total power are taken from the evidence, ie. as resolved when the block was finalized, at the Babylon and
+ *   BTC heights it records
+ * - the votes, which the contract never changes once submitted, are queried at the latest Babylon height, which
+ *   the bundle records. Only the votes of FPs with voting power in the evidence are included
+ * - the signatures are verified if EOTS verification is enabled, invalid votes being left out. Otherwise the
+ *   bundle carries the votes as listed by the contract and is marked as such
+ */
+func (fg *FinalityGadget) QueryFinalityProof(height uint64) (*types.FinalityProof, error) {
+	storedBlock, err := fg.db.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	evidence, err := fg.db.GetEvidenceByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	block := *storedBlock
+	block.BlockHash = strings.TrimPrefix(block.BlockHash, "0x")
+
+	consumerId, err := fg.cwClient.QueryConsumerId()
+	if err != nil {
+		return nil, err
+	}
+
+	// pin the votes query, so the bundle tells where to find them
+	votesBabylonHeight, err := fg.bbnClient.QueryLatestHeight()
+	if err != nil {
+		return nil, err
+	}
+	votes, err := fg.cwClient.QueryBlockVotersAtHeight(&block, votesBabylonHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	// sort the FP set so the same block always yields the same bundle
+	fpPower := make(map[string]uint64, len(evidence.Voters))
+	fps := make([]*types.VoterPower, 0, len(evidence.Voters))
+	for _, voter := range evidence.Voters {
+		if voter.Power > 0 {
+			fpPower[voter.FpBtcPkHex] = voter.Power
+			fps = append(fps, &types.VoterPower{FpBtcPkHex: voter.FpBtcPkHex, Power: voter.Power})
+		}
+	}
+	sort.Slice(fps, func(i, j int) bool { return fps[i].FpBtcPkHex < fps[j].FpBtcPkHex })
+
+	var msg []byte
+	if fg.verifyEotsSigs {
+		msg, err = voteMsg(block.BlockHeight, block.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	proofVotes := make([]*types.BlockVoter, 0, len(votes))
+	voted := make(map[string]bool, len(votes))
+	var votedPower uint64
+	for _, vote := range votes {
+		power, exists := fpPower[vote.FpBtcPkHex]
+		if !exists || voted[vote.FpBtcPkHex] {
+			continue
+		}
+		if fg.verifyEotsSigs {
+			if err := verifyEotsSig(vote, msg); err != nil {
+				fg.logger.Warn("Leaving a vote with an invalid signature out of the proof bundle",
+					zap.String("fp_pubkey", vote.FpBtcPkHex),
+					zap.Uint64("block_height", block.BlockHeight),
+					zap.Error(err))
+				continue
+			}
+		}
+		voted[vote.FpBtcPkHex] = true
+		votedPower += power
+		proofVotes = append(proofVotes, vote)
+	}
+	sort.Slice(proofVotes, func(i, j int) bool { return proofVotes[i].FpBtcPkHex < proofVotes[j].FpBtcPkHex })
+
+	tally := &voteTally{quorum: fg.quorumRule(), totalPower: evidence.TotalPower, votedPower: votedPower}
+	if !tally.hasQuorum() {
+		return nil, fmt.Errorf("%w: block %d, the votes found don't reach the quorum", types.ErrBlockNotFinalized, height)
+	}
+
+	return &types.FinalityProof{
+		Version:            types.FinalityProofVersion,
+		ConsumerId:         consumerId,
+		BlockHeight:        block.BlockHeight,
+		BlockHash:          normalizeBlockHash(block.BlockHash),
+		BlockTimestamp:     block.BlockTimestamp,
+		BabylonHeight:      evidence.BabylonHeight,
+		BtcHeight:          evidence.BtcHeight,
+		VotesBabylonHeight: votesBabylonHeight,
+		TotalPower:         evidence.TotalPower,
+		FinalityProviders:  fps,
+		Votes:              proofVotes,
+		SignaturesVerified: fg.verifyEotsSigs,
+	}, nil
+}
+
+/* VerifyFinalityProof re-checks a proof bundle offline
+ *
+ * - the voting power of the FPs and the total power are taken as given by the bundle, they can be cross-checked
+ *   against Babylon at the bundle's Babylon and BTC heights
+ * - each vote must be by an FP in the bundle, at most once, with a valid EOTS signature over (height || block hash),
+ *   whether or not the finality gadget verified it
+ * - the voted power must reach the given quorum of the total power
+ */
+func VerifyFinalityProof(proof *types.FinalityProof, quorum types.QuorumRule) error {
+	if proof == nil {
+		return fmt.Errorf("%w: proof is nil", types.ErrInvalidFinalityProof)
+	}
+	if err := quorum.Validate(); err != nil {
+		return err
+	}
+	if proof.Version != types.FinalityProofVersion {
+		return fmt.Errorf("%w: unsupported version %d, expected %d",
+			types.ErrInvalidFinalityProof, proof.Version, types.FinalityProofVersion)
+	}
+
+	msg, err := voteMsg(proof.BlockHeight, proof.BlockHash)
+	if err != nil {
+		return fmt.Errorf("%w: %v", types.ErrInvalidFinalityProof, err)
+	}
+
+	// the FPs in the bundle can't have more than the total voting power
+	fpPower := make(map[string]uint64, len(proof.FinalityProviders))
+	var fpsPower uint64
+	for _, fp := range proof.FinalityProviders {
+		if _, exists := fpPower[fp.FpBtcPkHex]; exists {
+			return fmt.Errorf("%w: duplicate finality provider %s", types.ErrInvalidFinalityProof, fp.FpBtcPkHex)
+		}
+		if fpsPower+fp.Power < fpsPower {
+			return fmt.Errorf("%w: finality providers power overflows", types.ErrInvalidFinalityProof)
+		}
+		fpPower[fp.FpBtcPkHex] = fp.Power
+		fpsPower += fp.Power
+	}
+	totalPower := proof.TotalPower
+	if totalPower == 0 {
+		return fmt.Errorf("%w: %v", types.ErrInvalidFinalityProof, types.ErrNoFpHasVotingPower)
+	}
+	if fpsPower > totalPower {
+		return fmt.Errorf("%w: finality providers power %d exceeds the total power %d",
+			types.ErrInvalidFinalityProof, fpsPower, totalPower)
+	}
+
+	// calculate voted voting power from the votes with a valid signature
+	voted := make(map[string]bool, len(proof.Votes))
+	var votedPower uint64
+	for _, vote := range proof.Votes {
+		power, exists := fpPower[vote.FpBtcPkHex]
+		if !exists {
+			return fmt.Errorf("%w: vote by unknown finality provider %s", types.ErrInvalidFinalityProof, vote.FpBtcPkHex)
+		}
+		if voted[vote.FpBtcPkHex] {
+			return fmt.Errorf("%w: duplicate vote by finality provider %s", types.ErrInvalidFinalityProof, vote.FpBtcPkHex)
+		}
+		if err := verifyEotsSig(vote, msg); err != nil {
+			return fmt.Errorf("%w: vote by finality provider %s: %v", types.ErrInvalidFinalityProof, vote.FpBtcPkHex, err)
+		}
+		voted[vote.FpBtcPkHex] = true
+		votedPower += power
+	}
+
+	tally := &voteTally{quorum: quorum, totalPower: totalPower, votedPower: votedPower}
+	if !tally.hasQuorum() {
+		return fmt.Errorf("%w: voted power %d of total power %d doesn't reach the quorum %s",
+			types.ErrInvalidFinalityProof, votedPower, totalPower, quorum)
+	}
+	return nil
+}
+
 func (fg *FinalityGadget) QueryIsBlockFinalizedByHeight(height uint64) (bool, error) {
 	return fg.db.QueryIsBlockFinalizedByHeight(height)
 }
@@ -453,6 +703,9 @@ func (fg *FinalityGadget) QueryLatestFinalizedBlock() (*types.Block, error) {
 //  3. Integrate FG with it disabled on CW contract
 //  3. Restart OP chain after setting `babylonFinalityGadgetRpc`
 //  4. Enable FG on CW contract (for network with multiple nodes, enable after majority of nodes upgrade)
+//
+// Transient RPC errors are retried with backoff like in the block processing, only permanent RPC errors and DB
+// errors are returned.
 func (fg *FinalityGadget) Startup(ctx context.Context) error {
 	fg.logger.Info("Starting up finality gadget...")
 	// Start polling for new blocks at set interval
@@ -466,21 +719,52 @@ func (fg *FinalityGadget) Startup(ctx context.Context) error {
 		case <-ticker.C:
 			// query rpc for latest eth finalized block
 			// at this point, FG is disabled so the derivation pipeline passes through
-			latestFinalizedBlock, err := fg.l2Client.HeaderByNumber(ctx, big.NewInt(ethrpc.FinalizedBlockNumber.Int64()))
+			var latestFinalizedHeight, latestFinalizedBlockTime uint64
+			err := fg.retryStage(ctx, nil, stageStartup, 0, func() error {
+				latestFinalizedBlock, err := fg.l2Client.HeaderByNumber(ctx, big.NewInt(ethrpc.FinalizedBlockNumber.Int64()))
+				if err != nil {
+					return fmt.Errorf("error fetching latest finalized L2 block: %w", err)
+				}
+				latestFinalizedHeight = latestFinalizedBlock.Number.Uint64()
+				latestFinalizedBlockTime = latestFinalizedBlock.Time
+				return nil
+			})
 			if err != nil {
-				return fmt.Errorf("error fetching latest finalized L2 block: %w", err)
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
 			}
-			latestFinalizedHeight := latestFinalizedBlock.Number.Uint64()
-			latestFinalizedBlockTime := latestFinalizedBlock.Time
 
-			// get the BTC staking activation timestamp
-			btcStakingActivatedTimestamp, err := fg.QueryBtcStakingActivatedTimestamp()
+			// get the BTC staking activation timestamp, from the db if it's already known
+			btcStakingActivatedTimestamp, err := fg.db.GetActivatedTimestamp()
+			if err != nil && !errors.Is(err, types.ErrActivatedTimestampNotFound) {
+				return fmt.Errorf("error fetching BTC staking activation timestamp from db: %w", err)
+			}
 			if err != nil {
-				if errors.Is(err, types.ErrBtcStakingNotActivated) {
+				activated := true
+				err = fg.retryStage(ctx, nil, stageStartup, latestFinalizedHeight, func() error {
+					var err error
+					btcStakingActivatedTimestamp, err = fg.queryBtcStakingActivationTimestamp()
+					if errors.Is(err, types.ErrBtcStakingNotActivated) {
+						activated = false
+						return nil
+					}
+					if err != nil {
+						return fmt.Errorf("error querying BTC staking activation timestamp: %w", err)
+					}
+					return nil
+				})
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return err
+				}
+				if !activated {
 					fg.logger.Info("BTC staking not yet activated, waiting...")
 					continue
 				}
-				return fmt.Errorf("error querying BTC staking activation timestamp: %w", err)
 			}
 
 			// throw error if btc staking activated before the first block was finalized (see startup order above)
@@ -499,11 +783,19 @@ func (fg *FinalityGadget) Startup(ctx context.Context) error {
 			if err != nil {
 				return fmt.Errorf("error fetching latest finalized block from db: %w", err)
 			}
-			fg.lastProcessedHeight = latestFinalizedHeight - 1
+			checkpoint, err := fg.db.GetProcessingCheckpoint()
+			if err != nil {
+				return fmt.Errorf("error fetching processing checkpoint from db: %w", err)
+			}
+			lastProcessedHeight := latestFinalizedHeight - 1
 			if latestFinalizedBlockDb != nil && latestFinalizedBlockDb.BlockHeight > latestFinalizedHeight {
-				fg.lastProcessedHeight = latestFinalizedBlockDb.BlockHeight
+				lastProcessedHeight = latestFinalizedBlockDb.BlockHeight
 			}
-			fg.logger.Info("Starting finality gadget from block", zap.Uint64("block_height", fg.lastProcessedHeight+1))
+			if checkpoint != nil && checkpoint.Height > lastProcessedHeight {
+				lastProcessedHeight = checkpoint.Height
+			}
+			fg.lastProcessedHeight.Store(lastProcessedHeight)
+			fg.logger.Info("Starting finality gadget from block", zap.Uint64("block_height", lastProcessedHeight+1))
 
 			return nil
 		}
@@ -513,6 +805,9 @@ func (fg *FinalityGadget) Startup(ctx context.Context) error {
 // This function process blocks indefinitely, starting from the last finalized block.
 func (fg *FinalityGadget) ProcessBlocks(ctx context.Context) error {
 	fg.logger.Info("Processing blocks...")
+	fg.processing.Store(true)
+	defer fg.processing.Store(false)
+	fg.beat()
 	// Start polling for new blocks at set interval
 	ticker := time.NewTicker(fg.pollInterval)
 	defer ticker.Stop()
@@ -523,26 +818,36 @@ func (fg *FinalityGadget) ProcessBlocks(ctx context.Context) error {
 			fg.logger.Debug("Exiting block processing loop...")
 			return nil
 		case <-ticker.C:
+			fg.beat()
 			fg.logger.Debug("Processing new blocks...")
 			// get latest block
 			latestBlock, err := fg.l2Client.HeaderByNumber(ctx, big.NewInt(ethrpc.LatestBlockNumber.Int64()))
 			if err != nil {
-				return fmt.Errorf("error fetching latest L2 block: %w", err)
+				if types.IsPermanentError(err) {
+					return fmt.Errorf("error fetching latest L2 block: %w", err)
+				}
+				fg.markDegraded(componentPoll, err)
+				fg.logger.Warn("Failed to fetch latest L2 block, retrying at the next poll", zap.Error(err))
+				continue
 			}
+			fg.clearDegraded(componentPoll)
 			fg.logger.Debug("Received latest block", zap.Uint64("block_height", latestBlock.Number.Uint64()))
+			fg.l2HeadHeight.Store(latestBlock.Number.Uint64())
 
 			// if the last processed block is less than the latest block, process all intervening blocks
-			if fg.lastProcessedHeight < latestBlock.Number.Uint64() {
-				fg.logger.Info("Processing new blocks", zap.Uint64("start_height", fg.lastProcessedHeight+1), zap.Uint64("end_height", latestBlock.Number.Uint64()))
+			if lastProcessedHeight := fg.lastProcessedHeight.Load(); lastProcessedHeight < latestBlock.Number.Uint64() {
+				fg.logger.Info("Processing new blocks", zap.Uint64("start_height", lastProcessedHeight+1), zap.Uint64("end_height", latestBlock.Number.Uint64()))
 				if err := fg.processBlocksTillHeight(ctx, latestBlock.Number.Uint64()); err != nil {
 					return fmt.Errorf("error processing block %d: %w", latestBlock.Number.Uint64(), err)
 				}
 			}
+			fg.lastPollTime.Store(time.Now().UnixNano())
 		}
 	}
 }
 
-func (fg *FinalityGadget) insertBlocks(blocks []*types.Block) error {
+// insertBlocks stores the finalized blocks together with the processing checkpoint, if any
+func (fg *FinalityGadget) insertBlocks(blocks []*types.Block, checkpoint *types.ProcessingCheckpoint) error {
 	// Lock mutex
 	fg.mutex.Lock()
 	defer fg.mutex.Unlock()
@@ -554,17 +859,28 @@ func (fg *FinalityGadget) insertBlocks(blocks []*types.Block) error {
 			BlockHeight:    block.BlockHeight,
 			BlockHash:      normalizeBlockHash(block.BlockHash),
 			BlockTimestamp: block.BlockTimestamp,
+			BabylonHeight:  block.BabylonHeight,
+			Evidence:       block.Evidence,
 		}
 	}
 
-	// Store blocks in DB
-	if err := fg.db.InsertBlocks(normalizedBlocks); err != nil {
+	// Store blocks in DB, atomically with the checkpoint so a restart never resumes above an unstored block
+	var err error
+	if checkpoint != nil {
+		err = fg.db.InsertBlocksWithCheckpoint(normalizedBlocks, checkpoint)
+	} else {
+		err = fg.db.InsertBlocks(normalizedBlocks)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to batch insert blocks: %w", err)
 	}
 
 	// Increment metrics for finalized blocks
 	metrics.FinalizedBlocksTotal.Add(float64(len(normalizedBlocks)))
 
+	// Push the new blocks to subscribers
+	fg.notifySubscribers()
+
 	return nil
 }
 
@@ -581,8 +897,56 @@ func (fg *FinalityGadget) Close() {
 // INTERNAL
 //////////////////////////////
 
+/* resolveQuorumRule returns the quorum required for a block to be finalized
+ *
+ * - the quorum set in the config takes precedence, so testnets can run with other safety assumptions
+ * - else the quorum exposed by the contract config, if any
+ * - else the default 2/3
+ * - the strict-greater semantics always come from the config
+ */
+func resolveQuorumRule(cfg *config.Config, contractConfig *types.ContractConfig, logger *zap.Logger) (types.QuorumRule, error) {
+	contractQuorum := types.QuorumRule{
+		Numerator:   contractConfig.QuorumNumerator,
+		Denominator: contractConfig.QuorumDenominator,
+		Strict:      cfg.QuorumStrict,
+	}
+	hasContractQuorum := contractQuorum.Numerator != 0 || contractQuorum.Denominator != 0
+
+	var quorum types.QuorumRule
+	var source string
+	switch {
+	case cfg.HasQuorum():
+		quorum, source = cfg.QuorumRule(), "config"
+		if hasContractQuorum && (quorum.Numerator != contractQuorum.Numerator || quorum.Denominator != contractQuorum.Denominator) {
+			logger.Warn("Configured quorum overrides the contract quorum",
+				zap.Stringer("quorum", quorum),
+				zap.Stringer("contract_quorum", contractQuorum))
+		}
+	case hasContractQuorum:
+		quorum, source = contractQuorum, "contract"
+	default:
+		quorum, source = types.DefaultQuorumRule, "default"
+		quorum.Strict = cfg.QuorumStrict
+	}
+	if err := quorum.Validate(); err != nil {
+		return types.QuorumRule{}, fmt.Errorf("invalid %s quorum: %w", source, err)
+	}
+
+	logger.Info("Using quorum", zap.Stringer("quorum", quorum), zap.String("source", source))
+	return quorum, nil
+}
+
+// quorumRule returns the quorum required for a block to be finalized, falling back to the default if unset
+func (fg *FinalityGadget) quorumRule() types.QuorumRule {
+	if fg.quorum.Denominator == 0 {
+		return types.DefaultQuorumRule
+	}
+	return fg.quorum
+}
+
 // determineStartingHeight calculates the appropriate starting block height based on
-// database state and configuration. Returns the last processed height.
+// database state (the latest finalized block and the processing checkpoint) and
+// configuration. Returns the last processed height.
 func determineStartingHeight(
 	cfg *config.Config,
 	db db.IDatabaseHandler,
@@ -596,6 +960,19 @@ func determineStartingHeight(
 		logger.Info("Found last finalized block in database", zap.Uint64("db_height", dbHeight))
 	}
 
+	// The processing checkpoint also covers the heights evaluated above the last finalized block
+	checkpoint, err := db.GetProcessingCheckpoint()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get processing checkpoint: %w", err)
+	}
+	if checkpoint != nil {
+		logger.Info("Found processing checkpoint in database",
+			zap.Uint64("checkpoint_height", checkpoint.Height),
+			zap.Stringer("checkpoint_outcome", checkpoint.Outcome),
+			zap.Uint64("checkpoint_timestamp", checkpoint.Timestamp))
+		dbHeight = max(dbHeight, checkpoint.Height)
+	}
+
 	// Case 1: No StartBlockHeight configured
 	if cfg.StartBlockHeight == 0 {
 		if dbHeight == 0 && contractConfig.BsnActivationHeight > 0 {
@@ -628,7 +1005,116 @@ func determineStartingHeight(
 	return dbHeight, nil
 }
 
-func (fg *FinalityGadget) queryAllFpBtcPubKeys() ([]string, error) {
+// voteTally holds the inputs and the outcome of the quorum check for an L2 block
+type voteTally struct {
+	babylonHeight uint64
+	btcHeight     uint32
+	fpPower       map[string]uint64
+	// votes are the counted votes for the block, nil if the contract has none
+	votes      []*types.BlockVoter
+	quorum     types.QuorumRule
+	totalPower uint64
+	votedPower uint64
+}
+
+// hasQuorum returns true if the voted power reaches the quorum of the total power
+func (t *voteTally) hasQuorum() bool {
+	return t.quorum.Reached(t.votedPower, t.totalPower)
+}
+
+/* tallyVotes resolves the FP set and their voting power for the given L2 block and sums up the power of its votes
+ *
+ * - the block hash is expected without the 0x prefix
+ * - returns ErrNoFpHasVotingPower if the FP set has no voting power at the block
+ * - returns a tally without votes if the FPs with voting power have no timestamped public randomness for the
+ *   block yet, as the block can't be finalized before
+ * - it has no side effects, so it's shared by the finality check and the proof bundle
+ */
+func (fg *FinalityGadget) tallyVotes(block *types.Block) (*voteTally, error) {
+	// convert the L2 timestamp to Babylon height
+	babylonHeight, err := fg.bbnClient.QueryBabylonHeightByTimestamp(block.BlockTimestamp)
+	if err != nil {
+		return nil, err
+	}
+	tally := &voteTally{babylonHeight: babylonHeight, quorum: fg.quorumRule()}
+
+	// get all FPs pubkey for the consumer chain at this Babylon height
+	allFpPks, err := fg.queryAllFpBtcPubKeys(babylonHeight)
+	if err != nil {
+		return tally, err
+	}
+
+	// only FPs in the contract allow-list can contribute to total and voted power
+	allFpPks, err = fg.filterAllowedFpPks(allFpPks, babylonHeight)
+	if err != nil {
+		return tally, err
+	}
+	// an empty or unset allow-list allows no FP
+	if len(allFpPks) == 0 {
+		return tally, types.ErrNoFpHasVotingPower
+	}
+
+	// convert the L2 timestamp to BTC height
+	tally.btcHeight, err = fg.getBtcHeightByTimestamp(block.BlockTimestamp)
+	if err != nil {
+		return tally, err
+	}
+
+	// get all FPs voting power at this BTC height
+	tally.fpPower, err = fg.queryMultiFpPower(allFpPks, tally.btcHeight)
+	if err != nil {
+		return tally, err
+	}
+
+	// calculate total voting power
+	for _, power := range tally.fpPower {
+		tally.totalPower += power
+	}
+
+	// no FP has voting power for the consumer chain
+	if tally.totalPower == 0 {
+		return tally, types.ErrNoFpHasVotingPower
+	}
+
+	// FPs without timestamped public randomness for this height have no voting power
+	if err := fg.applyPubRandCommitCheck(tally.fpPower, block.BlockHeight); err != nil {
+		return tally, err
+	}
+	tally.totalPower = 0
+	for _, power := range tally.fpPower {
+		tally.totalPower += power
+	}
+
+	// no FP can vote on the block until their commitments are timestamped
+	if tally.totalPower == 0 {
+		fg.logger.Debug("No FP has timestamped public randomness for block yet",
+			zap.Uint64("block_height", block.BlockHeight))
+		return tally, nil
+	}
+
+	// get all FPs that voted this (L2 block height, L2 block hash) combination
+	votes, err := fg.cwClient.QueryBlockVoters(block)
+	if err != nil {
+		return tally, err
+	}
+	if votes == nil {
+		return tally, nil
+	}
+	tally.votes, err = fg.validVotes(votes, block)
+	if err != nil {
+		return tally, err
+	}
+
+	// calculate voted voting power
+	for _, vote := range tally.votes {
+		tally.votedPower += tally.fpPower[vote.FpBtcPkHex]
+	}
+	return tally, nil
+}
+
+// queryAllFpBtcPubKeys returns all FPs pubkey for the consumer chain as of the given Babylon
+// height. A height of 0 queries the latest FP set.
+func (fg *FinalityGadget) queryAllFpBtcPubKeys(babylonHeight uint64) ([]string, error) {
 	// get the consumer chain id
 	consumerId, err := fg.cwClient.QueryConsumerId()
 	if err != nil {
@@ -636,13 +1122,134 @@ func (fg *FinalityGadget) queryAllFpBtcPubKeys() ([]string, error) {
 	}
 
 	// get all the FPs pubkey for the consumer chain
-	allFpPks, err := fg.bbnClient.QueryAllFpBtcPubKeys(consumerId)
+	allFpPks, err := fg.bbnClient.QueryAllFpBtcPubKeys(consumerId, babylonHeight)
 	if err != nil {
 		return nil, err
 	}
 	return allFpPks, nil
 }
 
+// filterAllowedFpPks returns the intersection of the given FP pubkeys and the contract allow-list
+// at the given Babylon height, preserving the order of the given FP pubkeys. An empty or unset
+// allow-list allows no FP, as the contract only accepts votes from allow-listed FPs.
+func (fg *FinalityGadget) filterAllowedFpPks(fpPks []string, babylonHeight uint64) ([]string, error) {
+	allowedFpPks, err := fg.cwClient.QueryAllowedFinalityProviders(babylonHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedFpSet := make(map[string]bool, len(allowedFpPks))
+	for _, fpPk := range allowedFpPks {
+		allowedFpSet[strings.ToLower(fpPk)] = true
+	}
+
+	var filteredFpPks []string
+	for _, fpPk := range fpPks {
+		if allowedFpSet[strings.ToLower(fpPk)] {
+			filteredFpPks = append(filteredFpPks, fpPk)
+		}
+	}
+	return filteredFpPks, nil
+}
+
+/* applyPubRandCommitCheck zeroes the voting power of FPs that cannot have a valid vote for the given height
+ *
+ * - an FP can only vote on an L2 height if it committed public randomness covering that height
+ * - the commitment must be timestamped, i.e. the checkpoint of the Babylon epoch it was submitted in must be
+ *   finalized on BTC. A block evaluated before that is evaluated again at the next poll
+ * - FPs failing either condition get zero voting power for this block
+ */
+func (fg *FinalityGadget) applyPubRandCommitCheck(fpPower map[string]uint64, height uint64) error {
+	for fpPk, power := range fpPower {
+		if power == 0 {
+			continue
+		}
+
+		commit, err := fg.queryPubRandCommitForHeight(fpPk, height)
+		if err != nil {
+			return err
+		}
+		timestamped := false
+		if commit != nil {
+			timestamped, err = fg.isPubRandCommitTimestamped(commit)
+			if err != nil {
+				return err
+			}
+		}
+		if !timestamped {
+			fg.logger.Debug("FP has no timestamped public randomness for block, ignoring its voting power",
+				zap.String("fp_pubkey", fpPk),
+				zap.Uint64("block_height", height),
+				zap.Bool("has_commit", commit != nil))
+			fpPower[fpPk] = 0
+		}
+	}
+	return nil
+}
+
+/* validVotes returns the votes to be counted for the given block
+ *
+ * - if EOTS verification is disabled, the contract is trusted and all votes are counted
+ * - otherwise each vote is verified as an EOTS signature over (height || block hash)
+ *   using the FP's public key and the public randomness it submitted with the vote
+ * - invalid votes are excluded, logged and counted per FP
+ */
+func (fg *FinalityGadget) validVotes(votes []*types.BlockVoter, block *types.Block) ([]*types.BlockVoter, error) {
+	if !fg.verifyEotsSigs {
+		return votes, nil
+	}
+
+	msg, err := voteMsg(block.BlockHeight, block.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	validVotes := make([]*types.BlockVoter, 0, len(votes))
+	for _, vote := range votes {
+		if err := verifyEotsSig(vote, msg); err != nil {
+			fg.logger.Warn("Ignoring vote with invalid EOTS signature",
+				zap.String("fp_pubkey", vote.FpBtcPkHex),
+				zap.Uint64("block_height", block.BlockHeight),
+				zap.String("block_hash", block.BlockHash),
+				zap.Error(err))
+			metrics.FpInvalidVotesTotal.WithLabelValues(vote.FpBtcPkHex).Inc()
+			continue
+		}
+		validVotes = append(validVotes, vote)
+	}
+	return validVotes, nil
+}
+
+// voteMsg returns the message signed by FPs when voting for a block, i.e. (height || block hash)
+func voteMsg(height uint64, blockHash string) ([]byte, error) {
+	hashBytes, err := hex.DecodeString(strings.TrimPrefix(blockHash, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid block hash %s: %w", blockHash, err)
+	}
+	return append(binary.BigEndian.AppendUint64(nil, height), hashBytes...), nil
+}
+
+// verifyEotsSig checks the vote's finality signature over msg against the FP's public key and public randomness
+func verifyEotsSig(vote *types.BlockVoter, msg []byte) error {
+	fpPk, err := bbntypes.NewBIP340PubKeyFromHex(vote.FpBtcPkHex)
+	if err != nil {
+		return fmt.Errorf("invalid FP public key: %w", err)
+	}
+	btcPk, err := fpPk.ToBTCPK()
+	if err != nil {
+		return fmt.Errorf("invalid FP public key: %w", err)
+	}
+	pubRand, err := bbntypes.NewSchnorrPubRand(vote.PubRand)
+	if err != nil {
+		return fmt.Errorf("invalid public randomness: %w", err)
+	}
+	sig, err := bbntypes.NewSchnorrEOTSSig(vote.FinalitySignature)
+	if err != nil {
+		return fmt.Errorf("invalid finality signature: %w", err)
+	}
+	return eots.Verify(btcPk, pubRand.ToFieldValNormalized(), msg, sig.ToModNScalar())
+}
+
 // Get block by number
 func (fg *FinalityGadget) queryBlockByHeight(blockNumber int64) (*types.Block, error) {
 	header, err := fg.l2Client.HeaderByNumber(context.Background(), big.NewInt(blockNumber))
@@ -656,182 +1263,159 @@ func (fg *FinalityGadget) queryBlockByHeight(blockNumber int64) (*types.Block, e
 	}, nil
 }
 
-// Process blocks in batches of size `fg.batchSize` until the latest height
-func (fg *FinalityGadget) processBlocksTillHeight(ctx context.Context, latestHeight uint64) error {
-	fg.logger.Debug("Processing blocks till height", zap.Uint64("height", latestHeight))
-	for batchStartHeight := fg.lastProcessedHeight + 1; batchStartHeight <= latestHeight; {
-		select {
-		case <-ctx.Done():
-			fg.logger.Debug("Exiting block processing loop...")
-			return nil
-		default:
-			// Calculate batch start and end heights
-			batchEndHeight := batchStartHeight + fg.batchSize - 1
-			if batchEndHeight > latestHeight {
-				batchEndHeight = latestHeight
-			}
-			fg.logger.Info("Processing batch of blocks", zap.Uint64("batch_start_height", batchStartHeight), zap.Uint64("batch_end_height", batchEndHeight))
-
-			// Create batch of blocks to check in parallel
-			results := make(chan *types.Block, batchEndHeight-batchStartHeight+1)
-			errors := make(chan error, batchEndHeight-batchStartHeight+1)
-			var wg sync.WaitGroup
-
-			// Collect heights that should be processed based on finality signature interval
-			var heightsToProcess []uint64
-			for height := batchStartHeight; height <= batchEndHeight; height++ {
-				if fg.shouldProcessHeight(height) {
-					heightsToProcess = append(heightsToProcess, height)
-				}
-			}
+/* checkAndHandleReorg detects L2 reorgs beneath the latest finalized block stored in the db
+ *
+ * - fetch the L2 block right above the latest stored block and compare its parent hash with
+ *   the stored hash
+ * - if they match, the stored chain is still canonical and nothing needs to be done
+ * - else, walk back over the stored blocks (at finality signature intervals) until one whose
+ *   hash still matches the canonical L2 chain is found, i.e. the fork point
+ * - roll back all stored blocks above the fork point and re-process from there
+ *
+ * returns true if a reorg was detected and the db was rolled back
+ */
+func (fg *FinalityGadget) checkAndHandleReorg(ctx context.Context) (bool, error) {
+	latestStoredBlock, err := fg.db.QueryLatestFinalizedBlock()
+	if err != nil {
+		return false, err
+	}
+	if latestStoredBlock == nil {
+		return false, nil
+	}
 
-			fg.logger.Debug("Heights to process in batch based on finality signature interval",
-				zap.Uint64("batch_start_height", batchStartHeight),
-				zap.Uint64("batch_end_height", batchEndHeight),
-				zap.Uint64("bsn_activation_height", fg.contractConfig.BsnActivationHeight),
-				zap.Uint64("finality_signature_interval", fg.contractConfig.FinalitySignatureInterval),
-				zap.Strings("heights_to_process", func() []string {
-					var heights []string
-					for _, h := range heightsToProcess {
-						heights = append(heights, fmt.Sprintf("%d", h))
-					}
-					return heights
-				}()))
-
-			// Skip if no heights to process in this batch
-			if len(heightsToProcess) == 0 {
-				fg.logger.Debug("No heights to process in batch based on finality signature interval", zap.Uint64("batch_start_height", batchStartHeight), zap.Uint64("batch_end_height", batchEndHeight))
-				fg.lastProcessedHeight = batchEndHeight
-				batchStartHeight = batchEndHeight + 1
-				continue
-			}
+	// verify parent hash linkage of the next L2 block against the latest stored block
+	if latestStoredBlock.BlockHeight+1 > math.MaxInt64 {
+		return false, fmt.Errorf("block height %d exceeds maximum int64 value", latestStoredBlock.BlockHeight+1)
+	}
+	nextHeader, err := fg.l2Client.HeaderByNumber(ctx, big.NewInt(int64(latestStoredBlock.BlockHeight+1)))
+	if err != nil {
+		return false, fmt.Errorf("error getting block at height %d: %w", latestStoredBlock.BlockHeight+1, err)
+	}
+	if normalizeBlockHash(nextHeader.ParentHash.Hex()) == normalizeBlockHash(latestStoredBlock.BlockHash) {
+		return false, nil
+	}
 
-			// Update channel sizes based on actual heights to process
-			results = make(chan *types.Block, len(heightsToProcess))
-			errors = make(chan error, len(heightsToProcess))
-
-			// Query only the heights that should be processed in parallel
-			for _, height := range heightsToProcess {
-				wg.Add(1)
-				go func(h uint64) {
-					defer wg.Done()
-					block, err := fg.processHeight(h)
-					if block != nil && err == nil {
-						fg.logger.Debug("Processed block", zap.Uint64("block_height", h), zap.String("block_hash", block.BlockHash), zap.Uint64("batch_start_height", batchStartHeight), zap.Uint64("batch_end_height", batchEndHeight))
-					}
-					results <- block
-					errors <- err
-				}(height)
-			}
+	fg.logger.Warn("L2 reorg detected, parent hash does not match latest finalized block in db",
+		zap.Uint64("block_height", latestStoredBlock.BlockHeight),
+		zap.String("stored_block_hash", latestStoredBlock.BlockHash),
+		zap.String("canonical_parent_hash", nextHeader.ParentHash.Hex()))
 
-			// Close results channel once all goroutines complete
-			go func() {
-				wg.Wait()
-				fg.logger.Debug("Closing channels for batch", zap.Uint64("batch_start_height", batchStartHeight), zap.Uint64("batch_end_height", batchEndHeight))
-				close(results)
-				close(errors)
-			}()
+	// find the first height to roll back from
+	rollbackFromHeight, err := fg.findReorgRollbackHeight(latestStoredBlock)
+	if err != nil {
+		return false, fmt.Errorf("error finding L2 reorg fork point: %w", err)
+	}
 
-			// Extract and handle error (if any)
-			for err := range errors {
-				if err != nil {
-					return err
-				}
-			}
+	// the latest stored block is still canonical, so the L2 node is likely in the middle of
+	// switching branches. Wait for the next poll to re-check instead of rolling back
+	if rollbackFromHeight > latestStoredBlock.BlockHeight {
+		fg.logger.Warn("Latest finalized block in db is still canonical, skipping rollback", zap.Uint64("block_height", latestStoredBlock.BlockHeight))
+		return false, nil
+	}
 
-			// Extract blocks and find finalized blocks at signature intervals.
-			// As channels are async, blocks will NOT be ordered by height
-			// We use a map to sort blocks by height, then extract finalized blocks.
-			sortedBlocks := make(map[uint64]*types.Block)
-			for block := range results {
-				if block != nil {
-					sortedBlocks[block.BlockHeight] = block
-				}
-			}
+	if err := fg.rollbackBlocks(rollbackFromHeight, latestStoredBlock.BlockHeight); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-			var finalizedBlocks []*types.Block
-			var lastFinalizedHeight uint64
-			// Check only the heights we processed (at signature intervals)
-			for _, height := range heightsToProcess {
-				if block, ok := sortedBlocks[height]; ok {
-					if block != nil {
-						finalizedBlocks = append(finalizedBlocks, block)
-						lastFinalizedHeight = block.BlockHeight
-					}
-				} else {
-					// If a height we should check is not finalized, stop processing
-					fg.logger.Debug("Block at required height not finalized, stopping", zap.Uint64("height", height))
-					break
-				}
+// findReorgRollbackHeight walks back from the given stored block over the finality signature
+// intervals and returns the lowest height that is no longer on the canonical L2 chain
+func (fg *FinalityGadget) findReorgRollbackHeight(latestStoredBlock *types.Block) (uint64, error) {
+	earliestStoredBlock, err := fg.db.QueryEarliestFinalizedBlock()
+	if err != nil {
+		return 0, err
+	}
+
+	rollbackFromHeight := latestStoredBlock.BlockHeight + 1
+	for height := latestStoredBlock.BlockHeight; height >= earliestStoredBlock.BlockHeight; {
+		storedBlock, err := fg.db.GetBlockByHeight(height)
+		if err != nil && !errors.Is(err, types.ErrBlockNotFound) {
+			return 0, err
+		}
+		if storedBlock != nil {
+			if height > math.MaxInt64 {
+				return 0, fmt.Errorf("block height %d exceeds maximum int64 value", height)
 			}
-			fg.logger.Debug("Last finalized block in batch", zap.Uint64("block_height", lastFinalizedHeight), zap.Uint64("batch_start_height", batchStartHeight), zap.Uint64("batch_end_height", batchEndHeight))
-
-			// If no blocks were finalized at required intervals, wait for next poll
-			if len(finalizedBlocks) == 0 {
-				fg.logger.Debug("No blocks finalized at required signature intervals, waiting for next poll", zap.Uint64("batch_start_height", batchStartHeight), zap.Uint64("batch_end_height", batchEndHeight), zap.Strings("heights_checked", func() []string {
-					var heights []string
-					for _, h := range heightsToProcess {
-						heights = append(heights, fmt.Sprintf("%d", h))
-					}
-					return heights
-				}()))
-				return nil
+			canonicalBlock, err := fg.queryBlockByHeight(int64(height))
+			if err != nil {
+				return 0, fmt.Errorf("error getting block at height %d: %w", height, err)
 			}
-
-			// Batch insert all consecutive finalized blocks
-			fg.logger.Debug("Inserting finalized blocks", zap.Uint64("start_height", finalizedBlocks[0].BlockHeight), zap.Uint64("end_height", finalizedBlocks[len(finalizedBlocks)-1].BlockHeight))
-			if err := fg.insertBlocks(finalizedBlocks); err != nil {
-				return fmt.Errorf("error storing blocks: %w", err)
+			if normalizeBlockHash(canonicalBlock.BlockHash) == normalizeBlockHash(storedBlock.BlockHash) {
+				fg.logger.Info("Found L2 reorg fork point", zap.Uint64("fork_height", height))
+				return rollbackFromHeight, nil
 			}
-			fg.lastProcessedHeight = lastFinalizedHeight
+			rollbackFromHeight = height
+		}
 
-			// Update start height for next batch
-			batchStartHeight = lastFinalizedHeight + 1
+		if height < earliestStoredBlock.BlockHeight+fg.contractConfig.FinalitySignatureInterval {
+			break
 		}
+		height -= fg.contractConfig.FinalitySignatureInterval
 	}
 
-	return nil
+	// none of the stored blocks is canonical anymore, so everything has to be rolled back
+	fg.logger.Error("L2 reorg is deeper than all finalized blocks in db", zap.Uint64("earliest_block_height", earliestStoredBlock.BlockHeight))
+	return earliestStoredBlock.BlockHeight, nil
 }
 
-func (fg *FinalityGadget) processHeight(height uint64) (*types.Block, error) {
-	fg.logger.Debug("Processing block", zap.Uint64("block_height", height))
-	// Fetch block from rpc
-	if height > math.MaxInt64 {
-		fg.logger.Debug("Block height exceeds maximum int64 value", zap.Uint64("block_height", height))
-		return nil, fmt.Errorf("block height %d exceeds maximum int64 value", height)
-	}
-	block, err := fg.queryBlockByHeight(int64(height))
-	if err != nil || block == nil {
-		fg.logger.Error("Error fetching block", zap.Uint64("block_height", height), zap.Error(err))
-		return nil, fmt.Errorf("error getting block at height %d: %w", height, err)
+// rollbackBlocks removes stored blocks from the given height up to the latest height and
+// rewinds the processing cursor so that the affected range is re-processed
+func (fg *FinalityGadget) rollbackBlocks(fromHeight uint64, latestHeight uint64) error {
+	// Lock mutex
+	fg.mutex.Lock()
+	defer fg.mutex.Unlock()
+
+	if err := fg.db.DeleteBlocksFromHeight(fromHeight); err != nil {
+		return fmt.Errorf("failed to roll back blocks: %w", err)
 	}
-	fg.logger.Debug("Fetched block", zap.Uint64("block_height", height), zap.String("block_hash", block.BlockHash))
+	fg.notifySubscribersOfRollback(fromHeight)
 
-	// Check finalization
-	isFinalized, err := fg.QueryIsBlockBabylonFinalizedFromBabylon(block)
-	if err != nil {
-		fg.logger.Error("Error checking if block is finalized from babylon", zap.Uint64("block_height", height), zap.Error(err))
-		return nil, fmt.Errorf("error checking is block %d finalized from babylon: %w", height, err)
+	// stored blocks are always at finality signature intervals
+	rolledBackBlocks := (latestHeight-fromHeight)/fg.contractConfig.FinalitySignatureInterval + 1
+
+	if fromHeight > 0 {
+		fg.lastProcessedHeight.Store(fromHeight - 1)
+	} else {
+		fg.lastProcessedHeight.Store(0)
 	}
-	fg.logger.Debug("Fetched block finality status", zap.Uint64("block_height", height), zap.Bool("is_finalized", isFinalized))
 
-	if !isFinalized {
-		fg.logger.Debug("Block not finalized", zap.Uint64("block_height", height))
-		return nil, nil
+	// Update metrics
+	metrics.L2ReorgsTotal.Inc()
+	metrics.L2ReorgRolledBackBlocksTotal.Add(float64(rolledBackBlocks))
+	if latestBlock, err := fg.db.QueryLatestFinalizedBlock(); err == nil && latestBlock != nil {
+		metrics.LatestFinalizedBlockHeight.Set(float64(latestBlock.BlockHeight))
 	}
 
-	fg.logger.Debug("Block finalized", zap.Uint64("block_height", height))
+	fg.logger.Warn("Rolled back finalized blocks due to L2 reorg",
+		zap.Uint64("from_height", fromHeight),
+		zap.Uint64("to_height", latestHeight),
+		zap.Uint64("rolled_back_blocks", rolledBackBlocks),
+		zap.Uint64("resume_height", fg.lastProcessedHeight.Load()+1))
 
-	// Update latest finalized block height metric
-	metrics.LatestFinalizedBlockHeight.Set(float64(height))
+	return nil
+}
 
-	return block, nil
+// saveCheckpoint stores the processing checkpoint of an evaluation that finalized no new block
+func (fg *FinalityGadget) saveCheckpoint(height uint64, outcome types.CheckpointOutcome) error {
+	if err := fg.db.InsertBlocksWithCheckpoint(nil, newCheckpoint(height, outcome)); err != nil {
+		return fmt.Errorf("failed to save processing checkpoint: %w", err)
+	}
+	return nil
+}
+
+func newCheckpoint(height uint64, outcome types.CheckpointOutcome) *types.ProcessingCheckpoint {
+	return &types.ProcessingCheckpoint{
+		Height:    height,
+		Outcome:   outcome,
+		Timestamp: uint64(time.Now().Unix()),
+	}
 }
 
 // Query the BTC staking activation timestamp from bbnClient
 // returns math.MaxUint64, ErrBtcStakingNotActivated if the BTC staking is not activated
 func (fg *FinalityGadget) queryBtcStakingActivationTimestamp() (uint64, error) {
-	allFpPks, err := fg.queryAllFpBtcPubKeys()
+	allFpPks, err := fg.queryAllFpBtcPubKeys(0)
 	if err != nil {
 		return math.MaxUint64, err
 	}
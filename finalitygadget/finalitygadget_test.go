@@ -1,17 +1,27 @@
 package finalitygadget
 
 import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"math/rand"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/babylonlabs-io/babylon/v3/crypto/eots"
+	bbntypes "github.com/babylonlabs-io/babylon/v3/types"
+	"github.com/babylonlabs-io/finality-gadget/config"
 	"github.com/babylonlabs-io/finality-gadget/testutil"
 	"github.com/babylonlabs-io/finality-gadget/testutil/mocks"
 	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/ethereum/go-ethereum/common"
+	eth "github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"go.uber.org/zap"
@@ -33,15 +43,23 @@ func TestQueryIsBlockBabylonFinalized(t *testing.T) {
 
 	const consumerChainID = "consumer-chain-id"
 	const BTCHeight = uint32(111)
+	const babylonHeight = uint64(222)
+	// the epoch of babylonHeight is the last finalized one
+	const epochInterval = uint64(10)
+	const lastFinalizedEpoch = uint64(23)
 
 	testCases := []struct {
 		name           string
 		expectedErr    error
 		block          *types.Block
 		allFpPks       []string
+		allowedFpPks   []string // defaults to allFpPks if nil
 		fpPowers       map[string]uint64
+		pubRandCommits map[string]*types.PubRandCommit // defaults to a timestamped commit per FP
 		votedProviders []string
+		quorum         types.QuorumRule // defaults to 2/3 if unset
 		expectResult   bool
+		noVotes        bool // the votes aren't queried as no FP can vote yet
 	}{
 		{
 			name:           "0% votes, expects false",
@@ -70,6 +88,36 @@ func TestQueryIsBlockBabylonFinalized(t *testing.T) {
 			expectResult:   true,
 			expectedErr:    nil,
 		},
+		{
+			name:           "exact 2/3 votes with strict quorum, expects false",
+			block:          &blockWithHashTrimmed,
+			allFpPks:       []string{"pk1", "pk2", "pk3"},
+			fpPowers:       map[string]uint64{"pk1": 100, "pk2": 100, "pk3": 100},
+			votedProviders: []string{"pk1", "pk2"},
+			quorum:         types.QuorumRule{Numerator: 2, Denominator: 3, Strict: true},
+			expectResult:   false,
+			expectedErr:    nil,
+		},
+		{
+			name:           "25% votes with 1/4 quorum, expects true",
+			block:          &blockWithHashTrimmed,
+			allFpPks:       []string{"pk1", "pk2"},
+			fpPowers:       map[string]uint64{"pk1": 100, "pk2": 300},
+			votedProviders: []string{"pk1"},
+			quorum:         types.QuorumRule{Numerator: 1, Denominator: 4},
+			expectResult:   true,
+			expectedErr:    nil,
+		},
+		{
+			name:           "75% votes with 4/5 quorum, expects false",
+			block:          &blockWithHashTrimmed,
+			allFpPks:       []string{"pk1", "pk2"},
+			fpPowers:       map[string]uint64{"pk1": 100, "pk2": 300},
+			votedProviders: []string{"pk2"},
+			quorum:         types.QuorumRule{Numerator: 4, Denominator: 5},
+			expectResult:   false,
+			expectedErr:    nil,
+		},
 		{
 			name:           "75% votes, expects true",
 			block:          &blockWithHashTrimmed,
@@ -97,6 +145,72 @@ func TestQueryIsBlockBabylonFinalized(t *testing.T) {
 			expectResult:   true,
 			expectedErr:    nil,
 		},
+		{
+			name:           "non-allow-listed FP excluded from total power, expects true",
+			block:          &blockWithHashTrimmed,
+			allFpPks:       []string{"pk1", "pk2", "pk3"},
+			allowedFpPks:   []string{"pk1", "pk2"},
+			fpPowers:       map[string]uint64{"pk1": 100, "pk2": 100},
+			votedProviders: []string{"pk1", "pk2"},
+			expectResult:   true,
+			expectedErr:    nil,
+		},
+		{
+			name:           "non-allow-listed FP excluded from voted power, expects false",
+			block:          &blockWithHashTrimmed,
+			allFpPks:       []string{"pk1", "pk2", "pk3"},
+			allowedFpPks:   []string{"pk1", "pk2"},
+			fpPowers:       map[string]uint64{"pk1": 100, "pk2": 100},
+			votedProviders: []string{"pk1", "pk3"},
+			expectResult:   false,
+			expectedErr:    nil,
+		},
+		{
+			name:         "empty allow-list allows no FP, expects error",
+			block:        &blockWithHashTrimmed,
+			allFpPks:     []string{"pk1", "pk2", "pk3"},
+			allowedFpPks: []string{},
+			expectResult: false,
+			expectedErr:  types.ErrNoFpHasVotingPower,
+		},
+		{
+			name:     "FP without pub rand commit has no voting power, expects true",
+			block:    &blockWithHashTrimmed,
+			allFpPks: []string{"pk1", "pk2", "pk3"},
+			fpPowers: map[string]uint64{"pk1": 100, "pk2": 100, "pk3": 300},
+			pubRandCommits: map[string]*types.PubRandCommit{
+				"pk3": nil,
+			},
+			votedProviders: []string{"pk1", "pk2"},
+			expectResult:   true,
+			expectedErr:    nil,
+		},
+		{
+			name:     "FP with pub rand committed in an epoch not finalized yet has no voting power, expects false",
+			block:    &blockWithHashTrimmed,
+			allFpPks: []string{"pk1", "pk2", "pk3"},
+			fpPowers: map[string]uint64{"pk1": 100, "pk2": 100, "pk3": 100},
+			pubRandCommits: map[string]*types.PubRandCommit{
+				"pk2": {StartHeight: 100, NumPubRand: 100, BabylonHeight: babylonHeight + epochInterval},
+			},
+			votedProviders: []string{"pk1", "pk2"},
+			expectResult:   false,
+			expectedErr:    nil,
+		},
+		{
+			name:     "no FP with timestamped pub rand yet, expects false without error",
+			block:    &blockWithHashTrimmed,
+			allFpPks: []string{"pk1", "pk2"},
+			fpPowers: map[string]uint64{"pk1": 100, "pk2": 100},
+			pubRandCommits: map[string]*types.PubRandCommit{
+				"pk1": nil,
+				"pk2": {StartHeight: 100, NumPubRand: 100, BabylonHeight: babylonHeight + epochInterval},
+			},
+			votedProviders: []string{"pk1", "pk2"},
+			expectResult:   false,
+			expectedErr:    nil,
+			noVotes:        true,
+		},
 		{
 			name:           "zero voting power, 100% votes, expects false",
 			block:          &blockWithHashUntrimmed,
@@ -113,29 +227,73 @@ func TestQueryIsBlockBabylonFinalized(t *testing.T) {
 			ctl := gomock.NewController(t)
 			defer ctl.Finish()
 
+			// the Babylon height and evidence are recorded on the block, so clear what previous cases
+			// left on the shared blocks
+			for _, block := range []*types.Block{tc.block, &blockWithHashTrimmed} {
+				block.BabylonHeight = 0
+				block.Evidence = nil
+			}
+
+			allowedFpPks := tc.allowedFpPks
+			if allowedFpPks == nil {
+				allowedFpPks = tc.allFpPks
+			}
+
 			mockCwClient := mocks.NewMockICosmWasmClient(ctl)
 			mockCwClient.EXPECT().QueryConsumerId().Return(consumerChainID, nil).Times(1)
-			mockBTCClient := mocks.NewMockIBitcoinClient(ctl)
-			mockBTCClient.EXPECT().
-				GetBlockHeightByTimestamp(tc.block.BlockTimestamp).
-				Return(BTCHeight, nil).
+			mockCwClient.EXPECT().
+				QueryAllowedFinalityProviders(babylonHeight).
+				Return(allowedFpPks, nil).
 				Times(1)
-
+			mockBTCClient := mocks.NewMockIBitcoinClient(ctl)
 			mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+			// the voting power isn't queried when the allow-list allows no FP
+			if len(allowedFpPks) > 0 {
+				mockBTCClient.EXPECT().
+					GetBlockHeightByTimestamp(tc.block.BlockTimestamp).
+					Return(BTCHeight, nil).
+					Times(1)
+				mockBBNClient.EXPECT().
+					QueryMultiFpPower(allowedFpPks, BTCHeight).
+					Return(tc.fpPowers, nil).
+					Times(1)
+			}
+
 			mockBBNClient.EXPECT().
-				QueryAllFpBtcPubKeys(consumerChainID).
+				QueryAllFpBtcPubKeys(consumerChainID, babylonHeight).
 				Return(tc.allFpPks, nil).
 				Times(1)
 
 			mockBBNClient.EXPECT().
-				QueryMultiFpPower(tc.allFpPks, BTCHeight).
-				Return(tc.fpPowers, nil).
+				QueryBabylonHeightByTimestamp(tc.block.BlockTimestamp).
+				Return(babylonHeight, nil).
 				Times(1)
 
-			if !errors.Is(tc.expectedErr, types.ErrNoFpHasVotingPower) {
+			mockBBNClient.EXPECT().QueryEpochInterval().Return(epochInterval, nil).AnyTimes()
+			mockBBNClient.EXPECT().QueryLastFinalizedEpoch().Return(lastFinalizedEpoch, nil).AnyTimes()
+
+			for fpPk, power := range tc.fpPowers {
+				if power == 0 {
+					continue
+				}
+				commit, ok := tc.pubRandCommits[fpPk]
+				if !ok {
+					commit = &types.PubRandCommit{StartHeight: 100, NumPubRand: 100, BabylonHeight: babylonHeight}
+				}
 				mockCwClient.EXPECT().
-					QueryListOfVotedFinalityProviders(&blockWithHashTrimmed).
-					Return(tc.votedProviders, tc.expectedErr).
+					QueryPubRandCommitForHeight(fpPk, tc.block.BlockHeight).
+					Return(commit, nil).
+					Times(1)
+			}
+
+			if !errors.Is(tc.expectedErr, types.ErrNoFpHasVotingPower) && !tc.noVotes {
+				voters := make([]*types.BlockVoter, len(tc.votedProviders))
+				for i, fpPk := range tc.votedProviders {
+					voters[i] = &types.BlockVoter{FpBtcPkHex: fpPk}
+				}
+				mockCwClient.EXPECT().
+					QueryBlockVoters(&blockWithHashTrimmed).
+					Return(voters, tc.expectedErr).
 					Times(1)
 			}
 
@@ -143,11 +301,31 @@ func TestQueryIsBlockBabylonFinalized(t *testing.T) {
 				cwClient:  mockCwClient,
 				bbnClient: mockBBNClient,
 				btcClient: mockBTCClient,
+				quorum:    tc.quorum,
+				logger:    zap.NewNop(),
 			}
 
 			res, err := mockFinalityGadget.QueryIsBlockBabylonFinalizedFromBabylon(tc.block)
 			require.Equal(t, tc.expectResult, res)
 			require.Equal(t, tc.expectedErr, err)
+			require.Equal(t, babylonHeight, tc.block.BabylonHeight)
+
+			if tc.expectedErr == nil && !tc.noVotes {
+				evidence := tc.block.Evidence
+				require.NotNil(t, evidence)
+				require.Equal(t, babylonHeight, evidence.BabylonHeight)
+				require.Equal(t, BTCHeight, evidence.BtcHeight)
+				require.Equal(t, tc.expectResult, mockFinalityGadget.quorumRule().Reached(evidence.VotedPower, evidence.TotalPower))
+				// the evidence records the configured quorum, not the observed participation
+				require.Equal(t, mockFinalityGadget.quorumRule().Ratio(), evidence.QuorumRatio)
+				require.Equal(t, mockFinalityGadget.quorumRule().Threshold(evidence.TotalPower), evidence.QuorumPower)
+				require.Equal(t, tc.expectResult, evidence.VotedPower >= evidence.QuorumPower)
+				var voterPower uint64
+				for _, voter := range evidence.Voters {
+					voterPower += voter.Power
+				}
+				require.Equal(t, evidence.VotedPower, voterPower)
+			}
 		})
 	}
 }
@@ -275,7 +453,7 @@ func TestInsertBlock(t *testing.T) {
 	}
 
 	// insert block
-	err := mockFinalityGadget.insertBlocks(blocks)
+	err := mockFinalityGadget.insertBlocks(blocks, nil)
 	require.NoError(t, err)
 
 	// verify block was inserted
@@ -379,7 +557,7 @@ func TestBatchInsertBlocks(t *testing.T) {
 			}
 
 			// Test batch insert
-			err := mockFinalityGadget.insertBlocks(tc.blocks)
+			err := mockFinalityGadget.insertBlocks(tc.blocks, nil)
 			require.NoError(t, err)
 
 			// Verify each block was inserted correctly
@@ -415,7 +593,7 @@ func TestGetBlockByHeight(t *testing.T) {
 	}
 
 	// insert block
-	err := mockFinalityGadget.insertBlocks(blocks)
+	err := mockFinalityGadget.insertBlocks(blocks, nil)
 	require.NoError(t, err)
 
 	// fetch block by height
@@ -462,7 +640,7 @@ func TestGetBlockByHashWith0xPrefix(t *testing.T) {
 	}
 
 	// insert block
-	err := mockFinalityGadget.insertBlocks(blocks)
+	err := mockFinalityGadget.insertBlocks(blocks, nil)
 	require.NoError(t, err)
 
 	// fetch block by hash including 0x prefix
@@ -500,7 +678,7 @@ func TestGetBlockByHashWithout0xPrefix(t *testing.T) {
 	}
 
 	// insert block
-	err := mockFinalityGadget.insertBlocks(blocks)
+	err := mockFinalityGadget.insertBlocks(blocks, nil)
 	require.NoError(t, err)
 
 	// fetch block by hash including 0x prefix
@@ -653,7 +831,7 @@ func TestQueryLatestFinalizedBlock(t *testing.T) {
 	}
 
 	// insert two blocks
-	err := mockFinalityGadget.insertBlocks(blocks)
+	err := mockFinalityGadget.insertBlocks(blocks, nil)
 	require.NoError(t, err)
 
 	// fetch latest block
@@ -706,7 +884,7 @@ func TestQueryBtcStakingActivatedTimestamp(t *testing.T) {
 	// Test case 2: Timestamp is not in the database, need to query from bbnClient
 	mockDbHandler.EXPECT().GetActivatedTimestamp().Return(uint64(0), types.ErrActivatedTimestampNotFound)
 	mockCwClient.EXPECT().QueryConsumerId().Return("consumer-chain-id", nil)
-	mockBBNClient.EXPECT().QueryAllFpBtcPubKeys("consumer-chain-id").Return([]string{"pk1", "pk2"}, nil)
+	mockBBNClient.EXPECT().QueryAllFpBtcPubKeys("consumer-chain-id", uint64(0)).Return([]string{"pk1", "pk2"}, nil)
 	mockBBNClient.EXPECT().QueryEarliestActiveDelBtcHeight([]string{"pk1", "pk2"}).Return(uint32(100), nil)
 	mockBTCClient.EXPECT().GetBlockTimestampByHeight(uint32(100)).Return(uint64(1234567890), nil)
 
@@ -717,7 +895,7 @@ func TestQueryBtcStakingActivatedTimestamp(t *testing.T) {
 	// Test case 3: BTC staking is not activated
 	mockDbHandler.EXPECT().GetActivatedTimestamp().Return(uint64(0), types.ErrActivatedTimestampNotFound)
 	mockCwClient.EXPECT().QueryConsumerId().Return("consumer-chain-id", nil)
-	mockBBNClient.EXPECT().QueryAllFpBtcPubKeys("consumer-chain-id").Return([]string{"pk1", "pk2"}, nil)
+	mockBBNClient.EXPECT().QueryAllFpBtcPubKeys("consumer-chain-id", uint64(0)).Return([]string{"pk1", "pk2"}, nil)
 	mockBBNClient.EXPECT().QueryEarliestActiveDelBtcHeight([]string{"pk1", "pk2"}).Return(uint32(math.MaxUint32), nil)
 
 	timestamp, err = mockFinalityGadget.QueryBtcStakingActivatedTimestamp()
@@ -725,10 +903,625 @@ func TestQueryBtcStakingActivatedTimestamp(t *testing.T) {
 	require.Equal(t, uint64(math.MaxUint64), timestamp)
 }
 
+func TestCheckAndHandleReorg(t *testing.T) {
+	contractConfig := &types.ContractConfig{
+		BsnActivationHeight:       10,
+		FinalitySignatureInterval: 5,
+	}
+
+	// branch A is the chain the stored blocks were finalized on, branch B is the new canonical chain
+	// that forks off after height 10
+	headerA10 := &eth.Header{Number: big.NewInt(10), Time: 1000}
+	headerA15 := &eth.Header{Number: big.NewInt(15), Time: 1010, ParentHash: common.HexToHash("0xa14")}
+	headerA20 := &eth.Header{Number: big.NewInt(20), Time: 1020, ParentHash: common.HexToHash("0xa19")}
+	headerB15 := &eth.Header{Number: big.NewInt(15), Time: 1011, ParentHash: common.HexToHash("0xb14")}
+	headerB20 := &eth.Header{Number: big.NewInt(20), Time: 1021, ParentHash: common.HexToHash("0xb19")}
+
+	storedA10 := headerToBlock(headerA10)
+	storedA15 := headerToBlock(headerA15)
+	storedA20 := headerToBlock(headerA20)
+
+	t.Run("no reorg", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockDbHandler := mocks.NewMockIDatabaseHandler(ctl)
+		mockL2Client := mocks.NewMockIEthL2Client(ctl)
+
+		mockDbHandler.EXPECT().QueryLatestFinalizedBlock().Return(storedA20, nil).Times(1)
+		mockL2Client.EXPECT().
+			HeaderByNumber(gomock.Any(), big.NewInt(21)).
+			Return(&eth.Header{Number: big.NewInt(21), ParentHash: headerA20.Hash()}, nil).
+			Times(1)
+
+		mockFinalityGadget := &FinalityGadget{
+			db:             mockDbHandler,
+			l2Client:       mockL2Client,
+			contractConfig: contractConfig,
+			logger:         zap.NewNop(),
+		}
+		mockFinalityGadget.lastProcessedHeight.Store(22)
+
+		reorged, err := mockFinalityGadget.checkAndHandleReorg(context.Background())
+		require.NoError(t, err)
+		require.False(t, reorged)
+		require.Equal(t, uint64(22), mockFinalityGadget.lastProcessedHeight.Load())
+	})
+
+	t.Run("reorg rolls back to fork point", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockDbHandler := mocks.NewMockIDatabaseHandler(ctl)
+		mockL2Client := mocks.NewMockIEthL2Client(ctl)
+
+		gomock.InOrder(
+			mockDbHandler.EXPECT().QueryLatestFinalizedBlock().Return(storedA20, nil),
+			mockDbHandler.EXPECT().QueryEarliestFinalizedBlock().Return(storedA10, nil),
+			mockDbHandler.EXPECT().DeleteBlocksFromHeight(uint64(15)).Return(nil),
+			mockDbHandler.EXPECT().QueryLatestFinalizedBlock().Return(storedA10, nil),
+		)
+		mockDbHandler.EXPECT().GetBlockByHeight(uint64(20)).Return(storedA20, nil).Times(1)
+		mockDbHandler.EXPECT().GetBlockByHeight(uint64(15)).Return(storedA15, nil).Times(1)
+		mockDbHandler.EXPECT().GetBlockByHeight(uint64(10)).Return(storedA10, nil).Times(1)
+
+		mockL2Client.EXPECT().
+			HeaderByNumber(gomock.Any(), big.NewInt(21)).
+			Return(&eth.Header{Number: big.NewInt(21), ParentHash: headerB20.Hash()}, nil).
+			Times(1)
+		mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(20)).Return(headerB20, nil).Times(1)
+		mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(15)).Return(headerB15, nil).Times(1)
+		mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(headerA10, nil).Times(1)
+
+		mockFinalityGadget := &FinalityGadget{
+			db:             mockDbHandler,
+			l2Client:       mockL2Client,
+			contractConfig: contractConfig,
+			logger:         zap.NewNop(),
+		}
+		mockFinalityGadget.lastProcessedHeight.Store(22)
+
+		reorged, err := mockFinalityGadget.checkAndHandleReorg(context.Background())
+		require.NoError(t, err)
+		require.True(t, reorged)
+		require.Equal(t, uint64(14), mockFinalityGadget.lastProcessedHeight.Load())
+	})
+
+	t.Run("empty db", func(t *testing.T) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+
+		mockDbHandler := mocks.NewMockIDatabaseHandler(ctl)
+		mockDbHandler.EXPECT().QueryLatestFinalizedBlock().Return(nil, nil).Times(1)
+
+		mockFinalityGadget := &FinalityGadget{
+			db:             mockDbHandler,
+			contractConfig: contractConfig,
+			logger:         zap.NewNop(),
+		}
+
+		reorged, err := mockFinalityGadget.checkAndHandleReorg(context.Background())
+		require.NoError(t, err)
+		require.False(t, reorged)
+	})
+}
+
+func TestDetermineStartingHeightFromCheckpoint(t *testing.T) {
+	dbHandler := setupRetentionDB(t, 10, 20, time.Now())
+	contractConfig := &types.ContractConfig{BsnActivationHeight: 1, FinalitySignatureInterval: 1}
+	cfg := &config.Config{}
+
+	// Without a checkpoint, processing resumes above the latest finalized block
+	height, err := determineStartingHeight(cfg, dbHandler, contractConfig, zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), height)
+
+	// The heights evaluated above the latest finalized block are not evaluated again
+	checkpoint := &types.ProcessingCheckpoint{Height: 35, Outcome: types.CheckpointNotFinalized, Timestamp: 1}
+	require.NoError(t, dbHandler.InsertBlocksWithCheckpoint(nil, checkpoint))
+	height, err = determineStartingHeight(cfg, dbHandler, contractConfig, zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, uint64(35), height)
+
+	// A configured start height above the checkpoint takes precedence
+	height, err = determineStartingHeight(&config.Config{StartBlockHeight: 40}, dbHandler, contractConfig, zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, uint64(39), height)
+
+	// A rollback rewinds the checkpoint below the rolled back blocks
+	fg := &FinalityGadget{db: dbHandler, logger: zap.NewNop(), contractConfig: contractConfig}
+	require.NoError(t, fg.rollbackBlocks(15, 20))
+	height, err = determineStartingHeight(cfg, dbHandler, contractConfig, zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, uint64(14), height)
+}
+
+func TestStartupRetriesTransientErrors(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	dbHandler := setupRetentionDB(t, 10, 20, time.Now())
+	require.NoError(t, dbHandler.SaveActivatedTimestamp(1000))
+
+	// The first L2 query fails transiently, the next one succeeds
+	transientErr := types.NewUpstreamError(types.UpstreamL2, types.ErrorClassTransient, errors.New("connection refused"))
+	mockL2Client := mocks.NewMockIEthL2Client(ctl)
+	gomock.InOrder(
+		mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).Return(nil, transientErr).Times(1),
+		mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).
+			Return(&eth.Header{Number: big.NewInt(25), Time: 2000}, nil).Times(1),
+	)
+
+	fg := &FinalityGadget{
+		l2Client:     mockL2Client,
+		db:           dbHandler,
+		logger:       zap.NewNop(),
+		pollInterval: 10 * time.Millisecond,
+	}
+	require.NoError(t, fg.Startup(context.Background()))
+	require.Equal(t, uint64(24), fg.lastProcessedHeight.Load())
+	require.False(t, fg.QueryHealthStatus().Degraded)
+
+	// Permanent errors are still returned
+	permanentErr := types.NewUpstreamError(types.UpstreamL2, types.ErrorClassPermanent, errors.New("401 Unauthorized"))
+	mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).Return(nil, permanentErr).Times(1)
+	require.ErrorIs(t, fg.Startup(context.Background()), permanentErr)
+}
+
+func TestValidVotes(t *testing.T) {
+	block := &types.Block{
+		BlockHash:   "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3",
+		BlockHeight: 123,
+	}
+	blockHash, err := hex.DecodeString(block.BlockHash)
+	require.NoError(t, err)
+	msg := append(binary.BigEndian.AppendUint64(nil, block.BlockHeight), blockHash...)
+
+	validVote := signedVote(t, msg)
+	wrongMsgVote := signedVote(t, append(binary.BigEndian.AppendUint64(nil, block.BlockHeight+1), blockHash...))
+	wrongPubRandVote := signedVote(t, msg)
+	wrongPubRandVote.PubRand = validVote.PubRand
+	malformedVote := &types.BlockVoter{FpBtcPkHex: "pk4"}
+	votes := []*types.BlockVoter{validVote, wrongMsgVote, wrongPubRandVote, malformedVote}
+
+	t.Run("verification disabled counts all votes", func(t *testing.T) {
+		mockFinalityGadget := &FinalityGadget{logger: zap.NewNop()}
+
+		validVotes, err := mockFinalityGadget.validVotes(votes, block)
+		require.NoError(t, err)
+		require.Equal(t, votes, validVotes)
+	})
+
+	t.Run("verification enabled drops invalid votes", func(t *testing.T) {
+		mockFinalityGadget := &FinalityGadget{logger: zap.NewNop(), verifyEotsSigs: true}
+
+		validVotes, err := mockFinalityGadget.validVotes(votes, block)
+		require.NoError(t, err)
+		require.Equal(t, []*types.BlockVoter{validVote}, validVotes)
+	})
+}
+
+func TestQueryFinalityProof(t *testing.T) {
+	block := &types.Block{
+		BlockHash:      normalizeBlockHash("0xd4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"),
+		BlockHeight:    123,
+		BlockTimestamp: 12345,
+		BabylonHeight:  222,
+	}
+	trimmedBlock := *block
+	trimmedBlock.BlockHash = strings.TrimPrefix(block.BlockHash, "0x")
+	msg, err := voteMsg(block.BlockHeight, block.BlockHash)
+	require.NoError(t, err)
+
+	const consumerChainID = "consumer-chain-id"
+	const BTCHeight = uint32(111)
+	const votesBabylonHeight = uint64(1000)
+
+	// pk1 and pk2 voted with power, pk3 voted without power and the vote of pk4 wasn't counted
+	vote1, vote2, vote3, vote4 := signedVote(t, msg), signedVote(t, msg), signedVote(t, msg), signedVote(t, msg)
+	evidence := &types.FinalityEvidence{
+		BlockHeight:   block.BlockHeight,
+		BlockHash:     trimmedBlock.BlockHash,
+		BabylonHeight: block.BabylonHeight,
+		BtcHeight:     BTCHeight,
+		TotalPower:    300,
+		VotedPower:    200,
+		Voters: []*types.VoterPower{
+			{FpBtcPkHex: vote1.FpBtcPkHex, Power: 100},
+			{FpBtcPkHex: vote2.FpBtcPkHex, Power: 100},
+			{FpBtcPkHex: vote3.FpBtcPkHex, Power: 0},
+		},
+	}
+	forgedVote1 := &types.BlockVoter{
+		FpBtcPkHex:        vote1.FpBtcPkHex,
+		PubRand:           vote1.PubRand,
+		FinalitySignature: vote2.FinalitySignature,
+	}
+
+	testCases := []struct {
+		name           string
+		votes          []*types.BlockVoter
+		verifyEotsSigs bool
+		expectErr      error
+	}{
+		{
+			name:           "signatures verified",
+			votes:          []*types.BlockVoter{vote4, vote2, vote3, vote1},
+			verifyEotsSigs: true,
+		},
+		{
+			name:           "signatures not verified",
+			votes:          []*types.BlockVoter{vote4, vote2, vote3, vote1},
+			verifyEotsSigs: false,
+		},
+		{
+			name:           "invalid signature left out, expects not finalized",
+			votes:          []*types.BlockVoter{vote2, forgedVote1},
+			verifyEotsSigs: true,
+			expectErr:      types.ErrBlockNotFinalized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			mockDbHandler := mocks.NewMockIDatabaseHandler(ctl)
+			mockDbHandler.EXPECT().GetBlockByHeight(block.BlockHeight).Return(block, nil).Times(1)
+			mockDbHandler.EXPECT().GetEvidenceByHeight(block.BlockHeight).Return(evidence, nil).Times(1)
+
+			// the FP set and the voting power come from the evidence, only the votes are queried
+			mockCwClient := mocks.NewMockICosmWasmClient(ctl)
+			mockCwClient.EXPECT().QueryConsumerId().Return(consumerChainID, nil).Times(1)
+			mockCwClient.EXPECT().QueryBlockVotersAtHeight(&trimmedBlock, votesBabylonHeight).Return(tc.votes, nil).Times(1)
+			mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+			mockBBNClient.EXPECT().QueryLatestHeight().Return(votesBabylonHeight, nil).Times(1)
+
+			mockFinalityGadget := &FinalityGadget{
+				db:             mockDbHandler,
+				cwClient:       mockCwClient,
+				bbnClient:      mockBBNClient,
+				logger:         zap.NewNop(),
+				verifyEotsSigs: tc.verifyEotsSigs,
+			}
+
+			proof, err := mockFinalityGadget.QueryFinalityProof(block.BlockHeight)
+			if tc.expectErr != nil {
+				require.ErrorIs(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, types.FinalityProofVersion, proof.Version)
+			require.Equal(t, consumerChainID, proof.ConsumerId)
+			require.Equal(t, block.BlockHash, proof.BlockHash)
+			require.Equal(t, block.BabylonHeight, proof.BabylonHeight)
+			require.Equal(t, BTCHeight, proof.BtcHeight)
+			require.Equal(t, votesBabylonHeight, proof.VotesBabylonHeight)
+			require.Equal(t, evidence.TotalPower, proof.TotalPower)
+			require.Equal(t, tc.verifyEotsSigs, proof.SignaturesVerified)
+			require.Len(t, proof.FinalityProviders, 2)
+			require.Len(t, proof.Votes, 2)
+			require.NoError(t, VerifyFinalityProof(proof, types.DefaultQuorumRule))
+		})
+	}
+}
+
+func TestVerifyFinalityProof(t *testing.T) {
+	block := &types.Block{
+		BlockHash:   "0xd4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3",
+		BlockHeight: 123,
+	}
+	msg, err := voteMsg(block.BlockHeight, block.BlockHash)
+	require.NoError(t, err)
+	vote1, vote2, vote3 := signedVote(t, msg), signedVote(t, msg), signedVote(t, msg)
+
+	// newProof returns a proof with three FPs of equal power, of which the given ones voted
+	newProof := func(votes ...*types.BlockVoter) *types.FinalityProof {
+		return &types.FinalityProof{
+			Version:     types.FinalityProofVersion,
+			BlockHeight: block.BlockHeight,
+			BlockHash:   block.BlockHash,
+			TotalPower:  300,
+			FinalityProviders: []*types.VoterPower{
+				{FpBtcPkHex: vote1.FpBtcPkHex, Power: 100},
+				{FpBtcPkHex: vote2.FpBtcPkHex, Power: 100},
+				{FpBtcPkHex: vote3.FpBtcPkHex, Power: 100},
+			},
+			Votes: votes,
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		proof       *types.FinalityProof
+		quorum      types.QuorumRule // defaults to 2/3 if unset
+		expectValid bool
+	}{
+		{
+			name:        "exact 2/3 votes, expects valid",
+			proof:       newProof(vote1, vote2),
+			expectValid: true,
+		},
+		{
+			name:        "exact 2/3 votes with strict quorum, expects invalid",
+			proof:       newProof(vote1, vote2),
+			quorum:      types.QuorumRule{Numerator: 2, Denominator: 3, Strict: true},
+			expectValid: false,
+		},
+		{
+			name:        "1/3 votes, expects invalid",
+			proof:       newProof(vote1),
+			expectValid: false,
+		},
+		{
+			name:        "1/3 votes with 1/3 quorum, expects valid",
+			proof:       newProof(vote1),
+			quorum:      types.QuorumRule{Numerator: 1, Denominator: 3},
+			expectValid: true,
+		},
+		{
+			name:        "duplicate vote, expects invalid",
+			proof:       newProof(vote1, vote1),
+			expectValid: false,
+		},
+		{
+			name:        "vote by unknown FP, expects invalid",
+			proof:       newProof(vote1, vote2, signedVote(t, msg)),
+			expectValid: false,
+		},
+		{
+			name: "vote with invalid signature, expects invalid",
+			proof: newProof(vote1, &types.BlockVoter{
+				FpBtcPkHex:        vote2.FpBtcPkHex,
+				PubRand:           vote2.PubRand,
+				FinalitySignature: vote1.FinalitySignature,
+			}),
+			expectValid: false,
+		},
+		{
+			name: "FPs power above the total power, expects invalid",
+			proof: func() *types.FinalityProof {
+				proof := newProof(vote1, vote2)
+				proof.TotalPower = 200
+				return proof
+			}(),
+			expectValid: false,
+		},
+		{
+			name: "unsupported version, expects invalid",
+			proof: func() *types.FinalityProof {
+				proof := newProof(vote1, vote2)
+				proof.Version = types.FinalityProofVersion + 1
+				return proof
+			}(),
+			expectValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quorum := tc.quorum
+			if quorum.Denominator == 0 {
+				quorum = types.DefaultQuorumRule
+			}
+			err := VerifyFinalityProof(tc.proof, quorum)
+			if tc.expectValid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, types.ErrInvalidFinalityProof)
+			}
+		})
+	}
+}
+
+func TestResolveQuorumRule(t *testing.T) {
+	testCases := []struct {
+		name           string
+		cfg            *config.Config
+		contractConfig *types.ContractConfig
+		expectedQuorum types.QuorumRule
+		expectErr      bool
+	}{
+		{
+			name:           "nothing set, expects default",
+			cfg:            &config.Config{},
+			contractConfig: &types.ContractConfig{},
+			expectedQuorum: types.DefaultQuorumRule,
+		},
+		{
+			name:           "strict only, expects strict default",
+			cfg:            &config.Config{QuorumStrict: true},
+			contractConfig: &types.ContractConfig{},
+			expectedQuorum: types.QuorumRule{Numerator: 2, Denominator: 3, Strict: true},
+		},
+		{
+			name:           "contract quorum, expects contract quorum",
+			cfg:            &config.Config{},
+			contractConfig: &types.ContractConfig{QuorumNumerator: 3, QuorumDenominator: 4},
+			expectedQuorum: types.QuorumRule{Numerator: 3, Denominator: 4},
+		},
+		{
+			name:           "config and contract quorum, expects config quorum",
+			cfg:            &config.Config{QuorumNumerator: 1, QuorumDenominator: 2, QuorumStrict: true},
+			contractConfig: &types.ContractConfig{QuorumNumerator: 3, QuorumDenominator: 4},
+			expectedQuorum: types.QuorumRule{Numerator: 1, Denominator: 2, Strict: true},
+		},
+		{
+			name:           "invalid contract quorum, expects error",
+			cfg:            &config.Config{},
+			contractConfig: &types.ContractConfig{QuorumNumerator: 5, QuorumDenominator: 4},
+			expectErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quorum, err := resolveQuorumRule(tc.cfg, tc.contractConfig, zap.NewNop())
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedQuorum, quorum)
+		})
+	}
+}
+
+func TestQuorumRuleReached(t *testing.T) {
+	// products of large voting powers overflow uint64
+	total := uint64(math.MaxUint64 / 2)
+	require.True(t, types.DefaultQuorumRule.Reached(total/3*2+1, total))
+	require.False(t, types.DefaultQuorumRule.Reached(total/3*2-1, total))
+	require.True(t, types.QuorumRule{Numerator: 1, Denominator: 1}.Reached(total, total))
+	require.False(t, types.QuorumRule{Numerator: 1, Denominator: 2, Strict: true}.Reached(50, 100))
+	require.True(t, types.QuorumRule{Numerator: 1, Denominator: 2}.Reached(50, 100))
+}
+
+func TestQueryMultiFpPowerCache(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+	mockBBNClient.EXPECT().
+		QueryMultiFpPower([]string{"pk1", "pk2"}, uint32(100)).
+		Return(map[string]uint64{"pk1": 100, "pk2": 200}, nil).
+		Times(1)
+	mockBBNClient.EXPECT().
+		QueryMultiFpPower([]string{"pk1", "pk2"}, uint32(101)).
+		Return(map[string]uint64{"pk1": 100, "pk2": 300}, nil).
+		Times(1)
+	mockBBNClient.EXPECT().
+		QueryMultiFpPower([]string{"pk1"}, uint32(100)).
+		Return(map[string]uint64{"pk1": 100}, nil).
+		Times(1)
+	mockBBNClient.EXPECT().
+		QueryMultiFpPower([]string{"pk1", "pk2"}, uint32(100)).
+		Return(map[string]uint64{"pk1": 100, "pk2": 0}, nil).
+		Times(1)
+
+	cache, err := newFpPowerCache(fpPowerCacheSize, 500*time.Millisecond)
+	require.NoError(t, err)
+	fg := &FinalityGadget{
+		bbnClient:    mockBBNClient,
+		fpPowerCache: cache,
+		logger:       zap.NewNop(),
+	}
+
+	fpPower, err := fg.queryMultiFpPower([]string{"pk1", "pk2"}, 100)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"pk1": 100, "pk2": 200}, fpPower)
+
+	// modifying the returned table doesn't affect the cached one
+	fpPower["pk2"] = 0
+
+	// same FP set in a different order at the same BTC height is served from the cache
+	fpPower, err = fg.queryMultiFpPower([]string{"pk2", "pk1"}, 100)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"pk1": 100, "pk2": 200}, fpPower)
+
+	// another BTC height or FP set is queried
+	fpPower, err = fg.queryMultiFpPower([]string{"pk1", "pk2"}, 101)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"pk1": 100, "pk2": 300}, fpPower)
+	fpPower, err = fg.queryMultiFpPower([]string{"pk1"}, 100)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"pk1": 100}, fpPower)
+
+	// once expired, the table is queried again, eg. after pk2 unbonded
+	time.Sleep(600 * time.Millisecond)
+	fpPower, err = fg.queryMultiFpPower([]string{"pk1", "pk2"}, 100)
+	require.NoError(t, err)
+	require.Equal(t, map[string]uint64{"pk1": 100, "pk2": 0}, fpPower)
+}
+
+func TestPubRandCommitCache(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// pk1 committed for heights 100 to 199 in epoch 23, then for heights 200 to 299 in epoch 24
+	commit1 := &types.PubRandCommit{StartHeight: 100, NumPubRand: 100, BabylonHeight: 222}
+	commit2 := &types.PubRandCommit{StartHeight: 200, NumPubRand: 100, BabylonHeight: 232}
+	mockCwClient := mocks.NewMockICosmWasmClient(ctl)
+	mockCwClient.EXPECT().QueryPubRandCommitForHeight("pk1", uint64(100)).Return(commit1, nil).Times(1)
+	mockCwClient.EXPECT().QueryPubRandCommitForHeight("pk1", uint64(200)).Return(commit2, nil).Times(1)
+	mockCwClient.EXPECT().QueryPubRandCommitForHeight("pk1", uint64(300)).Return(nil, nil).Times(2)
+	lastFinalizedEpoch := uint64(23)
+	mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+	mockBBNClient.EXPECT().QueryEpochInterval().Return(uint64(10), nil).Times(1)
+	mockBBNClient.EXPECT().QueryLastFinalizedEpoch().DoAndReturn(func() (uint64, error) {
+		return lastFinalizedEpoch, nil
+	}).Times(3)
+
+	cache, err := newPubRandCommitCache(pubRandCommitCacheSize)
+	require.NoError(t, err)
+	fg := &FinalityGadget{
+		cwClient:           mockCwClient,
+		bbnClient:          mockBBNClient,
+		pubRandCommitCache: cache,
+		logger:             zap.NewNop(),
+	}
+
+	// heights covered by the commitment found last are served from the cache
+	for _, height := range []uint64{100, 150, 199} {
+		commit, err := fg.queryPubRandCommitForHeight("pk1", height)
+		require.NoError(t, err)
+		require.Equal(t, commit1, commit)
+	}
+	commit, err := fg.queryPubRandCommitForHeight("pk1", 200)
+	require.NoError(t, err)
+	require.Equal(t, commit2, commit)
+	// missing commitments are queried again
+	for range 2 {
+		commit, err = fg.queryPubRandCommitForHeight("pk1", 300)
+		require.NoError(t, err)
+		require.Nil(t, commit)
+	}
+
+	// the last finalized epoch is only queried again for commitments of later epochs
+	for range 2 {
+		timestamped, err := fg.isPubRandCommitTimestamped(commit1)
+		require.NoError(t, err)
+		require.True(t, timestamped)
+	}
+	timestamped, err := fg.isPubRandCommitTimestamped(commit2)
+	require.NoError(t, err)
+	require.False(t, timestamped)
+	lastFinalizedEpoch = 24
+	timestamped, err = fg.isPubRandCommitTimestamped(commit2)
+	require.NoError(t, err)
+	require.True(t, timestamped)
+}
+
+// signedVote returns a vote by a fresh FP key with a valid EOTS signature over msg
+func signedVote(t *testing.T, msg []byte) *types.BlockVoter {
+	sk, err := eots.KeyGen(cryptorand.Reader)
+	require.NoError(t, err)
+	secRand, pubRand, err := eots.RandGen(cryptorand.Reader)
+	require.NoError(t, err)
+	sig, err := eots.Sign(sk, secRand, msg)
+	require.NoError(t, err)
+
+	return &types.BlockVoter{
+		FpBtcPkHex:        bbntypes.NewBIP340PubKeyFromBTCPK(eots.PubGen(sk)).MarshalHex(),
+		PubRand:           bbntypes.NewSchnorrPubRandFromFieldVal(pubRand).MustMarshal(),
+		FinalitySignature: bbntypes.NewSchnorrEOTSSigFromModNScalar(sig).MustMarshal(),
+	}
+}
+
+func headerToBlock(header *eth.Header) *types.Block {
+	return &types.Block{
+		BlockHeight:    header.Number.Uint64(),
+		BlockHash:      normalizeBlockHash(header.Hash().Hex()),
+		BlockTimestamp: header.Time,
+	}
+}
+
 func normalizedBlock(block *types.Block) *types.Block {
 	return &types.Block{
 		BlockHeight:    block.BlockHeight,
 		BlockHash:      normalizeBlockHash(block.BlockHash),
 		BlockTimestamp: block.BlockTimestamp,
+		BabylonHeight:  block.BabylonHeight,
 	}
 }
@@ -0,0 +1,308 @@
+package finalitygadget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/metrics"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultReadinessMaxLag is the default number of L2 blocks above the latest finalized block for the finality
+	// gadget to be ready
+	defaultReadinessMaxLag = 100
+	// readinessMaxPollIntervals is the number of poll intervals without a successful poll after which the finality
+	// gadget isn't ready anymore
+	readinessMaxPollIntervals = 10
+	// healthCheckTimeout bounds each readiness check of a dependency
+	healthCheckTimeout = 5 * time.Second
+	// livenessMaxPollIntervals is the number of poll intervals without a heartbeat of the block processing after
+	// which the finality gadget isn't alive anymore
+	livenessMaxPollIntervals = 10
+	// livenessMinTimeout is the minimum time without a heartbeat after which the finality gadget isn't alive anymore,
+	// above the max retry backoff and the timeouts of the upstream calls
+	livenessMinTimeout = 2 * time.Minute
+)
+
+// Components reported as degraded besides the stages of the block processing pipeline
+const (
+	// componentPoll is the poll of the latest L2 block
+	componentPoll = "poll"
+	// componentBtcHeaderSync is the sync of the local BTC header index
+	componentBtcHeaderSync = "btc_header_sync"
+	// componentFpSet is the FP set of the consumer chain, failing while it has no voting power
+	componentFpSet = "fp_set"
+)
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+// QueryHealthStatus returns whether the finality gadget is degraded, and which of its components keep failing
+func (fg *FinalityGadget) QueryHealthStatus() *types.HealthStatus {
+	fg.degradedMutex.Lock()
+	defer fg.degradedMutex.Unlock()
+
+	status := &types.HealthStatus{Degraded: len(fg.degraded) > 0}
+	for _, failure := range fg.degraded {
+		failureCopy := *failure
+		status.Failures = append(status.Failures, &failureCopy)
+	}
+	sort.Slice(status.Failures, func(i, j int) bool {
+		return status.Failures[i].Component < status.Failures[j].Component
+	})
+	return status
+}
+
+/* QueryLiveness returns whether the finality gadget is running, regardless of its dependencies
+ *
+ * - the block processing loop must be running
+ * - it must have shown a heartbeat within livenessMaxPollIntervals poll intervals, and at least livenessMinTimeout.
+ *   The poll loop, the committer and every attempt of a pipeline stage beat, so failing upstreams don't stop the
+ *   heartbeat, but a call hanging forever does
+ */
+func (fg *FinalityGadget) QueryLiveness() *types.LivenessStatus {
+	status := &types.LivenessStatus{
+		UptimeSeconds:            uint64(time.Since(fg.startTime).Seconds()),
+		MaxSecondsSinceHeartbeat: max(livenessMaxPollIntervals*fg.pollInterval, livenessMinTimeout).Seconds(),
+	}
+	heartbeat := fg.heartbeat.Load()
+	if heartbeat == 0 {
+		return status
+	}
+	status.LastHeartbeatTimestamp = uint64(heartbeat / int64(time.Second)) // #nosec G115
+	status.SecondsSinceHeartbeat = time.Since(time.Unix(0, heartbeat)).Seconds()
+	status.Alive = fg.processing.Load() && status.SecondsSinceHeartbeat <= status.MaxSecondsSinceHeartbeat
+	return status
+}
+
+/* QueryReadiness returns whether the finality gadget serves up to date finality
+ *
+ * - the L2, Babylon and BTC nodes, the CosmWasm contract and the db are checked in parallel, each within
+ *   healthCheckTimeout or until ctx is done. A check still in flight is shared rather than started again, so
+ *   a hung dependency holds a single goroutine however often the readiness is queried
+ * - the processing lag is the number of L2 blocks above the latest finalized block, it must not exceed the
+ *   configured max lag. The L2 head is the one seen by the last successful poll if the L2 node is unreachable
+ * - the last successful poll must be less than readinessMaxPollIntervals poll intervals old
+ * - the block processing loop must be running
+ */
+func (fg *FinalityGadget) QueryReadiness(ctx context.Context) *types.ReadinessStatus {
+	status := &types.ReadinessStatus{
+		MaxProcessingLag:        fg.readinessMaxLag,
+		MaxSecondsSinceLastPoll: (readinessMaxPollIntervals * fg.pollInterval).Seconds(),
+		Failures:                fg.QueryHealthStatus().Failures,
+	}
+
+	// The L2 and db checks return the L2 head and the latest finalized height, the others only their error
+	checks := []struct {
+		name  string
+		check func(ctx context.Context) (uint64, error)
+	}{
+		{types.UpstreamL2, func(ctx context.Context) (uint64, error) {
+			header, err := fg.l2Client.HeaderByNumber(ctx, big.NewInt(ethrpc.LatestBlockNumber.Int64()))
+			if err != nil {
+				return 0, err
+			}
+			return header.Number.Uint64(), nil
+		}},
+		{types.UpstreamBabylon, func(context.Context) (uint64, error) {
+			return fg.bbnClient.QueryLatestHeight()
+		}},
+		{types.UpstreamBitcoin, func(context.Context) (uint64, error) {
+			count, err := fg.btcClient.GetBlockCount()
+			return uint64(count), err
+		}},
+		{types.UpstreamCosmWasm, func(context.Context) (uint64, error) {
+			_, err := fg.cwClient.QueryConsumerId()
+			return 0, err
+		}},
+		{types.DependencyDB, func(context.Context) (uint64, error) {
+			block, err := fg.db.QueryLatestFinalizedBlock()
+			if err != nil || block == nil {
+				return 0, err
+			}
+			return block.BlockHeight, nil
+		}},
+	}
+	status.Dependencies = make([]*types.DependencyStatus, len(checks))
+	heights := make([]uint64, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status.Dependencies[i], heights[i] = fg.checkDependency(ctx, check.name, check.check)
+		}()
+	}
+	wg.Wait()
+
+	status.L2HeadHeight = fg.l2HeadHeight.Load()
+	for i, dependency := range status.Dependencies {
+		switch {
+		case !dependency.Healthy:
+		case dependency.Name == types.UpstreamL2:
+			status.L2HeadHeight = heights[i]
+		case dependency.Name == types.DependencyDB:
+			status.LatestFinalizedHeight = heights[i]
+		}
+	}
+
+	for _, dependency := range status.Dependencies {
+		if !dependency.Healthy {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("%s is unreachable: %s", dependency.Name, dependency.Error))
+		}
+	}
+	if status.L2HeadHeight > status.LatestFinalizedHeight {
+		status.ProcessingLag = status.L2HeadHeight - status.LatestFinalizedHeight
+	}
+	if status.ProcessingLag > status.MaxProcessingLag {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("the latest finalized block lags %d blocks behind the L2 head, more than %d",
+			status.ProcessingLag, status.MaxProcessingLag))
+	}
+
+	if lastPoll := fg.lastPollTime.Load(); lastPoll == 0 {
+		status.Reasons = append(status.Reasons, "the L2 chain wasn't polled successfully yet")
+	} else {
+		status.LastPollTimestamp = uint64(lastPoll / int64(time.Second)) // #nosec G115
+		status.SecondsSinceLastPoll = time.Since(time.Unix(0, lastPoll)).Seconds()
+		if status.SecondsSinceLastPoll > status.MaxSecondsSinceLastPoll {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("the last successful poll of the L2 chain was %.0fs ago",
+				status.SecondsSinceLastPoll))
+		}
+	}
+
+	if !fg.processing.Load() {
+		status.Reasons = append(status.Reasons, "block processing is not running")
+	}
+	status.Ready = len(status.Reasons) == 0
+	return status
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+// markDegraded records a failure of the component, the finality gadget being degraded until it succeeds again
+func (fg *FinalityGadget) markDegraded(component string, err error) {
+	fg.degradedMutex.Lock()
+	defer fg.degradedMutex.Unlock()
+
+	if fg.degraded == nil {
+		fg.degraded = make(map[string]*types.ComponentFailure)
+	}
+	failure, ok := fg.degraded[component]
+	if !ok {
+		failure = &types.ComponentFailure{Component: component, Since: uint64(time.Now().Unix())}
+		fg.degraded[component] = failure
+		fg.logger.Warn("Finality gadget degraded", zap.String("component", component), zap.Error(err))
+	}
+	failure.Error = err.Error()
+	failure.Failures++
+	var upstreamErr *types.UpstreamError
+	if errors.As(err, &upstreamErr) {
+		failure.Upstream = upstreamErr.Upstream
+	}
+	metrics.Degraded.Set(1)
+}
+
+// clearDegraded records a success of the component
+func (fg *FinalityGadget) clearDegraded(component string) {
+	fg.degradedMutex.Lock()
+	defer fg.degradedMutex.Unlock()
+
+	failure, ok := fg.degraded[component]
+	if !ok {
+		return
+	}
+	delete(fg.degraded, component)
+	fg.logger.Info("Finality gadget component recovered",
+		zap.String("component", component),
+		zap.Uint64("failures", failure.Failures))
+	if len(fg.degraded) == 0 {
+		metrics.Degraded.Set(0)
+	}
+}
+
+// beat records that the block processing shows progress, for the liveness
+func (fg *FinalityGadget) beat() {
+	fg.heartbeat.Store(time.Now().UnixNano())
+}
+
+// dependencyCheck is a readiness check of a dependency in flight, shared by the readiness queries made meanwhile
+type dependencyCheck struct {
+	// done is closed once the check returns, with its height and error
+	done   chan struct{}
+	height uint64
+	err    error
+}
+
+/* checkDependency runs the readiness check of the dependency and returns its status and the height it returned
+ *
+ * - the check fails if it takes more than healthCheckTimeout, or if ctx is done first
+ * - the check runs detached from ctx, within healthCheckTimeout for the clients taking a context. The clients
+ *   that don't may keep it running past the timeout, until their own timeouts, in which case the next queries
+ *   wait on it instead of starting another one
+ */
+func (fg *FinalityGadget) checkDependency(
+	ctx context.Context,
+	name string,
+	check func(ctx context.Context) (uint64, error),
+) (*types.DependencyStatus, uint64) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	inFlight := fg.startDependencyCheck(name, check)
+	var height uint64
+	var err error
+	select {
+	case <-inFlight.done:
+		height, err = inFlight.height, inFlight.err
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out: %w", ctx.Err())
+	}
+
+	status := &types.DependencyStatus{
+		Name:      name,
+		Healthy:   err == nil,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status, height
+}
+
+// startDependencyCheck starts the readiness check of the dependency, unless one is already in flight, and returns it
+func (fg *FinalityGadget) startDependencyCheck(name string, check func(ctx context.Context) (uint64, error)) *dependencyCheck {
+	fg.dependencyChecksMutex.Lock()
+	defer fg.dependencyChecksMutex.Unlock()
+
+	if inFlight, ok := fg.dependencyChecks[name]; ok {
+		return inFlight
+	}
+	if fg.dependencyChecks == nil {
+		fg.dependencyChecks = make(map[string]*dependencyCheck)
+	}
+	inFlight := &dependencyCheck{done: make(chan struct{})}
+	fg.dependencyChecks[name] = inFlight
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		defer cancel()
+		inFlight.height, inFlight.err = check(ctx)
+
+		fg.dependencyChecksMutex.Lock()
+		delete(fg.dependencyChecks, name)
+		fg.dependencyChecksMutex.Unlock()
+		close(inFlight.done)
+	}()
+	return inFlight
+}
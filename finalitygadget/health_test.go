@@ -0,0 +1,192 @@
+package finalitygadget
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/testutil/mocks"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	eth "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestQueryReadiness(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	l2HeadHeight := int64(130)
+	mockL2Client := mocks.NewMockIEthL2Client(ctl)
+	mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, *big.Int) (*eth.Header, error) {
+			return &eth.Header{Number: big.NewInt(l2HeadHeight)}, nil
+		}).AnyTimes()
+	mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+	mockBBNClient.EXPECT().QueryLatestHeight().Return(uint64(1000), nil).AnyTimes()
+	mockCwClient := mocks.NewMockICosmWasmClient(ctl)
+	mockCwClient.EXPECT().QueryConsumerId().Return("consumer-chain-id", nil).AnyTimes()
+	btcErr := errors.New("connection refused")
+	btcDown := false
+	mockBTCClient := mocks.NewMockIBitcoinClient(ctl)
+	mockBTCClient.EXPECT().GetBlockCount().DoAndReturn(func() (uint32, error) {
+		if btcDown {
+			return 0, btcErr
+		}
+		return 800000, nil
+	}).AnyTimes()
+
+	fg := &FinalityGadget{
+		l2Client:        mockL2Client,
+		bbnClient:       mockBBNClient,
+		cwClient:        mockCwClient,
+		btcClient:       mockBTCClient,
+		db:              setupRetentionDB(t, 10, 30, time.Now()),
+		logger:          zap.NewNop(),
+		pollInterval:    time.Second,
+		readinessMaxLag: 100,
+		startTime:       time.Now(),
+	}
+
+	// Not ready until block processing runs and polls the L2 chain
+	require.False(t, fg.QueryLiveness().Alive)
+	status := fg.QueryReadiness(context.Background())
+	require.False(t, status.Ready)
+	require.Len(t, status.Reasons, 2)
+	require.Len(t, status.Dependencies, 5)
+	for _, dependency := range status.Dependencies {
+		require.True(t, dependency.Healthy, dependency.Name)
+	}
+	require.Equal(t, uint64(130), status.L2HeadHeight)
+	require.Equal(t, uint64(30), status.LatestFinalizedHeight)
+	require.Equal(t, uint64(100), status.ProcessingLag)
+
+	fg.processing.Store(true)
+	fg.beat()
+	fg.lastPollTime.Store(time.Now().UnixNano())
+	require.True(t, fg.QueryLiveness().Alive)
+	status = fg.QueryReadiness(context.Background())
+	require.True(t, status.Ready, status.Reasons)
+	require.Empty(t, status.Reasons)
+
+	// Lagging more than the max lag
+	l2HeadHeight = 131
+	status = fg.QueryReadiness(context.Background())
+	require.False(t, status.Ready)
+	require.Equal(t, uint64(101), status.ProcessingLag)
+	require.Len(t, status.Reasons, 1)
+	l2HeadHeight = 130
+
+	// An unreachable dependency
+	btcDown = true
+	status = fg.QueryReadiness(context.Background())
+	require.False(t, status.Ready)
+	require.Len(t, status.Reasons, 1)
+	for _, dependency := range status.Dependencies {
+		require.Equal(t, dependency.Name != types.UpstreamBitcoin, dependency.Healthy, dependency.Name)
+		if dependency.Name == types.UpstreamBitcoin {
+			require.Equal(t, btcErr.Error(), dependency.Error)
+		}
+	}
+	btcDown = false
+
+	// A stale poll
+	fg.lastPollTime.Store(time.Now().Add(-time.Minute).UnixNano())
+	status = fg.QueryReadiness(context.Background())
+	require.False(t, status.Ready)
+	require.Len(t, status.Reasons, 1)
+	require.InDelta(t, 60, status.SecondsSinceLastPoll, 5)
+}
+
+func TestQueryLiveness(t *testing.T) {
+	fg := &FinalityGadget{
+		logger:       zap.NewNop(),
+		pollInterval: time.Minute,
+		startTime:    time.Now(),
+	}
+
+	// Not alive until block processing runs and beats
+	status := fg.QueryLiveness()
+	require.False(t, status.Alive)
+	require.Zero(t, status.LastHeartbeatTimestamp)
+	require.Equal(t, (livenessMaxPollIntervals * time.Minute).Seconds(), status.MaxSecondsSinceHeartbeat)
+
+	fg.processing.Store(true)
+	fg.beat()
+	require.True(t, fg.QueryLiveness().Alive)
+
+	// A processing loop hung for more than the max poll intervals isn't alive
+	fg.heartbeat.Store(time.Now().Add(-livenessMaxPollIntervals*time.Minute - time.Second).UnixNano())
+	status = fg.QueryLiveness()
+	require.False(t, status.Alive)
+	require.Greater(t, status.SecondsSinceHeartbeat, status.MaxSecondsSinceHeartbeat)
+
+	// Short poll intervals still leave room for the retries backoff
+	fg.pollInterval = time.Second
+	fg.heartbeat.Store(time.Now().Add(-time.Minute).UnixNano())
+	require.True(t, fg.QueryLiveness().Alive)
+}
+
+func TestQueryReadinessHungDependency(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	mockL2Client := mocks.NewMockIEthL2Client(ctl)
+	mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).
+		Return(&eth.Header{Number: big.NewInt(130)}, nil).AnyTimes()
+	mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+	mockBBNClient.EXPECT().QueryLatestHeight().Return(uint64(1000), nil).AnyTimes()
+	mockCwClient := mocks.NewMockICosmWasmClient(ctl)
+	mockCwClient.EXPECT().QueryConsumerId().Return("consumer-chain-id", nil).AnyTimes()
+	// the BTC node hangs until released, ignoring any timeout
+	release := make(chan struct{})
+	var calls atomic.Int32
+	mockBTCClient := mocks.NewMockIBitcoinClient(ctl)
+	mockBTCClient.EXPECT().GetBlockCount().DoAndReturn(func() (uint32, error) {
+		calls.Add(1)
+		<-release
+		return 800000, nil
+	}).AnyTimes()
+
+	fg := &FinalityGadget{
+		l2Client:        mockL2Client,
+		bbnClient:       mockBBNClient,
+		cwClient:        mockCwClient,
+		btcClient:       mockBTCClient,
+		db:              setupRetentionDB(t, 10, 30, time.Now()),
+		logger:          zap.NewNop(),
+		pollInterval:    time.Second,
+		readinessMaxLag: 100,
+		startTime:       time.Now(),
+	}
+
+	// Queries made while the check hangs time out with ctx, and share the check in flight
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		status := fg.QueryReadiness(ctx)
+		cancel()
+		require.False(t, status.Ready)
+		for _, dependency := range status.Dependencies {
+			require.Equal(t, dependency.Name != types.UpstreamBitcoin, dependency.Healthy, dependency.Name)
+		}
+		require.Equal(t, uint64(130), status.L2HeadHeight)
+	}
+	require.Equal(t, int32(1), calls.Load())
+
+	// Once it returns, the next query starts a new check
+	close(release)
+	require.Eventually(t, func() bool {
+		fg.dependencyChecksMutex.Lock()
+		defer fg.dependencyChecksMutex.Unlock()
+		return len(fg.dependencyChecks) == 0
+	}, time.Second, 10*time.Millisecond)
+	status := fg.QueryReadiness(context.Background())
+	for _, dependency := range status.Dependencies {
+		require.True(t, dependency.Healthy, dependency.Name)
+	}
+	require.Equal(t, int32(2), calls.Load())
+}
@@ -1,6 +1,10 @@
 package finalitygadget
 
-import "github.com/babylonlabs-io/finality-gadget/types"
+import (
+	"context"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+)
 
 type IFinalityGadget interface {
 	// TODO: make this method internal once fully tested. External services should query the database instead.
@@ -12,13 +16,17 @@ type IFinalityGadget interface {
 	 *
 	 * - to check if the block is finalized, we need to:
 	 *   - get the consumer chain id
-	 *   - get all the FPs pubkey for the consumer chain
+	 *   - convert the L2 block timestamp to Babylon height
+	 *   - get all the FPs pubkey for the consumer chain at this Babylon height
+	 *   - keep only the FPs in the contract allow-list at this Babylon height
 	 *   - convert the L2 block timestamp to BTC height
 	 *   - get all FPs voting power at this BTC height
+	 *   - zero the voting power of FPs without timestamped public randomness for this height
 	 *   - calculate total voting power
 	 *   - get all FPs that voted this L2 block with the same height and hash
+	 *   - if enabled, drop votes whose EOTS signature fails local verification
 	 *   - calculate voted voting power
-	 *   - check if the voted voting power is more than 2/3 of the total voting power
+	 *   - check if the voted voting power reaches the quorum (2/3 of the total voting power by default)
 	 */
 	QueryIsBlockBabylonFinalizedFromBabylon(block *types.Block) (bool, error)
 
@@ -65,6 +73,15 @@ type IFinalityGadget interface {
 	// GetBlockByHash returns the btc finalized block at given hash by querying the local db
 	GetBlockByHash(hash string) (*types.Block, error)
 
+	// GetFinalityEvidenceByHeight returns the finality evidence of the btc finalized block at given height by querying the local db
+	GetFinalityEvidenceByHeight(height uint64) (*types.FinalityEvidence, error)
+
+	// GetFinalityEvidenceByHash returns the finality evidence of the btc finalized block at given hash by querying the local db
+	GetFinalityEvidenceByHash(hash string) (*types.FinalityEvidence, error)
+
+	// QueryFinalityProof returns a self-contained proof bundle that the btc finalized block at given height is finalized
+	QueryFinalityProof(height uint64) (*types.FinalityProof, error)
+
 	// QueryIsBlockFinalizedByHeight returns the btc finalization status of a block at given height by querying the local db
 	QueryIsBlockFinalizedByHeight(height uint64) (bool, error)
 
@@ -74,9 +91,38 @@ type IFinalityGadget interface {
 	// QueryLatestFinalizedBlock returns the latest finalized block by querying the local db
 	QueryLatestFinalizedBlock() (*types.Block, error)
 
+	// ListFinalizedBlocks returns a page of the btc finalized blocks matching the query by querying the local db,
+	// resuming the listing at the cursor returned with the previous page
+	ListFinalizedBlocks(query types.BlockQuery, cursor string) (*types.BlockPage, error)
+
+	/* SubscribeFinalizedBlocks streams finalized blocks to send, starting at the given height
+	 *
+	 * - first replays the finalized blocks stored in the db from the given height
+	 * - then sends new blocks as they are inserted, until ctx is done or send fails
+	 * - if blocks already sent are rolled back due to an L2 reorg, the stream resumes from the fork height
+	 */
+	SubscribeFinalizedBlocks(ctx context.Context, fromHeight uint64, send func(*types.Block) error) error
+
+	// SubscribeFinalityEvents is SubscribeFinalizedBlocks, but also reports rollbacks of blocks already sent as rollback events
+	SubscribeFinalityEvents(ctx context.Context, fromHeight uint64, send func(*types.FinalityEvent) error) error
+
 	// QueryTransactionStatus returns the finality status of a transaction
 	QueryTransactionStatus(txHash string) (*types.TransactionInfo, error)
 
 	// QueryChainSyncStatus returns the latest finalized blocks for display by the finality explorer
 	QueryChainSyncStatus() (*types.ChainSyncStatus, error)
+
+	// QueryHealthStatus returns whether the finality gadget is degraded, and which of its components keep failing
+	QueryHealthStatus() *types.HealthStatus
+
+	// QueryLiveness returns whether the finality gadget is running, regardless of its dependencies
+	QueryLiveness() *types.LivenessStatus
+
+	/* QueryReadiness returns whether the finality gadget serves up to date finality
+	 *
+	 * - its dependencies (L2, Babylon and BTC nodes, CosmWasm contract and db) must be reachable
+	 * - the latest finalized block must lag at most the configured max lag behind the L2 head
+	 * - the L2 chain must have been polled successfully recently, and block processing must be running
+	 */
+	QueryReadiness(ctx context.Context) *types.ReadinessStatus
 }
@@ -0,0 +1,383 @@
+package finalitygadget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/metrics"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"go.uber.org/zap"
+)
+
+const (
+	// processingLookahead is how many heights per worker can be dispatched ahead of the commit cursor
+	processingLookahead = 2
+	// processingRetryInitialBackoff is the delay before retrying a failed stage for the first time
+	processingRetryInitialBackoff = 500 * time.Millisecond
+	// processingRetryMaxBackoff caps the delay between two retries of a failed stage, before jitter
+	processingRetryMaxBackoff = 30 * time.Second
+)
+
+// Stages of the block processing pipeline, as labelled in the metrics
+const (
+	stageFetch      = "fetch"
+	stageEvaluate   = "evaluate"
+	stageReorgCheck = "reorg_check"
+	stageCommit     = "commit"
+	// stageStartup resolves the height to start processing from, before the pipeline runs
+	stageStartup = "startup"
+)
+
+// errPipelineStopped is returned by the stages still running when the pipeline stops
+var errPipelineStopped = errors.New("block processing pipeline stopped")
+
+// heightResult is the evaluation of a height, block being nil if it isn't finalized
+type heightResult struct {
+	height uint64
+	block  *types.Block
+	err    error
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+/* processBlocksTillHeight evaluates the heights at finality signature intervals up to latestHeight with a pool
+ * of workers, and commits the finalized blocks strictly in height order
+ *
+ * - a dispatcher hands the heights above the commit cursor to fg.concurrency workers, staying at most
+ *   processingLookahead heights per worker ahead of the cursor, so the RPC load and memory stay bounded
+ * - each worker fetches the L2 header and evaluates the quorum, retrying failed stages with backoff, except on
+ *   permanent upstream errors which are returned once their height is next to commit
+ * - results are committed as soon as they are contiguous with the cursor, in batches of up to fg.batchSize
+ *   blocks, each batch atomically with the processing checkpoint
+ * - the stored finalized blocks are checked to still be canonical before dispatching, so an L2 reorg is rolled
+ *   back at the next poll even if no new block finalizes, processing then resuming from the rolled back cursor
+ * - the first height not finalized yet stops the pipeline until the next poll, as does an L2 reorg under
+ *   the cursor while committing
+ * - once every interval height up to latestHeight is committed, the checkpoint moves to latestHeight
+ */
+func (fg *FinalityGadget) processBlocksTillHeight(ctx context.Context, latestHeight uint64) error {
+	fg.logger.Debug("Processing blocks till height", zap.Uint64("height", latestHeight))
+
+	// Make sure the stored finalized blocks are still canonical before building on top of them
+	err := fg.retryStage(ctx, nil, stageReorgCheck, fg.lastProcessedHeight.Load(), func() error {
+		if _, err := fg.checkAndHandleReorg(ctx); err != nil {
+			return fmt.Errorf("error checking for L2 reorg: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	interval := fg.contractConfig.FinalitySignatureInterval
+	concurrency := max(fg.concurrency, 1)
+	lookahead := concurrency * processingLookahead
+
+	// stop is closed when the committer returns, to stop the dispatcher and the workers
+	stop := make(chan struct{})
+	jobs := make(chan uint64)
+	// results never blocks a worker, as at most lookahead heights are dispatched and not committed
+	results := make(chan heightResult, lookahead)
+	// slots holds a token per height dispatched and not committed yet
+	slots := make(chan struct{}, lookahead)
+
+	var wg sync.WaitGroup
+	defer func() {
+		close(stop)
+		wg.Wait()
+		metrics.ProcessingQueueDepth.Set(0)
+	}()
+
+	firstHeight := fg.nextIntervalHeight(fg.lastProcessedHeight.Load() + 1)
+
+	// Dispatch the interval heights in order, as slots free up
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for height := firstHeight; height <= latestHeight; height += interval {
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			}
+			metrics.ProcessingQueueDepth.Set(float64(len(slots)))
+			select {
+			case jobs <- height:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	// Evaluate the dispatched heights
+	for i := uint64(0); i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for height := range jobs {
+				block, err := fg.evaluateHeight(ctx, stop, height)
+				if errors.Is(err, errPipelineStopped) || ctx.Err() != nil {
+					return
+				}
+				results <- heightResult{height: height, block: block, err: err}
+			}
+		}()
+	}
+
+	// Commit the results in height order
+	pending := make(map[uint64]heightResult)
+	var batch []*types.Block
+	for next := firstHeight; next <= latestHeight; {
+		select {
+		case <-ctx.Done():
+			fg.logger.Debug("Exiting block processing loop...")
+			return nil
+		case result := <-results:
+			fg.beat()
+			pending[result.height] = result
+		}
+
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			<-slots
+			metrics.ProcessingQueueDepth.Set(float64(len(slots)))
+			if result.err != nil {
+				return result.err
+			}
+
+			// If a height we should check is not finalized, stop processing until the next poll
+			if result.block == nil {
+				fg.logger.Debug("Block at required height not finalized, stopping", zap.Uint64("height", next))
+				if committed, err := fg.commitBlocks(ctx, batch); err != nil || !committed {
+					return err
+				}
+				return fg.saveCheckpoint(fg.lastProcessedHeight.Load(), types.CheckpointNotFinalized)
+			}
+
+			batch = append(batch, result.block)
+			next += interval
+			if uint64(len(batch)) >= fg.batchSize {
+				if committed, err := fg.commitBlocks(ctx, batch); err != nil || !committed {
+					return err
+				}
+				batch = nil
+			}
+		}
+
+		// Commit what is contiguous with the cursor rather than waiting for a full batch
+		if committed, err := fg.commitBlocks(ctx, batch); err != nil || !committed {
+			return err
+		}
+		batch = nil
+	}
+
+	// No height up to latestHeight is left to evaluate
+	if fg.lastProcessedHeight.Load() < latestHeight {
+		if err := fg.saveCheckpoint(latestHeight, types.CheckpointSkipped); err != nil {
+			return err
+		}
+		fg.lastProcessedHeight.Store(latestHeight)
+	}
+	return nil
+}
+
+// commitBlocks stores the finalized blocks atomically with the checkpoint at the last one, after checking that the
+// stored blocks they build on and the blocks themselves are still canonical. It returns false instead if an L2
+// reorg rolled back the db, if a block of the batch was reorged out since it was evaluated, or if ctx is done,
+// and fails on db and permanent upstream errors.
+func (fg *FinalityGadget) commitBlocks(ctx context.Context, blocks []*types.Block) (bool, error) {
+	if len(blocks) == 0 {
+		return true, nil
+	}
+	start := time.Now()
+
+	// Make sure the stored finalized blocks are still canonical before building on top of them, and that the
+	// batch is on the same chain, as its blocks were fetched before the check
+	var reorged, canonical bool
+	err := fg.retryStage(ctx, nil, stageReorgCheck, blocks[0].BlockHeight, func() error {
+		var err error
+		reorged, err = fg.checkAndHandleReorg(ctx)
+		if err != nil {
+			return fmt.Errorf("error checking for L2 reorg: %w", err)
+		}
+		if reorged {
+			return nil
+		}
+		canonical, err = fg.isBatchCanonical(ctx, blocks)
+		if err != nil {
+			return fmt.Errorf("error checking blocks are canonical: %w", err)
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return false, err
+	}
+	if err != nil || reorged || !canonical {
+		return false, nil
+	}
+
+	lastHeight := blocks[len(blocks)-1].BlockHeight
+	fg.logger.Debug("Inserting finalized blocks", zap.Uint64("start_height", blocks[0].BlockHeight), zap.Uint64("end_height", lastHeight))
+	if err := fg.insertBlocks(blocks, newCheckpoint(lastHeight, types.CheckpointFinalized)); err != nil {
+		return false, fmt.Errorf("error storing blocks: %w", err)
+	}
+	fg.lastProcessedHeight.Store(lastHeight)
+	fg.lastFinalizedTime.Store(time.Now().UnixNano())
+
+	metrics.LatestFinalizedBlockHeight.Set(float64(lastHeight))
+	metrics.ProcessingStageDuration.WithLabelValues(stageCommit).Observe(time.Since(start).Seconds())
+	return true, nil
+}
+
+// isBatchCanonical checks that the blocks still match the canonical L2 chain, so that the batch is hash-contiguous
+// with the stored tip the reorg check just verified
+func (fg *FinalityGadget) isBatchCanonical(ctx context.Context, blocks []*types.Block) (bool, error) {
+	for _, block := range blocks {
+		if block.BlockHeight > math.MaxInt64 {
+			return false, fmt.Errorf("block height %d exceeds maximum int64 value", block.BlockHeight)
+		}
+		header, err := fg.l2Client.HeaderByNumber(ctx, big.NewInt(int64(block.BlockHeight)))
+		if err != nil {
+			return false, fmt.Errorf("error getting block at height %d: %w", block.BlockHeight, err)
+		}
+		if normalizeBlockHash(header.Hash().Hex()) != normalizeBlockHash(block.BlockHash) {
+			fg.logger.Warn("Block reorged out since it was evaluated, re-evaluating from the last committed block",
+				zap.Uint64("block_height", block.BlockHeight),
+				zap.String("block_hash", block.BlockHash),
+				zap.String("canonical_block_hash", header.Hash().Hex()))
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// evaluateHeight fetches the L2 block at height and checks its finality on Babylon, retrying each stage until
+// it succeeds or the pipeline stops. It returns the block if it is finalized, nil otherwise.
+func (fg *FinalityGadget) evaluateHeight(ctx context.Context, stop <-chan struct{}, height uint64) (*types.Block, error) {
+	if height > math.MaxInt64 {
+		return nil, fmt.Errorf("block height %d exceeds maximum int64 value", height)
+	}
+
+	var block *types.Block
+	err := fg.retryStage(ctx, stop, stageFetch, height, func() error {
+		var err error
+		block, err = fg.queryBlockByHeight(int64(height))
+		if err != nil {
+			return fmt.Errorf("error getting block at height %d: %w", height, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	fg.logger.Debug("Fetched block", zap.Uint64("block_height", height), zap.String("block_hash", block.BlockHash))
+
+	var isFinalized bool
+	err = fg.retryStage(ctx, stop, stageEvaluate, height, func() error {
+		var err error
+		isFinalized, err = fg.QueryIsBlockBabylonFinalizedFromBabylon(block)
+		// The FP set can gain voting power later, eg. once FPs are allow-listed, so the block is reported as not
+		// finalized and evaluated again at the next poll
+		if errors.Is(err, types.ErrNoFpHasVotingPower) {
+			fg.markDegraded(componentFpSet, fmt.Errorf("block %d: %w", height, err))
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error checking is block %d finalized from babylon: %w", height, err)
+		}
+		fg.clearDegraded(componentFpSet)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	fg.logger.Debug("Fetched block finality status", zap.Uint64("block_height", height), zap.Bool("is_finalized", isFinalized))
+
+	if !isFinalized {
+		return nil, nil
+	}
+	return block, nil
+}
+
+/* retryStage runs a stage of the pipeline for the height until it succeeds
+ *
+ * - transient errors are retried, doubling the delay between attempts up to processingRetryMaxBackoff, with a
+ *   random jitter so the workers don't hammer a recovering upstream all at once
+ * - the stage is reported as degraded until it succeeds
+ * - it fails on permanent upstream errors, which retrying can't fix, and if ctx is done or the pipeline stops
+ *   first, a nil stop never stopping it
+ */
+func (fg *FinalityGadget) retryStage(ctx context.Context, stop <-chan struct{}, stage string, height uint64, run func() error) error {
+	backoff := processingRetryInitialBackoff
+	for attempt := 1; ; attempt++ {
+		fg.beat()
+		start := time.Now()
+		err := run()
+		metrics.ProcessingStageDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+		if err == nil {
+			fg.clearDegraded(stage)
+			return nil
+		}
+		fg.markDegraded(stage, err)
+
+		if types.IsPermanentError(err) {
+			fg.logger.Error("Block processing stage failed with a permanent error",
+				zap.String("stage", stage),
+				zap.Uint64("block_height", height),
+				zap.Error(err))
+			return err
+		}
+
+		delay := jitter(backoff)
+		metrics.ProcessingRetriesTotal.WithLabelValues(stage).Inc()
+		fg.logger.Warn("Block processing stage failed, retrying",
+			zap.String("stage", stage),
+			zap.Uint64("block_height", height),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", delay),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-stop:
+			return errPipelineStopped
+		case <-time.After(delay):
+		}
+		backoff = min(2*backoff, processingRetryMaxBackoff)
+	}
+}
+
+// jitter returns a random delay between half the backoff and the backoff
+func jitter(backoff time.Duration) time.Duration {
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// nextIntervalHeight returns the first height at or above height that is at a finality signature interval
+func (fg *FinalityGadget) nextIntervalHeight(height uint64) uint64 {
+	activationHeight := fg.contractConfig.BsnActivationHeight
+	interval := fg.contractConfig.FinalitySignatureInterval
+	if height <= activationHeight {
+		return activationHeight
+	}
+	if offset := (height - activationHeight) % interval; offset != 0 {
+		return height + interval - offset
+	}
+	return height
+}
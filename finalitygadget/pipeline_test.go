@@ -0,0 +1,527 @@
+package finalitygadget
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/db"
+	"github.com/babylonlabs-io/finality-gadget/testutil/mocks"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	eth "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestProcessBlocksTillHeight(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// a linked L2 chain, so the reorg check before each commit passes
+	headers := make([]*eth.Header, 30)
+	for height := range headers {
+		headers[height] = &eth.Header{Number: big.NewInt(int64(height)), Time: uint64(1000 + height)}
+		if height > 0 {
+			headers[height].ParentHash = headers[height-1].Hash()
+		}
+	}
+
+	// the first fetch of height 6 fails, and is retried
+	var mu sync.Mutex
+	failedFetch := false
+	mockL2Client := mocks.NewMockIEthL2Client(ctl)
+	mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, number *big.Int) (*eth.Header, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if number.Int64() == 6 && !failedFetch {
+				failedFetch = true
+				return nil, errors.New("connection reset")
+			}
+			return headers[number.Int64()], nil
+		}).AnyTimes()
+
+	// blocks are finalized up to finalizedHeight
+	finalizedHeight := uint64(14)
+	mockCwClient := mocks.NewMockICosmWasmClient(ctl)
+	mockCwClient.EXPECT().QueryConsumerId().Return("consumer-chain-id", nil).AnyTimes()
+	mockCwClient.EXPECT().QueryAllowedFinalityProviders(uint64(100)).Return([]string{"pk1"}, nil).AnyTimes()
+	mockCwClient.EXPECT().QueryPubRandCommitForHeight("pk1", gomock.Any()).
+		Return(&types.PubRandCommit{StartHeight: 1, NumPubRand: 100, BabylonHeight: 100}, nil).AnyTimes()
+	mockCwClient.EXPECT().QueryBlockVoters(gomock.Any()).DoAndReturn(
+		func(block *types.Block) ([]*types.BlockVoter, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if block.BlockHeight > finalizedHeight {
+				return nil, nil
+			}
+			return []*types.BlockVoter{{FpBtcPkHex: "pk1"}}, nil
+		}).AnyTimes()
+	mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+	mockBBNClient.EXPECT().QueryBabylonHeightByTimestamp(gomock.Any()).Return(uint64(100), nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryAllFpBtcPubKeys("consumer-chain-id", uint64(100)).Return([]string{"pk1"}, nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryMultiFpPower([]string{"pk1"}, uint32(50)).Return(map[string]uint64{"pk1": 100}, nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryEpochInterval().Return(uint64(10), nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryLastFinalizedEpoch().Return(uint64(10), nil).AnyTimes()
+	mockBTCClient := mocks.NewMockIBitcoinClient(ctl)
+	mockBTCClient.EXPECT().GetBlockHeightByTimestamp(gomock.Any()).Return(uint32(50), nil).AnyTimes()
+
+	dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	require.NoError(t, err)
+	defer dbHandler.Close()
+	require.NoError(t, dbHandler.CreateInitialSchema())
+
+	fpPowerCache, err := newFpPowerCache(fpPowerCacheSize, fpPowerCacheTTL)
+	require.NoError(t, err)
+	fg := &FinalityGadget{
+		l2Client:       mockL2Client,
+		cwClient:       mockCwClient,
+		bbnClient:      mockBBNClient,
+		btcClient:      mockBTCClient,
+		db:             dbHandler,
+		logger:         zap.NewNop(),
+		batchSize:      2,
+		concurrency:    3,
+		fpPowerCache:   fpPowerCache,
+		contractConfig: &types.ContractConfig{BsnActivationHeight: 2, FinalitySignatureInterval: 2},
+	}
+
+	// Heights 2 to 14 are committed in order, and processing stops at 16
+	require.NoError(t, fg.processBlocksTillHeight(context.Background(), 20))
+	require.True(t, failedFetch)
+	require.Equal(t, uint64(14), fg.lastProcessedHeight.Load())
+	require.NotZero(t, fg.lastFinalizedTime.Load())
+	blocks, err := dbHandler.ListBlocks(types.BlockQuery{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, blocks, 7)
+	for i, block := range blocks {
+		require.Equal(t, uint64(2+2*i), block.BlockHeight)
+		evidence, err := dbHandler.GetEvidenceByHeight(block.BlockHeight)
+		require.NoError(t, err)
+		require.Equal(t, uint64(100), evidence.VotedPower)
+	}
+	checkpoint, err := dbHandler.GetProcessingCheckpoint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(14), checkpoint.Height)
+	require.Equal(t, types.CheckpointNotFinalized, checkpoint.Outcome)
+
+	// Once finalized, the remaining heights are committed
+	mu.Lock()
+	finalizedHeight = 20
+	mu.Unlock()
+	require.NoError(t, fg.processBlocksTillHeight(context.Background(), 20))
+	require.Equal(t, uint64(20), fg.lastProcessedHeight.Load())
+	checkpoint, err = dbHandler.GetProcessingCheckpoint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), checkpoint.Height)
+	require.Equal(t, types.CheckpointFinalized, checkpoint.Outcome)
+
+	// Heights between two finality signature intervals are skipped
+	require.NoError(t, fg.processBlocksTillHeight(context.Background(), 21))
+	require.Equal(t, uint64(21), fg.lastProcessedHeight.Load())
+	checkpoint, err = dbHandler.GetProcessingCheckpoint()
+	require.NoError(t, err)
+	require.Equal(t, types.CheckpointSkipped, checkpoint.Outcome)
+	latest, err := dbHandler.QueryLatestFinalizedBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), latest.BlockHeight)
+}
+
+func TestProcessBlocksWithoutVotingPower(t *testing.T) {
+	headers := make([]*eth.Header, 10)
+	for height := range headers {
+		headers[height] = &eth.Header{Number: big.NewInt(int64(height)), Time: uint64(1000 + height)}
+		if height > 0 {
+			headers[height].ParentHash = headers[height-1].Hash()
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		power        uint64
+		allowedFpPks []string
+	}{
+		{name: "FP without delegations", power: 0, allowedFpPks: []string{"pk1"}},
+		{name: "empty allow-list", power: 100, allowedFpPks: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			mockL2Client := mocks.NewMockIEthL2Client(ctl)
+			mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, number *big.Int) (*eth.Header, error) {
+					return headers[number.Int64()], nil
+				}).AnyTimes()
+
+			// the FP set has no voting power until the FP gets delegations and is allow-listed
+			var mu sync.Mutex
+			power, allowedFpPks := tc.power, tc.allowedFpPks
+			mockCwClient := mocks.NewMockICosmWasmClient(ctl)
+			mockCwClient.EXPECT().QueryConsumerId().Return("consumer-chain-id", nil).AnyTimes()
+			mockCwClient.EXPECT().QueryAllowedFinalityProviders(uint64(100)).DoAndReturn(
+				func(uint64) ([]string, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					return allowedFpPks, nil
+				}).AnyTimes()
+			mockCwClient.EXPECT().QueryPubRandCommitForHeight("pk1", gomock.Any()).
+				Return(&types.PubRandCommit{StartHeight: 1, NumPubRand: 100, BabylonHeight: 100}, nil).AnyTimes()
+			mockCwClient.EXPECT().QueryBlockVoters(gomock.Any()).Return([]*types.BlockVoter{{FpBtcPkHex: "pk1"}}, nil).AnyTimes()
+			mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+			mockBBNClient.EXPECT().QueryBabylonHeightByTimestamp(gomock.Any()).Return(uint64(100), nil).AnyTimes()
+			mockBBNClient.EXPECT().QueryAllFpBtcPubKeys("consumer-chain-id", uint64(100)).Return([]string{"pk1"}, nil).AnyTimes()
+			mockBBNClient.EXPECT().QueryMultiFpPower([]string{"pk1"}, uint32(50)).DoAndReturn(
+				func([]string, uint32) (map[string]uint64, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					return map[string]uint64{"pk1": power}, nil
+				}).AnyTimes()
+			mockBBNClient.EXPECT().QueryEpochInterval().Return(uint64(10), nil).AnyTimes()
+			mockBBNClient.EXPECT().QueryLastFinalizedEpoch().Return(uint64(10), nil).AnyTimes()
+			mockBTCClient := mocks.NewMockIBitcoinClient(ctl)
+			mockBTCClient.EXPECT().GetBlockHeightByTimestamp(gomock.Any()).Return(uint32(50), nil).AnyTimes()
+
+			dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+			require.NoError(t, err)
+			defer dbHandler.Close()
+			require.NoError(t, dbHandler.CreateInitialSchema())
+
+			fg := &FinalityGadget{
+				l2Client:       mockL2Client,
+				cwClient:       mockCwClient,
+				bbnClient:      mockBBNClient,
+				btcClient:      mockBTCClient,
+				db:             dbHandler,
+				logger:         zap.NewNop(),
+				batchSize:      2,
+				concurrency:    2,
+				contractConfig: &types.ContractConfig{BsnActivationHeight: 2, FinalitySignatureInterval: 2},
+			}
+
+			// Without voting power, no block is finalized and the FP set is reported as degraded, but processing
+			// goes on
+			require.NoError(t, fg.processBlocksTillHeight(context.Background(), 8))
+			require.Equal(t, uint64(0), fg.lastProcessedHeight.Load())
+			checkpoint, err := dbHandler.GetProcessingCheckpoint()
+			require.NoError(t, err)
+			require.Equal(t, types.CheckpointNotFinalized, checkpoint.Outcome)
+			status := fg.QueryHealthStatus()
+			require.True(t, status.Degraded)
+			require.Equal(t, componentFpSet, status.Failures[0].Component)
+
+			// Once the FP set has voting power, the blocks are finalized at the next poll
+			mu.Lock()
+			power, allowedFpPks = 100, []string{"pk1"}
+			mu.Unlock()
+			require.NoError(t, fg.processBlocksTillHeight(context.Background(), 8))
+			require.Equal(t, uint64(8), fg.lastProcessedHeight.Load())
+			require.False(t, fg.QueryHealthStatus().Degraded)
+		})
+	}
+}
+
+func TestProcessBlocksUntimestampedPubRand(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	headers := make([]*eth.Header, 10)
+	for height := range headers {
+		headers[height] = &eth.Header{Number: big.NewInt(int64(height)), Time: uint64(1000 + height)}
+		if height > 0 {
+			headers[height].ParentHash = headers[height-1].Hash()
+		}
+	}
+	mockL2Client := mocks.NewMockIEthL2Client(ctl)
+	mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, number *big.Int) (*eth.Header, error) {
+			// the latest block
+			if number.Sign() < 0 {
+				return headers[len(headers)-1], nil
+			}
+			return headers[number.Int64()], nil
+		}).AnyTimes()
+
+	// the FP commitment is submitted in epoch 10, which isn't finalized on BTC yet
+	var mu sync.Mutex
+	lastFinalizedEpoch := uint64(9)
+	mockCwClient := mocks.NewMockICosmWasmClient(ctl)
+	mockCwClient.EXPECT().QueryConsumerId().Return("consumer-chain-id", nil).AnyTimes()
+	mockCwClient.EXPECT().QueryAllowedFinalityProviders(uint64(100)).Return([]string{"pk1"}, nil).AnyTimes()
+	mockCwClient.EXPECT().QueryPubRandCommitForHeight("pk1", gomock.Any()).
+		Return(&types.PubRandCommit{StartHeight: 1, NumPubRand: 100, BabylonHeight: 100}, nil).AnyTimes()
+	mockCwClient.EXPECT().QueryBlockVoters(gomock.Any()).Return([]*types.BlockVoter{{FpBtcPkHex: "pk1"}}, nil).AnyTimes()
+	mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+	mockBBNClient.EXPECT().QueryBabylonHeightByTimestamp(gomock.Any()).Return(uint64(100), nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryAllFpBtcPubKeys("consumer-chain-id", uint64(100)).Return([]string{"pk1"}, nil).AnyTimes()
+	// the power table is zeroed for FPs without timestamped public randomness, so each call returns a new one
+	mockBBNClient.EXPECT().QueryMultiFpPower([]string{"pk1"}, uint32(50)).DoAndReturn(
+		func([]string, uint32) (map[string]uint64, error) {
+			return map[string]uint64{"pk1": 100}, nil
+		}).AnyTimes()
+	mockBBNClient.EXPECT().QueryEpochInterval().Return(uint64(10), nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryLastFinalizedEpoch().DoAndReturn(func() (uint64, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return lastFinalizedEpoch, nil
+	}).AnyTimes()
+	mockBTCClient := mocks.NewMockIBitcoinClient(ctl)
+	mockBTCClient.EXPECT().GetBlockHeightByTimestamp(gomock.Any()).Return(uint32(50), nil).AnyTimes()
+
+	dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	require.NoError(t, err)
+	defer dbHandler.Close()
+	require.NoError(t, dbHandler.CreateInitialSchema())
+
+	fg := &FinalityGadget{
+		l2Client:       mockL2Client,
+		cwClient:       mockCwClient,
+		bbnClient:      mockBBNClient,
+		btcClient:      mockBTCClient,
+		db:             dbHandler,
+		logger:         zap.NewNop(),
+		pollInterval:   10 * time.Millisecond,
+		batchSize:      2,
+		concurrency:    2,
+		contractConfig: &types.ContractConfig{BsnActivationHeight: 2, FinalitySignatureInterval: 2},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- fg.ProcessBlocks(ctx)
+	}()
+
+	// The daemon keeps polling without finalizing any block, nor being degraded
+	require.Never(t, func() bool { return len(done) > 0 }, 200*time.Millisecond, 10*time.Millisecond)
+	require.True(t, fg.processing.Load())
+	require.Equal(t, uint64(0), fg.lastProcessedHeight.Load())
+	require.False(t, fg.QueryHealthStatus().Degraded)
+
+	// Once the commitment is timestamped, the blocks are finalized
+	mu.Lock()
+	lastFinalizedEpoch = 10
+	mu.Unlock()
+	require.Eventually(t, func() bool { return fg.lastProcessedHeight.Load() == 9 }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestProcessBlocksReorgWithoutNewFinalizedBlock(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// chain B forks from chain A above height 2
+	newChain := func(seed uint64) []*eth.Header {
+		headers := make([]*eth.Header, 10)
+		for height := range headers {
+			headers[height] = &eth.Header{Number: big.NewInt(int64(height)), Time: uint64(1000 + height)}
+			if height > 2 {
+				headers[height].Extra = []byte{byte(seed)}
+			}
+			if height > 0 {
+				headers[height].ParentHash = headers[height-1].Hash()
+			}
+		}
+		return headers
+	}
+	chainA, chainB := newChain(1), newChain(2)
+	require.Equal(t, chainA[2].Hash(), chainB[2].Hash())
+	require.NotEqual(t, chainA[4].Hash(), chainB[4].Hash())
+
+	var mu sync.Mutex
+	headers := chainA
+	mockL2Client := mocks.NewMockIEthL2Client(ctl)
+	mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, number *big.Int) (*eth.Header, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return headers[number.Int64()], nil
+		}).AnyTimes()
+
+	// no block on chain B is voted
+	mockCwClient := mocks.NewMockICosmWasmClient(ctl)
+	mockCwClient.EXPECT().QueryConsumerId().Return("consumer-chain-id", nil).AnyTimes()
+	mockCwClient.EXPECT().QueryAllowedFinalityProviders(uint64(100)).Return([]string{"pk1"}, nil).AnyTimes()
+	mockCwClient.EXPECT().QueryPubRandCommitForHeight("pk1", gomock.Any()).
+		Return(&types.PubRandCommit{StartHeight: 1, NumPubRand: 100, BabylonHeight: 100}, nil).AnyTimes()
+	mockCwClient.EXPECT().QueryBlockVoters(gomock.Any()).Return(nil, nil).AnyTimes()
+	mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+	mockBBNClient.EXPECT().QueryBabylonHeightByTimestamp(gomock.Any()).Return(uint64(100), nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryAllFpBtcPubKeys("consumer-chain-id", uint64(100)).Return([]string{"pk1"}, nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryMultiFpPower([]string{"pk1"}, uint32(50)).DoAndReturn(
+		func([]string, uint32) (map[string]uint64, error) {
+			return map[string]uint64{"pk1": 100}, nil
+		}).AnyTimes()
+	mockBBNClient.EXPECT().QueryEpochInterval().Return(uint64(10), nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryLastFinalizedEpoch().Return(uint64(10), nil).AnyTimes()
+	mockBTCClient := mocks.NewMockIBitcoinClient(ctl)
+	mockBTCClient.EXPECT().GetBlockHeightByTimestamp(gomock.Any()).Return(uint32(50), nil).AnyTimes()
+
+	dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	require.NoError(t, err)
+	defer dbHandler.Close()
+	require.NoError(t, dbHandler.CreateInitialSchema())
+
+	fg := &FinalityGadget{
+		l2Client:       mockL2Client,
+		cwClient:       mockCwClient,
+		bbnClient:      mockBBNClient,
+		btcClient:      mockBTCClient,
+		db:             dbHandler,
+		logger:         zap.NewNop(),
+		batchSize:      2,
+		concurrency:    2,
+		contractConfig: &types.ContractConfig{BsnActivationHeight: 2, FinalitySignatureInterval: 2},
+	}
+
+	// Blocks 2 and 4 of chain A are finalized
+	blockAt := func(height int) *types.Block {
+		return &types.Block{
+			BlockHeight:    uint64(height),
+			BlockHash:      hex.EncodeToString(chainA[height].Hash().Bytes()),
+			BlockTimestamp: chainA[height].Time,
+		}
+	}
+	committed, err := fg.commitBlocks(context.Background(), []*types.Block{blockAt(2), blockAt(4)})
+	require.NoError(t, err)
+	require.True(t, committed)
+
+	// L2 reorgs to chain B, on which no block is finalized: block 4 is rolled back at the next poll
+	mu.Lock()
+	headers = chainB
+	mu.Unlock()
+	require.NoError(t, fg.processBlocksTillHeight(context.Background(), 9))
+	latest, err := dbHandler.QueryLatestFinalizedBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), latest.BlockHeight)
+	isFinalized, err := dbHandler.QueryIsBlockFinalizedByHeight(4)
+	require.NoError(t, err)
+	require.False(t, isFinalized)
+	require.Equal(t, uint64(3), fg.lastProcessedHeight.Load())
+}
+
+func TestCommitBlocksNonCanonical(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	headers := make([]*eth.Header, 10)
+	for height := range headers {
+		headers[height] = &eth.Header{Number: big.NewInt(int64(height)), Time: uint64(1000 + height)}
+		if height > 0 {
+			headers[height].ParentHash = headers[height-1].Hash()
+		}
+	}
+	mockL2Client := mocks.NewMockIEthL2Client(ctl)
+	mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, number *big.Int) (*eth.Header, error) {
+			return headers[number.Int64()], nil
+		}).AnyTimes()
+
+	dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	require.NoError(t, err)
+	defer dbHandler.Close()
+	require.NoError(t, dbHandler.CreateInitialSchema())
+
+	fg := &FinalityGadget{
+		l2Client: mockL2Client,
+		db:       dbHandler,
+		logger:   zap.NewNop(),
+	}
+	blockAt := func(height int) *types.Block {
+		return &types.Block{
+			BlockHeight:    uint64(height),
+			BlockHash:      hex.EncodeToString(headers[height].Hash().Bytes()),
+			BlockTimestamp: headers[height].Time,
+		}
+	}
+
+	// A canonical batch is committed
+	committed, err := fg.commitBlocks(context.Background(), []*types.Block{blockAt(2), blockAt(4)})
+	require.NoError(t, err)
+	require.True(t, committed)
+	require.Equal(t, uint64(4), fg.lastProcessedHeight.Load())
+
+	// A batch with a block reorged out since it was evaluated is not committed
+	reorgedOut := blockAt(8)
+	reorgedOut.BlockHash = hex.EncodeToString((&eth.Header{Number: big.NewInt(8), Time: 2000}).Hash().Bytes())
+	committed, err = fg.commitBlocks(context.Background(), []*types.Block{blockAt(6), reorgedOut})
+	require.NoError(t, err)
+	require.False(t, committed)
+	require.Equal(t, uint64(4), fg.lastProcessedHeight.Load())
+	latest, err := dbHandler.QueryLatestFinalizedBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), latest.BlockHeight)
+}
+
+func TestNextIntervalHeight(t *testing.T) {
+	fg := &FinalityGadget{contractConfig: &types.ContractConfig{BsnActivationHeight: 10, FinalitySignatureInterval: 5}}
+	for height, expected := range map[uint64]uint64{0: 10, 10: 10, 11: 15, 14: 15, 15: 15, 16: 20} {
+		require.Equal(t, expected, fg.nextIntervalHeight(height), "height %d", height)
+		require.True(t, fg.shouldProcessHeight(expected))
+	}
+}
+
+func TestRetryStage(t *testing.T) {
+	fg := &FinalityGadget{logger: zap.NewNop()}
+	transientErr := types.NewUpstreamError(types.UpstreamBabylon, types.ErrorClassTransient, errors.New("connection refused"))
+	permanentErr := types.NewUpstreamError(types.UpstreamL2, types.ErrorClassPermanent, errors.New("401 Unauthorized"))
+
+	// Transient errors are retried until the stage succeeds, the stage being degraded meanwhile
+	attempts := 0
+	err := fg.retryStage(context.Background(), nil, stageEvaluate, 10, func() error {
+		attempts++
+		if attempts == 1 {
+			status := fg.QueryHealthStatus()
+			require.False(t, status.Degraded)
+			return transientErr
+		}
+		status := fg.QueryHealthStatus()
+		require.True(t, status.Degraded)
+		require.Len(t, status.Failures, 1)
+		require.Equal(t, stageEvaluate, status.Failures[0].Component)
+		require.Equal(t, types.UpstreamBabylon, status.Failures[0].Upstream)
+		require.Equal(t, uint64(1), status.Failures[0].Failures)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.False(t, fg.QueryHealthStatus().Degraded)
+
+	// Permanent errors are not retried
+	attempts = 0
+	err = fg.retryStage(context.Background(), nil, stageFetch, 10, func() error {
+		attempts++
+		return fmt.Errorf("error getting block at height 10: %w", permanentErr)
+	})
+	require.ErrorIs(t, err, permanentErr)
+	require.True(t, types.IsPermanentError(err))
+	require.Equal(t, 1, attempts)
+
+	// An FP set without voting power isn't permanent, as it can gain voting power
+	require.False(t, types.IsPermanentError(fmt.Errorf("error checking is block 10 finalized from babylon: %w", types.ErrNoFpHasVotingPower)))
+
+	// Retries stop with the pipeline
+	stop := make(chan struct{})
+	close(stop)
+	err = fg.retryStage(context.Background(), stop, stageFetch, 10, func() error {
+		return transientErr
+	})
+	require.ErrorIs(t, err, errPipelineStopped)
+	status := fg.QueryHealthStatus()
+	require.True(t, status.Degraded)
+	require.Len(t, status.Failures, 1)
+	require.Equal(t, stageFetch, status.Failures[0].Component)
+	require.Equal(t, uint64(2), status.Failures[0].Failures)
+}
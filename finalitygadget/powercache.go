@@ -0,0 +1,124 @@
+package finalitygadget
+
+import (
+	"crypto/sha256"
+	"sort"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/metrics"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// fpPowerCacheSize is the number of FP power tables kept in memory. Consecutive L2 blocks
+// almost always map to the same BTC height, so a few recent BTC heights cover catching up.
+const fpPowerCacheSize = 256
+
+// fpPowerCacheTTL is how long an FP power table is served from the cache. Babylon computes the voting power at a
+// BTC height from the latest state of the delegations, so later unbondings and activations change it, and the
+// cache can only serve a table briefly. That is still enough for catching up consecutive L2 blocks.
+const fpPowerCacheTTL = time.Minute
+
+// fpPowerCacheKey identifies an FP power table by the FP set it was queried for and the BTC height
+type fpPowerCacheKey struct {
+	fpSetHash [sha256.Size]byte
+	btcHeight uint32
+}
+
+// fpPowerCache is a bounded, concurrency-safe LRU cache of FP power tables, whose entries expire after the
+// ttl. A nil cache is valid and always misses.
+type fpPowerCache struct {
+	tables *lru.Cache[fpPowerCacheKey, fpPowerCacheEntry]
+	ttl    time.Duration
+}
+
+type fpPowerCacheEntry struct {
+	fpPower  map[string]uint64
+	cachedAt time.Time
+}
+
+//////////////////////////////
+// CONSTRUCTOR
+//////////////////////////////
+
+func newFpPowerCache(size int, ttl time.Duration) (*fpPowerCache, error) {
+	tables, err := lru.New[fpPowerCacheKey, fpPowerCacheEntry](size)
+	if err != nil {
+		return nil, err
+	}
+	return &fpPowerCache{tables: tables, ttl: ttl}, nil
+}
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+/* queryMultiFpPower returns the voting power of the given FPs at the given BTC height
+ *
+ * - the power table is served from the cache if it was queried for the same FP set and BTC height less than
+ *   the cache ttl ago. Babylon computes it from the latest delegation state, so it isn't final and expires
+ * - the returned map is a copy the caller may modify
+ */
+func (fg *FinalityGadget) queryMultiFpPower(fpPks []string, btcHeight uint32) (map[string]uint64, error) {
+	key := fpPowerCacheKey{fpSetHash: hashFpSet(fpPks), btcHeight: btcHeight}
+	if fpPower, ok := fg.fpPowerCache.get(key); ok {
+		metrics.FpPowerCacheHitsTotal.Inc()
+		return fpPower, nil
+	}
+	metrics.FpPowerCacheMissesTotal.Inc()
+
+	fpPower, err := fg.bbnClient.QueryMultiFpPower(fpPks, btcHeight)
+	if err != nil {
+		return nil, err
+	}
+	fg.fpPowerCache.add(key, fpPower)
+	return fpPower, nil
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+func (c *fpPowerCache) get(key fpPowerCacheKey) (map[string]uint64, bool) {
+	if c == nil {
+		return nil, false
+	}
+	entry, ok := c.tables.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if time.Since(entry.cachedAt) > c.ttl {
+		c.tables.Remove(key)
+		return nil, false
+	}
+	return copyFpPower(entry.fpPower), true
+}
+
+func (c *fpPowerCache) add(key fpPowerCacheKey, fpPower map[string]uint64) {
+	if c == nil {
+		return
+	}
+	c.tables.Add(key, fpPowerCacheEntry{fpPower: copyFpPower(fpPower), cachedAt: time.Now()})
+}
+
+// hashFpSet returns a hash of the FP set that doesn't depend on the order of the keys
+func hashFpSet(fpPks []string) [sha256.Size]byte {
+	sorted := append([]string(nil), fpPks...)
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, fpPk := range sorted {
+		h.Write([]byte(fpPk))
+		h.Write([]byte{0})
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+func copyFpPower(fpPower map[string]uint64) map[string]uint64 {
+	copied := make(map[string]uint64, len(fpPower))
+	for fpPk, power := range fpPower {
+		copied[fpPk] = power
+	}
+	return copied
+}
@@ -0,0 +1,143 @@
+package finalitygadget
+
+import (
+	"sync/atomic"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// pubRandCommitCacheSize is the number of FPs whose latest public randomness commitment is kept in memory.
+// A commitment covers a whole range of L2 heights, so one per FP serves consecutive blocks.
+const pubRandCommitCacheSize = 1024
+
+// pubRandCommitCache is a bounded, concurrency-safe cache of the public randomness commitments and of the Babylon
+// epochs telling whether they are timestamped. A nil cache is valid and always misses.
+type pubRandCommitCache struct {
+	// commits holds the latest commitment found per FP
+	commits *lru.Cache[string, *types.PubRandCommit]
+	// lastFinalizedEpoch only grows, so a stale value can only make a commitment look not timestamped yet
+	lastFinalizedEpoch atomic.Uint64
+	// epochInterval is 0 until queried
+	epochInterval atomic.Uint64
+}
+
+//////////////////////////////
+// CONSTRUCTOR
+//////////////////////////////
+
+func newPubRandCommitCache(size int) (*pubRandCommitCache, error) {
+	commits, err := lru.New[string, *types.PubRandCommit](size)
+	if err != nil {
+		return nil, err
+	}
+	return &pubRandCommitCache{commits: commits}, nil
+}
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+/* queryPubRandCommitForHeight returns the public randomness commitment of the FP covering the given L2 height, or
+ * nil if there is none
+ *
+ * - the commitment is served from the cache if the latest one found for the FP covers the height
+ * - commitments never change once submitted, so entries never go stale. A missing commitment isn't cached, as
+ *   the FP may still submit it
+ */
+func (fg *FinalityGadget) queryPubRandCommitForHeight(fpPk string, height uint64) (*types.PubRandCommit, error) {
+	if commit, ok := fg.pubRandCommitCache.get(fpPk); ok && commit.Covers(height) {
+		return commit, nil
+	}
+
+	commit, err := fg.cwClient.QueryPubRandCommitForHeight(fpPk, height)
+	if err != nil {
+		return nil, err
+	}
+	if commit != nil {
+		fg.pubRandCommitCache.add(fpPk, commit)
+	}
+	return commit, nil
+}
+
+/* isPubRandCommitTimestamped returns whether the public randomness commitment is timestamped, i.e. the checkpoint
+ * of the Babylon epoch it was submitted in is finalized on BTC, as the Babylon finality module requires
+ *
+ * - the epoch interval is queried once
+ * - the last finalized epoch is only queried again for commitments submitted after the one cached
+ */
+func (fg *FinalityGadget) isPubRandCommitTimestamped(commit *types.PubRandCommit) (bool, error) {
+	epochInterval, ok := fg.pubRandCommitCache.getEpochInterval()
+	if !ok {
+		var err error
+		epochInterval, err = fg.bbnClient.QueryEpochInterval()
+		if err != nil {
+			return false, err
+		}
+		fg.pubRandCommitCache.setEpochInterval(epochInterval)
+	}
+	epoch := commit.Epoch(epochInterval)
+
+	if lastFinalizedEpoch, ok := fg.pubRandCommitCache.getLastFinalizedEpoch(); ok && epoch <= lastFinalizedEpoch {
+		return true, nil
+	}
+	lastFinalizedEpoch, err := fg.bbnClient.QueryLastFinalizedEpoch()
+	if err != nil {
+		return false, err
+	}
+	fg.pubRandCommitCache.setLastFinalizedEpoch(lastFinalizedEpoch)
+	return epoch <= lastFinalizedEpoch, nil
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+func (c *pubRandCommitCache) get(fpPk string) (*types.PubRandCommit, bool) {
+	if c == nil {
+		return nil, false
+	}
+	return c.commits.Get(fpPk)
+}
+
+func (c *pubRandCommitCache) add(fpPk string, commit *types.PubRandCommit) {
+	if c == nil {
+		return
+	}
+	c.commits.Add(fpPk, commit)
+}
+
+func (c *pubRandCommitCache) getEpochInterval() (uint64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	epochInterval := c.epochInterval.Load()
+	return epochInterval, epochInterval != 0
+}
+
+func (c *pubRandCommitCache) setEpochInterval(epochInterval uint64) {
+	if c == nil {
+		return
+	}
+	c.epochInterval.Store(epochInterval)
+}
+
+func (c *pubRandCommitCache) getLastFinalizedEpoch() (uint64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	return c.lastFinalizedEpoch.Load(), true
+}
+
+// setLastFinalizedEpoch records the last finalized epoch, unless a later one was already recorded concurrently
+func (c *pubRandCommitCache) setLastFinalizedEpoch(epoch uint64) {
+	if c == nil {
+		return
+	}
+	for {
+		cached := c.lastFinalizedEpoch.Load()
+		if epoch <= cached || c.lastFinalizedEpoch.CompareAndSwap(cached, epoch) {
+			return
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package finalitygadget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultPruneInterval is the interval between two prunings when none is set in the config
+	defaultPruneInterval = 10 * time.Minute
+	// pruneBatchSize is the number of blocks deleted per db write, so pruning never holds
+	// the db for long and block inserts interleave with it
+	pruneBatchSize = 1000
+)
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+// PruneBlocks prunes the finalized blocks out of the retention window, every prune interval until ctx is done.
+// It returns right away if no retention is set.
+func (fg *FinalityGadget) PruneBlocks(ctx context.Context) {
+	if fg.retentionBlocks == 0 && fg.retentionPeriod == 0 {
+		return
+	}
+	fg.logger.Info("Pruning finalized blocks out of the retention window",
+		zap.Uint64("retention_blocks", fg.retentionBlocks),
+		zap.Duration("retention_period", fg.retentionPeriod),
+		zap.Duration("prune_interval", fg.pruneInterval))
+
+	ticker := time.NewTicker(fg.pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := fg.pruneBlocks(ctx, time.Now()); err != nil && !errors.Is(err, context.Canceled) {
+			fg.logger.Error("Failed to prune finalized blocks", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+// pruneBlocks deletes the blocks below the retention cutoff in batches of pruneBatchSize,
+// and returns the number of blocks deleted
+func (fg *FinalityGadget) pruneBlocks(ctx context.Context, now time.Time) (uint64, error) {
+	cutoff, err := fg.retentionCutoffHeight(now)
+	if err != nil {
+		return 0, err
+	}
+	if cutoff == 0 {
+		return 0, nil
+	}
+
+	var total uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		deleted, err := fg.db.DeleteBlocksBeforeHeight(cutoff, pruneBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		metrics.PrunedBlocksTotal.Add(float64(deleted))
+		if deleted < pruneBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		fg.logger.Info("Pruned finalized blocks", zap.Uint64("count", total), zap.Uint64("below_height", cutoff))
+	}
+	return total, nil
+}
+
+/* retentionCutoffHeight returns the height below which finalized blocks are out of the retention window
+ *
+ * - with retentionBlocks, the latest retentionBlocks heights are kept
+ * - with retentionPeriod, the blocks with a timestamp in the last retentionPeriod are kept; as timestamps
+ *   increase with the height, the first of them is found with a binary search over the stored blocks
+ * - when both are set, a block is kept as long as it is within either of them
+ * - the latest finalized block is always kept, so block processing resumes from it after a restart
+ * - returns 0 if nothing is to be pruned
+ */
+func (fg *FinalityGadget) retentionCutoffHeight(now time.Time) (uint64, error) {
+	if fg.retentionBlocks == 0 && fg.retentionPeriod == 0 {
+		return 0, nil
+	}
+	latest, err := fg.db.QueryLatestFinalizedBlock()
+	if err != nil || latest == nil {
+		return 0, err
+	}
+
+	cutoff := latest.BlockHeight
+	if fg.retentionBlocks > 0 {
+		var blocksCutoff uint64
+		if latest.BlockHeight+1 > fg.retentionBlocks {
+			blocksCutoff = latest.BlockHeight + 1 - fg.retentionBlocks
+		}
+		cutoff = min(cutoff, blocksCutoff)
+	}
+	if fg.retentionPeriod > 0 {
+		periodCutoff, err := fg.firstHeightSince(uint64(now.Add(-fg.retentionPeriod).Unix()), latest.BlockHeight)
+		if err != nil {
+			return 0, err
+		}
+		cutoff = min(cutoff, periodCutoff)
+	}
+	return cutoff, nil
+}
+
+// firstHeightSince returns the lowest stored height with a timestamp at or after the given one,
+// or latestHeight if every stored block is older
+func (fg *FinalityGadget) firstHeightSince(timestamp uint64, latestHeight uint64) (uint64, error) {
+	earliest, err := fg.db.QueryEarliestFinalizedBlock()
+	if err != nil {
+		return 0, err
+	}
+
+	lowerBound, upperBound := earliest.BlockHeight, latestHeight
+	for lowerBound < upperBound {
+		midHeight := lowerBound + (upperBound-lowerBound)/2
+		// the first block stored at or above midHeight, as there may be gaps
+		blocks, err := fg.db.GetBlocksFromHeight(midHeight, 1)
+		if err != nil {
+			return 0, err
+		}
+		if len(blocks) == 0 {
+			return 0, fmt.Errorf("no finalized block stored at or above height %d", midHeight)
+		}
+		if blocks[0].BlockTimestamp >= timestamp {
+			upperBound = midHeight
+		} else {
+			lowerBound = blocks[0].BlockHeight + 1
+		}
+	}
+	// lowerBound moves past latestHeight if every stored block is older
+	return min(lowerBound, latestHeight), nil
+}
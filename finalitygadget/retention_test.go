@@ -0,0 +1,94 @@
+package finalitygadget
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/db"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// setupRetentionDB stores blocks at heights [from, to], one per minute up to now
+func setupRetentionDB(t *testing.T, from uint64, to uint64, now time.Time) db.IDatabaseHandler {
+	dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(func() { dbHandler.Close() })
+	require.NoError(t, dbHandler.CreateInitialSchema())
+
+	var blocks []*types.Block
+	for height := from; height <= to; height++ {
+		blocks = append(blocks, &types.Block{
+			BlockHeight:    height,
+			BlockHash:      fmt.Sprintf("0x%064x", height),
+			BlockTimestamp: uint64(now.Add(-time.Duration(to-height) * time.Minute).Unix()),
+		})
+	}
+	require.NoError(t, dbHandler.InsertBlocks(blocks))
+	return dbHandler
+}
+
+func TestRetentionCutoffHeight(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name            string
+		retentionBlocks uint64
+		retentionPeriod time.Duration
+		expectedCutoff  uint64
+	}{
+		{"no retention", 0, 0, 0},
+		{"blocks", 10, 0, 91},
+		{"more blocks than stored", 1000, 0, 0},
+		{"period", 0, 30 * time.Minute, 70},
+		{"period older than every block", 0, 24 * time.Hour, 10},
+		{"period keeps the latest block", 0, time.Second, 100},
+		// a block is kept while within either window
+		{"blocks and period", 10, 30 * time.Minute, 70},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fg := &FinalityGadget{
+				db:              setupRetentionDB(t, 10, 100, now),
+				logger:          zap.NewNop(),
+				retentionBlocks: tc.retentionBlocks,
+				retentionPeriod: tc.retentionPeriod,
+			}
+			cutoff, err := fg.retentionCutoffHeight(now)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCutoff, cutoff)
+		})
+	}
+}
+
+func TestPruneBlocks(t *testing.T) {
+	now := time.Now()
+	fg := &FinalityGadget{
+		db:              setupRetentionDB(t, 1, 2500, now),
+		logger:          zap.NewNop(),
+		retentionBlocks: 100,
+	}
+
+	// Blocks are pruned over several batches
+	deleted, err := fg.pruneBlocks(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2400), deleted)
+
+	earliest, err := fg.db.QueryEarliestFinalizedBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2401), earliest.BlockHeight)
+	latest, err := fg.db.QueryLatestFinalizedBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2500), latest.BlockHeight)
+	_, err = fg.db.GetBlockByHash(fmt.Sprintf("0x%064x", 2400))
+	require.ErrorIs(t, err, types.ErrBlockNotFound)
+
+	// Nothing is left to prune until new blocks are finalized
+	deleted, err = fg.pruneBlocks(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), deleted)
+}
@@ -0,0 +1,168 @@
+package finalitygadget
+
+import (
+	"context"
+	"sync"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"go.uber.org/zap"
+)
+
+// subscriptionBatchSize is the number of blocks a subscriber reads from the db at once
+const subscriptionBatchSize = 100
+
+// blockSubscriber is notified whenever finalized blocks are inserted or rolled back
+//
+// Notifications are coalesced into a single pending signal, so publishing never blocks
+// on a slow subscriber. The subscriber reads the blocks it hasn't sent yet from the db
+// at its own pace instead of buffering them in memory.
+type blockSubscriber struct {
+	notify chan struct{}
+
+	mutex sync.Mutex
+	// rewindHeight is the lowest height rolled back since the subscriber last checked
+	rewindHeight *uint64
+}
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+/* SubscribeFinalizedBlocks streams finalized blocks to send, starting at the given height
+ *
+ * - first replays the finalized blocks stored in the db from the given height
+ * - then sends new blocks as they are inserted, until ctx is done or send fails
+ * - blocks are sent in ascending height order; if blocks already sent are rolled back due to
+ *   an L2 reorg, the stream resumes from the fork height, so a height can be sent again with a new hash
+ * - a slow subscriber only delays its own stream, it never blocks block processing
+ */
+func (fg *FinalityGadget) SubscribeFinalizedBlocks(ctx context.Context, fromHeight uint64, send func(*types.Block) error) error {
+	return fg.SubscribeFinalityEvents(ctx, fromHeight, func(event *types.FinalityEvent) error {
+		if event.Type != types.FinalityEventFinalizedBlock {
+			return nil
+		}
+		return send(event.Block)
+	})
+}
+
+/* SubscribeFinalityEvents streams finalized block and rollback events to send, starting at the given height
+ *
+ * - same as SubscribeFinalizedBlocks, but a rollback of blocks already sent is reported as a
+ *   rollback event before the stream resumes from the fork height
+ */
+func (fg *FinalityGadget) SubscribeFinalityEvents(ctx context.Context, fromHeight uint64, send func(*types.FinalityEvent) error) error {
+	sub := fg.subscribe()
+	defer fg.unsubscribe(sub)
+
+	nextHeight := fromHeight
+	for {
+		// send everything finalized since the last sent block
+		for {
+			if rewindHeight, ok := sub.takeRewindHeight(); ok && rewindHeight < nextHeight {
+				fg.logger.Debug("Rewinding block subscription after rollback",
+					zap.Uint64("from_height", nextHeight),
+					zap.Uint64("to_height", rewindHeight))
+				if err := send(&types.FinalityEvent{
+					Type:           types.FinalityEventRollback,
+					RollbackHeight: rewindHeight,
+				}); err != nil {
+					return err
+				}
+				nextHeight = rewindHeight
+			}
+
+			blocks, err := fg.db.GetBlocksFromHeight(nextHeight, subscriptionBatchSize)
+			if err != nil {
+				return err
+			}
+			for _, block := range blocks {
+				if err := send(&types.FinalityEvent{
+					Type:  types.FinalityEventFinalizedBlock,
+					Block: block,
+				}); err != nil {
+					return err
+				}
+				nextHeight = block.BlockHeight + 1
+			}
+			if len(blocks) < subscriptionBatchSize {
+				break
+			}
+		}
+
+		// wait for new blocks
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.notify:
+		}
+	}
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+func (fg *FinalityGadget) subscribe() *blockSubscriber {
+	fg.subscribersMutex.Lock()
+	defer fg.subscribersMutex.Unlock()
+
+	if fg.subscribers == nil {
+		fg.subscribers = make(map[*blockSubscriber]struct{})
+	}
+	sub := &blockSubscriber{notify: make(chan struct{}, 1)}
+	fg.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (fg *FinalityGadget) unsubscribe(sub *blockSubscriber) {
+	fg.subscribersMutex.Lock()
+	defer fg.subscribersMutex.Unlock()
+
+	delete(fg.subscribers, sub)
+}
+
+// notifySubscribers signals all subscribers that new finalized blocks are available
+func (fg *FinalityGadget) notifySubscribers() {
+	fg.subscribersMutex.Lock()
+	defer fg.subscribersMutex.Unlock()
+
+	for sub := range fg.subscribers {
+		sub.signal()
+	}
+}
+
+// notifySubscribersOfRollback signals all subscribers that blocks from the given height were rolled back
+func (fg *FinalityGadget) notifySubscribersOfRollback(fromHeight uint64) {
+	fg.subscribersMutex.Lock()
+	defer fg.subscribersMutex.Unlock()
+
+	for sub := range fg.subscribers {
+		sub.mutex.Lock()
+		if sub.rewindHeight == nil || fromHeight < *sub.rewindHeight {
+			sub.rewindHeight = &fromHeight
+		}
+		sub.mutex.Unlock()
+		sub.signal()
+	}
+}
+
+// signal leaves a pending notification, unless one is already pending
+func (sub *blockSubscriber) signal() {
+	select {
+	case sub.notify <- struct{}{}:
+	default:
+	}
+}
+
+// takeRewindHeight returns and clears the pending rewind height, if any
+func (sub *blockSubscriber) takeRewindHeight() (uint64, bool) {
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+
+	if sub.rewindHeight == nil {
+		return 0, false
+	}
+	rewindHeight := *sub.rewindHeight
+	sub.rewindHeight = nil
+	return rewindHeight, true
+}
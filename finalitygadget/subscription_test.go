@@ -0,0 +1,130 @@
+package finalitygadget
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/db"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSubscribeFinalizedBlocks(t *testing.T) {
+	dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	require.NoError(t, err)
+	defer dbHandler.Close()
+	require.NoError(t, dbHandler.CreateInitialSchema())
+
+	fg := &FinalityGadget{
+		db:             dbHandler,
+		logger:         zap.NewNop(),
+		contractConfig: &types.ContractConfig{FinalitySignatureInterval: 1},
+	}
+
+	// blocks finalized before subscribing
+	require.NoError(t, fg.insertBlocks([]*types.Block{
+		{BlockHeight: 1, BlockHash: "0x01", BlockTimestamp: 1000},
+		{BlockHeight: 2, BlockHash: "0x02", BlockTimestamp: 1001},
+		{BlockHeight: 3, BlockHash: "0x03", BlockTimestamp: 1002},
+	}, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan *types.Block, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- fg.SubscribeFinalizedBlocks(ctx, 2, func(block *types.Block) error {
+			received <- block
+			return nil
+		})
+	}()
+
+	expectBlock := func(height uint64, hash string) {
+		select {
+		case block := <-received:
+			require.Equal(t, height, block.BlockHeight)
+			require.Equal(t, normalizeBlockHash(hash), block.BlockHash)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for block %d", height)
+		}
+	}
+
+	// replays the stored blocks from the requested height
+	expectBlock(2, "0x02")
+	expectBlock(3, "0x03")
+
+	// pushes newly inserted blocks
+	require.NoError(t, fg.insertBlocks([]*types.Block{{BlockHeight: 4, BlockHash: "0x04", BlockTimestamp: 1003}}, nil))
+	expectBlock(4, "0x04")
+
+	// resends from the fork height after a rollback
+	require.NoError(t, fg.rollbackBlocks(3, 4))
+	require.NoError(t, fg.insertBlocks([]*types.Block{
+		{BlockHeight: 3, BlockHash: "0x13", BlockTimestamp: 1002},
+		{BlockHeight: 4, BlockHash: "0x14", BlockTimestamp: 1003},
+	}, nil))
+	expectBlock(3, "0x13")
+	expectBlock(4, "0x14")
+
+	// stops when the context is cancelled
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the subscription to stop")
+	}
+	require.Empty(t, fg.subscribers)
+}
+
+func TestSubscribeFinalityEventsReportsRollback(t *testing.T) {
+	dbHandler, err := db.NewBBoltHandler(filepath.Join(t.TempDir(), "test.db"), zap.NewNop())
+	require.NoError(t, err)
+	defer dbHandler.Close()
+	require.NoError(t, dbHandler.CreateInitialSchema())
+
+	fg := &FinalityGadget{
+		db:             dbHandler,
+		logger:         zap.NewNop(),
+		contractConfig: &types.ContractConfig{FinalitySignatureInterval: 1},
+	}
+	require.NoError(t, fg.insertBlocks([]*types.Block{
+		{BlockHeight: 1, BlockHash: "0x01", BlockTimestamp: 1000},
+		{BlockHeight: 2, BlockHash: "0x02", BlockTimestamp: 1001},
+	}, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	received := make(chan *types.FinalityEvent, 10)
+	go func() {
+		_ = fg.SubscribeFinalityEvents(ctx, 1, func(event *types.FinalityEvent) error {
+			received <- event
+			return nil
+		})
+	}()
+
+	nextEvent := func() *types.FinalityEvent {
+		select {
+		case event := <-received:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return nil
+		}
+	}
+
+	require.Equal(t, uint64(1), nextEvent().Block.BlockHeight)
+	require.Equal(t, uint64(2), nextEvent().Block.BlockHeight)
+
+	// rolling back a sent block is reported before the new branch
+	require.NoError(t, fg.rollbackBlocks(2, 2))
+	require.NoError(t, fg.insertBlocks([]*types.Block{{BlockHeight: 2, BlockHash: "0x12", BlockTimestamp: 1001}}, nil))
+	event := nextEvent()
+	require.Equal(t, types.FinalityEventRollback, event.Type)
+	require.Equal(t, uint64(2), event.RollbackHeight)
+	event = nextEvent()
+	require.Equal(t, types.FinalityEventFinalizedBlock, event.Type)
+	require.Equal(t, normalizeBlockHash("0x12"), event.Block.BlockHash)
+}
@@ -0,0 +1,226 @@
+package finalitygadget
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/metrics"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"go.uber.org/zap"
+)
+
+const (
+	// stallCheckInterval is the interval between two checks of the finalization progress
+	stallCheckInterval = 30 * time.Second
+)
+
+// stallEpisode is the ongoing stall of the finalization
+type stallEpisode struct {
+	id        string
+	startedAt time.Time
+	// fingerprint identifies the last alert delivered, so the same alert isn't delivered at every check
+	fingerprint string
+}
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+// WatchFinalization checks the finalization progress every stallCheckInterval until ctx is done, and alerts the
+// webhooks when it stalls and when it resumes. It returns right away if no stall threshold is set.
+func (fg *FinalityGadget) WatchFinalization(ctx context.Context) {
+	if fg.stallMaxDuration == 0 && fg.stallMaxLag == 0 {
+		return
+	}
+	fg.logger.Info("Watching the finalization for stalls",
+		zap.Duration("stall_max_duration", fg.stallMaxDuration),
+		zap.Uint64("stall_max_lag", fg.stallMaxLag))
+
+	ticker := time.NewTicker(stallCheckInterval)
+	defer ticker.Stop()
+
+	var episode *stallEpisode
+	for {
+		episode = fg.checkStall(ctx, time.Now(), episode)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+/* checkStall checks whether the finalization is stalled, and returns the stall episode still ongoing, if any
+ *
+ * - the finalization is stalled if the finalized height hasn't advanced for more than the max duration, or if it
+ *   lags more than the max lag behind the L2 head seen by the last poll
+ * - the duration is measured on the wall clock from the last commit, or from the start if nothing was committed
+ *   since. Block timestamps would flag a stall while catching up on old blocks
+ * - a stall is diagnosed by tallying the votes on the first block not finalized, which tells the FPs missing votes
+ *   and the voting power missing to reach the quorum
+ * - a firing alert is delivered when the stall starts and whenever the FPs missing votes change, and a resolved
+ *   alert once the stall is over. Alerts whose delivery failed are delivered again at the next check
+ */
+func (fg *FinalityGadget) checkStall(ctx context.Context, now time.Time, episode *stallEpisode) *stallEpisode {
+	latest, err := fg.db.QueryLatestFinalizedBlock()
+	if err != nil {
+		fg.logger.Error("Failed to query the latest finalized block", zap.Error(err))
+		return episode
+	}
+	var latestHeight uint64
+	if latest != nil {
+		latestHeight = latest.BlockHeight
+	}
+	since := fg.startTime
+	if lastFinalizedTime := fg.lastFinalizedTime.Load(); lastFinalizedTime != 0 {
+		since = time.Unix(0, lastFinalizedTime)
+	}
+	l2HeadHeight := fg.l2HeadHeight.Load()
+
+	var reasons, conditions []string
+	if fg.stallMaxDuration > 0 && now.Sub(since) > fg.stallMaxDuration {
+		reasons = append(reasons, fmt.Sprintf("no new finalized block for %s", now.Sub(since).Truncate(time.Second)))
+		conditions = append(conditions, "duration")
+	}
+	if fg.stallMaxLag > 0 && l2HeadHeight > latestHeight && l2HeadHeight-latestHeight > fg.stallMaxLag {
+		reasons = append(reasons, fmt.Sprintf("the latest finalized block lags %d blocks behind the L2 head", l2HeadHeight-latestHeight))
+		conditions = append(conditions, "lag")
+	}
+
+	// The finalization progresses
+	if len(reasons) == 0 {
+		metrics.FinalizationStalled.Set(0)
+		metrics.StallMissingPower.Set(0)
+		if episode == nil {
+			return nil
+		}
+		fg.logger.Info("Finalization resumed",
+			zap.String("stall_id", episode.id),
+			zap.Uint64("latest_finalized_height", latestHeight),
+			zap.Duration("stalled_for", now.Sub(episode.startedAt)))
+		alert := &types.StallAlert{
+			ID:                    episode.id,
+			Status:                types.AlertStatusResolved,
+			StartedAt:             uint64(episode.startedAt.Unix()),
+			Timestamp:             uint64(now.Unix()),
+			LatestFinalizedHeight: latestHeight,
+			L2HeadHeight:          l2HeadHeight,
+		}
+		if err := fg.alertNotifier.notify(ctx, alert); err != nil {
+			// keep the episode so the resolved alert is delivered again at the next check
+			return episode
+		}
+		return nil
+	}
+
+	metrics.FinalizationStalled.Set(1)
+	if episode == nil {
+		episode = &stallEpisode{id: fmt.Sprintf("stall-%d-%d", latestHeight, now.Unix()), startedAt: now}
+	}
+	alert := &types.StallAlert{
+		ID:                    episode.id,
+		Status:                types.AlertStatusFiring,
+		Reasons:               reasons,
+		StartedAt:             uint64(episode.startedAt.Unix()),
+		Timestamp:             uint64(now.Unix()),
+		LatestFinalizedHeight: latestHeight,
+		L2HeadHeight:          l2HeadHeight,
+	}
+	alert.Diagnosis, err = fg.diagnoseStall(latestHeight, l2HeadHeight)
+	if err != nil {
+		fg.logger.Warn("Failed to diagnose the finalization stall", zap.Uint64("latest_finalized_height", latestHeight), zap.Error(err))
+	}
+
+	// Only alert again if the conditions or the FPs missing votes changed since the last alert
+	fingerprint := strings.Join(conditions, ",")
+	if alert.Diagnosis != nil {
+		metrics.StallMissingPower.Set(float64(alert.Diagnosis.MissingPower))
+		for _, voter := range alert.Diagnosis.MissingVoters {
+			fingerprint += "/" + voter.FpBtcPkHex
+		}
+	}
+	if fingerprint == episode.fingerprint {
+		return episode
+	}
+
+	fields := []zap.Field{
+		zap.String("stall_id", episode.id),
+		zap.Strings("reasons", reasons),
+		zap.Uint64("latest_finalized_height", latestHeight),
+		zap.Uint64("l2_head_height", l2HeadHeight),
+	}
+	if alert.Diagnosis != nil {
+		missingVoters := make([]string, len(alert.Diagnosis.MissingVoters))
+		for i, voter := range alert.Diagnosis.MissingVoters {
+			missingVoters[i] = voter.FpBtcPkHex
+		}
+		fields = append(fields,
+			zap.Uint64("block_height", alert.Diagnosis.BlockHeight),
+			zap.Strings("missing_voters", missingVoters),
+			zap.Uint64("missing_power", alert.Diagnosis.MissingPower))
+	}
+	fg.logger.Warn("Finalization stalled", fields...)
+
+	if err := fg.alertNotifier.notify(ctx, alert); err == nil {
+		episode.fingerprint = fingerprint
+	}
+	return episode
+}
+
+// diagnoseStall tallies the votes on the first block at a finality signature interval above the latest finalized
+// block. It returns nil if the L2 chain hasn't reached that block yet.
+func (fg *FinalityGadget) diagnoseStall(latestHeight uint64, l2HeadHeight uint64) (*types.StallDiagnosis, error) {
+	height := fg.nextIntervalHeight(latestHeight + 1)
+	if height > l2HeadHeight {
+		return nil, nil
+	}
+	if height > math.MaxInt64 {
+		return nil, fmt.Errorf("block height %d exceeds maximum int64 value", height)
+	}
+
+	block, err := fg.queryBlockByHeight(int64(height))
+	if err != nil {
+		return nil, fmt.Errorf("error getting block at height %d: %w", height, err)
+	}
+	tally, err := fg.tallyVotes(block)
+	if err != nil {
+		return nil, fmt.Errorf("error tallying the votes on block %d: %w", height, err)
+	}
+
+	voted := make(map[string]bool, len(tally.votes))
+	for _, vote := range tally.votes {
+		voted[vote.FpBtcPkHex] = true
+	}
+	diagnosis := &types.StallDiagnosis{
+		BlockHeight:   block.BlockHeight,
+		BlockHash:     block.BlockHash,
+		TotalPower:    tally.totalPower,
+		VotedPower:    tally.votedPower,
+		QuorumPower:   tally.quorum.Threshold(tally.totalPower),
+		MissingVoters: []*types.VoterPower{},
+	}
+	if diagnosis.QuorumPower > diagnosis.VotedPower {
+		diagnosis.MissingPower = diagnosis.QuorumPower - diagnosis.VotedPower
+	}
+	for fpPk, power := range tally.fpPower {
+		if power > 0 && !voted[fpPk] {
+			diagnosis.MissingVoters = append(diagnosis.MissingVoters, &types.VoterPower{FpBtcPkHex: fpPk, Power: power})
+		}
+	}
+	sort.Slice(diagnosis.MissingVoters, func(i, j int) bool {
+		if diagnosis.MissingVoters[i].Power != diagnosis.MissingVoters[j].Power {
+			return diagnosis.MissingVoters[i].Power > diagnosis.MissingVoters[j].Power
+		}
+		return diagnosis.MissingVoters[i].FpBtcPkHex < diagnosis.MissingVoters[j].FpBtcPkHex
+	})
+	return diagnosis, nil
+}
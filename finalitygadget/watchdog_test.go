@@ -0,0 +1,123 @@
+package finalitygadget
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/testutil/mocks"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	eth "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+)
+
+func TestCheckStall(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// the webhook fails the first delivery, which is retried
+	var mu sync.Mutex
+	var requests int
+	var alerts []*types.StallAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var alert types.StallAlert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alerts = append(alerts, &alert)
+	}))
+	defer server.Close()
+
+	// pk1 votes with 60 of the 100 voting power, pk2 doesn't
+	mockL2Client := mocks.NewMockIEthL2Client(ctl)
+	mockL2Client.EXPECT().HeaderByNumber(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, number *big.Int) (*eth.Header, error) {
+			return &eth.Header{Number: number, Time: 1000}, nil
+		}).AnyTimes()
+	mockCwClient := mocks.NewMockICosmWasmClient(ctl)
+	mockCwClient.EXPECT().QueryConsumerId().Return("consumer-chain-id", nil).AnyTimes()
+	mockCwClient.EXPECT().QueryAllowedFinalityProviders(uint64(100)).Return([]string{"pk1", "pk2"}, nil).AnyTimes()
+	mockCwClient.EXPECT().QueryPubRandCommitForHeight(gomock.Any(), gomock.Any()).
+		Return(&types.PubRandCommit{StartHeight: 1, NumPubRand: 100, BabylonHeight: 100}, nil).AnyTimes()
+	mockCwClient.EXPECT().QueryBlockVoters(gomock.Any()).Return([]*types.BlockVoter{{FpBtcPkHex: "pk1"}}, nil).AnyTimes()
+	mockBBNClient := mocks.NewMockIBabylonClient(ctl)
+	mockBBNClient.EXPECT().QueryBabylonHeightByTimestamp(gomock.Any()).Return(uint64(100), nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryAllFpBtcPubKeys("consumer-chain-id", uint64(100)).Return([]string{"pk1", "pk2"}, nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryMultiFpPower(gomock.Any(), uint32(50)).
+		Return(map[string]uint64{"pk1": 60, "pk2": 40}, nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryEpochInterval().Return(uint64(10), nil).AnyTimes()
+	mockBBNClient.EXPECT().QueryLastFinalizedEpoch().Return(uint64(10), nil).AnyTimes()
+	mockBTCClient := mocks.NewMockIBitcoinClient(ctl)
+	mockBTCClient.EXPECT().GetBlockHeightByTimestamp(gomock.Any()).Return(uint32(50), nil).AnyTimes()
+
+	// the latest finalized block is at height 30, 10 minutes ago, and nothing was committed since the start an hour ago
+	now := time.Now()
+	dbHandler := setupRetentionDB(t, 10, 30, now.Add(-10*time.Minute))
+	fpPowerCache, err := newFpPowerCache(fpPowerCacheSize, fpPowerCacheTTL)
+	require.NoError(t, err)
+	notifier := newWebhookNotifier([]string{server.URL}, zap.NewNop())
+	notifier.initialBackoff = time.Millisecond
+	fg := &FinalityGadget{
+		l2Client:         mockL2Client,
+		cwClient:         mockCwClient,
+		bbnClient:        mockBBNClient,
+		btcClient:        mockBTCClient,
+		db:               dbHandler,
+		logger:           zap.NewNop(),
+		fpPowerCache:     fpPowerCache,
+		contractConfig:   &types.ContractConfig{BsnActivationHeight: 2, FinalitySignatureInterval: 2},
+		startTime:        now.Add(-time.Hour),
+		stallMaxDuration: 5 * time.Minute,
+		stallMaxLag:      5,
+		alertNotifier:    notifier,
+	}
+	fg.l2HeadHeight.Store(40)
+
+	// Stalled on both conditions, diagnosed on the next height at a finality signature interval
+	episode := fg.checkStall(context.Background(), now, nil)
+	require.NotNil(t, episode)
+	require.Equal(t, 2, requests)
+	require.Len(t, alerts, 1)
+	alert := alerts[0]
+	require.Equal(t, types.AlertStatusFiring, alert.Status)
+	require.Len(t, alert.Reasons, 2)
+	require.Equal(t, uint64(30), alert.LatestFinalizedHeight)
+	require.Equal(t, uint64(40), alert.L2HeadHeight)
+	require.NotNil(t, alert.Diagnosis)
+	require.Equal(t, uint64(32), alert.Diagnosis.BlockHeight)
+	require.Equal(t, uint64(100), alert.Diagnosis.TotalPower)
+	require.Equal(t, uint64(60), alert.Diagnosis.VotedPower)
+	require.Equal(t, uint64(67), alert.Diagnosis.QuorumPower)
+	require.Equal(t, uint64(7), alert.Diagnosis.MissingPower)
+	require.Equal(t, []*types.VoterPower{{FpBtcPkHex: "pk2", Power: 40}}, alert.Diagnosis.MissingVoters)
+
+	// The same stall isn't alerted again
+	require.Same(t, episode, fg.checkStall(context.Background(), now.Add(stallCheckInterval), episode))
+	require.Len(t, alerts, 1)
+
+	// Resolved once a new block is finalized, however old its timestamp, as when catching up
+	require.NoError(t, dbHandler.InsertBlocks([]*types.Block{{
+		BlockHeight:    32,
+		BlockHash:      "0x20",
+		BlockTimestamp: uint64(now.Add(-10 * time.Minute).Unix()),
+	}}))
+	fg.lastFinalizedTime.Store(now.Add(2 * stallCheckInterval).UnixNano())
+	fg.l2HeadHeight.Store(33)
+	require.Nil(t, fg.checkStall(context.Background(), now.Add(2*stallCheckInterval), episode))
+	require.Len(t, alerts, 2)
+	require.Equal(t, types.AlertStatusResolved, alerts[1].Status)
+	require.Equal(t, alert.ID, alerts[1].ID)
+	require.Equal(t, uint64(32), alerts[1].LatestFinalizedHeight)
+}
@@ -0,0 +1,132 @@
+package finalitygadget
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/metrics"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"go.uber.org/zap"
+)
+
+const (
+	// webhookTimeout bounds each delivery attempt of an alert
+	webhookTimeout = 10 * time.Second
+	// webhookMaxAttempts is the number of delivery attempts of an alert to a webhook
+	webhookMaxAttempts = 5
+	// webhookInitialBackoff is the delay before retrying a failed delivery for the first time
+	webhookInitialBackoff = time.Second
+)
+
+// webhookNotifier delivers the alerts to the configured HTTP endpoints
+type webhookNotifier struct {
+	urls           []string
+	client         *http.Client
+	logger         *zap.Logger
+	initialBackoff time.Duration
+}
+
+//////////////////////////////
+// CONSTRUCTOR
+//////////////////////////////
+
+func newWebhookNotifier(urls []string, logger *zap.Logger) *webhookNotifier {
+	return &webhookNotifier{
+		urls:           urls,
+		client:         &http.Client{Timeout: webhookTimeout},
+		logger:         logger,
+		initialBackoff: webhookInitialBackoff,
+	}
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+// notify POSTs the alert as JSON to every webhook in parallel, and fails if any delivery failed
+func (n *webhookNotifier) notify(ctx context.Context, alert *types.StallAlert) error {
+	if n == nil || len(n.urls) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	errs := make([]error, len(n.urls))
+	var wg sync.WaitGroup
+	for i, url := range n.urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = n.deliver(ctx, url, body)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+/* deliver POSTs the alert to the webhook
+ *
+ * - network errors, 5xx and 429 responses are retried up to webhookMaxAttempts times, doubling the delay between
+ *   attempts with a random jitter
+ * - other responses outside of 2xx mean the webhook rejects the alert, and are not retried
+ */
+func (n *webhookNotifier) deliver(ctx context.Context, url string, body []byte) error {
+	backoff := n.initialBackoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := n.post(ctx, url, body)
+		if err == nil {
+			metrics.AlertWebhookDeliveriesTotal.WithLabelValues("success").Inc()
+			return nil
+		}
+		if !retryable || attempt == webhookMaxAttempts {
+			metrics.AlertWebhookDeliveriesTotal.WithLabelValues("failure").Inc()
+			n.logger.Error("Failed to deliver alert to webhook", zap.String("url", url), zap.Int("attempts", attempt), zap.Error(err))
+			return fmt.Errorf("failed to deliver alert to %s: %w", url, err)
+		}
+
+		delay := jitter(backoff)
+		n.logger.Warn("Failed to deliver alert to webhook, retrying",
+			zap.String("url", url),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", delay),
+			zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes a delivery attempt of the alert to the webhook, and returns whether it can be retried if it failed
+func (n *webhookNotifier) post(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	// drain the body so the connection is reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return true, nil
+	}
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("webhook answered %s", resp.Status)
+}
@@ -31,11 +31,102 @@ var (
 		Help: "Latest voting power of each finality provider",
 	}, []string{"fp_pubkey"})
 
+	// FpInvalidVotesTotal tracks the total number of votes by each FP rejected by local EOTS verification
+	FpInvalidVotesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "finality_gadget_fp_invalid_votes_total",
+		Help: "Total number of votes by each finality provider that failed local EOTS signature verification",
+	}, []string{"fp_pubkey"})
+
+	// FpPowerCacheHitsTotal tracks the total number of FP power table lookups served from the cache
+	FpPowerCacheHitsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "finality_gadget_fp_power_cache_hits_total",
+		Help: "The total number of finality provider power table lookups served from the cache",
+	})
+
+	// FpPowerCacheMissesTotal tracks the total number of FP power table lookups queried from Babylon
+	FpPowerCacheMissesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "finality_gadget_fp_power_cache_misses_total",
+		Help: "The total number of finality provider power table lookups that had to be queried from Babylon",
+	})
+
+	// BtcHeightLookupsTotal tracks the total number of L2 timestamp to BTC height lookups, by where they were resolved
+	BtcHeightLookupsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "finality_gadget_btc_height_lookups_total",
+		Help: "The total number of timestamp to BTC height lookups, by whether they were resolved from the local header index or over RPC",
+	}, []string{"source"})
+
+	// BtcHeaderIndexLatestHeight tracks the height of the latest BTC header in the local index
+	BtcHeaderIndexLatestHeight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "finality_gadget_btc_header_index_latest_height",
+		Help: "Height of the latest BTC header in the local timestamp-to-height index",
+	})
+
 	// LatestFinalizedBlockHeight tracks the height of the latest finalized block
 	LatestFinalizedBlockHeight = promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "finality_gadget_latest_finalized_block_height",
 		Help: "Height of the latest finalized block",
 	})
+
+	// L2ReorgsTotal tracks the total number of L2 reorgs detected below the latest finalized block
+	L2ReorgsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "finality_gadget_l2_reorgs_total",
+		Help: "The total number of L2 reorgs detected by the finality gadget",
+	})
+
+	// L2ReorgRolledBackBlocksTotal tracks the total number of stored blocks rolled back due to L2 reorgs
+	L2ReorgRolledBackBlocksTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "finality_gadget_l2_reorg_rolled_back_blocks_total",
+		Help: "The total number of finalized blocks removed from the db due to L2 reorgs",
+	})
+
+	// ProcessingQueueDepth tracks the heights dispatched to the block processing workers and not committed yet
+	ProcessingQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "finality_gadget_processing_queue_depth",
+		Help: "Number of heights being evaluated or waiting to be committed ahead of the commit cursor",
+	})
+
+	// ProcessingStageDuration tracks the latency of each stage of the block processing pipeline
+	ProcessingStageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "finality_gadget_processing_stage_duration_seconds",
+		Help:    "Latency of each attempt of a block processing stage (fetch, evaluate, reorg_check, commit)",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"stage"})
+
+	// ProcessingRetriesTotal tracks the failed attempts of each stage of the block processing pipeline
+	ProcessingRetriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "finality_gadget_processing_retries_total",
+		Help: "The total number of failed block processing stage attempts that were retried, by stage",
+	}, []string{"stage"})
+
+	// Degraded tracks whether some components of the finality gadget keep failing
+	Degraded = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "finality_gadget_degraded",
+		Help: "1 while some components of the finality gadget keep failing and are retried, 0 otherwise",
+	})
+
+	// FinalizationStalled tracks whether the finalization is stalled
+	FinalizationStalled = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "finality_gadget_finalization_stalled",
+		Help: "1 while the finalization is stalled according to the stall thresholds, 0 otherwise",
+	})
+
+	// StallMissingPower tracks the voting power missing to finalize the first block not finalized during a stall
+	StallMissingPower = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "finality_gadget_stall_missing_power",
+		Help: "Voting power missing to reach the quorum on the first block not finalized while the finalization is stalled",
+	})
+
+	// AlertWebhookDeliveriesTotal tracks the deliveries of alerts to the webhooks, by result
+	AlertWebhookDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "finality_gadget_alert_webhook_deliveries_total",
+		Help: "The total number of alert deliveries to the webhooks, by whether they succeeded or failed after all retries",
+	}, []string{"result"})
+
+	// PrunedBlocksTotal tracks the total number of finalized blocks pruned out of the retention window
+	PrunedBlocksTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "finality_gadget_pruned_blocks_total",
+		Help: "The total number of finalized blocks pruned from the db by the retention policy",
+	})
 )
 
 // Init initializes the metrics registry
@@ -46,5 +137,20 @@ func Init(logger *zap.Logger) {
 		zap.String("fp_voting_metric", "finality_gadget_fp_latest_block_voted"),
 		zap.String("fp_missed_blocks_metric", "finality_gadget_fp_missed_blocks_total"),
 		zap.String("fp_voting_power_metric", "finality_gadget_fp_latest_voting_power"),
-		zap.String("latest_finalized_metric", "finality_gadget_latest_finalized_block_height"))
+		zap.String("fp_invalid_votes_metric", "finality_gadget_fp_invalid_votes_total"),
+		zap.String("fp_power_cache_hits_metric", "finality_gadget_fp_power_cache_hits_total"),
+		zap.String("fp_power_cache_misses_metric", "finality_gadget_fp_power_cache_misses_total"),
+		zap.String("btc_height_lookups_metric", "finality_gadget_btc_height_lookups_total"),
+		zap.String("btc_header_index_latest_height_metric", "finality_gadget_btc_header_index_latest_height"),
+		zap.String("latest_finalized_metric", "finality_gadget_latest_finalized_block_height"),
+		zap.String("l2_reorgs_metric", "finality_gadget_l2_reorgs_total"),
+		zap.String("l2_reorg_rolled_back_blocks_metric", "finality_gadget_l2_reorg_rolled_back_blocks_total"),
+		zap.String("processing_queue_depth_metric", "finality_gadget_processing_queue_depth"),
+		zap.String("processing_stage_duration_metric", "finality_gadget_processing_stage_duration_seconds"),
+		zap.String("processing_retries_metric", "finality_gadget_processing_retries_total"),
+		zap.String("degraded_metric", "finality_gadget_degraded"),
+		zap.String("finalization_stalled_metric", "finality_gadget_finalization_stalled"),
+		zap.String("stall_missing_power_metric", "finality_gadget_stall_missing_power"),
+		zap.String("alert_webhook_deliveries_metric", "finality_gadget_alert_webhook_deliveries_total"),
+		zap.String("pruned_blocks_metric", "finality_gadget_pruned_blocks_total"))
 }
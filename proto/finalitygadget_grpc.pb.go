@@ -25,6 +25,11 @@ const (
 	FinalityGadget_QueryIsBlockFinalizedByHeight_FullMethodName     = "/proto.FinalityGadget/QueryIsBlockFinalizedByHeight"
 	FinalityGadget_QueryIsBlockFinalizedByHash_FullMethodName       = "/proto.FinalityGadget/QueryIsBlockFinalizedByHash"
 	FinalityGadget_QueryLatestFinalizedBlock_FullMethodName         = "/proto.FinalityGadget/QueryLatestFinalizedBlock"
+	FinalityGadget_QueryFinalityEvidenceByHeight_FullMethodName     = "/proto.FinalityGadget/QueryFinalityEvidenceByHeight"
+	FinalityGadget_QueryFinalityEvidenceByHash_FullMethodName       = "/proto.FinalityGadget/QueryFinalityEvidenceByHash"
+	FinalityGadget_QueryFinalityProof_FullMethodName                = "/proto.FinalityGadget/QueryFinalityProof"
+	FinalityGadget_ListFinalizedBlocks_FullMethodName               = "/proto.FinalityGadget/ListFinalizedBlocks"
+	FinalityGadget_SubscribeFinalizedBlocks_FullMethodName          = "/proto.FinalityGadget/SubscribeFinalizedBlocks"
 )
 
 // FinalityGadgetClient is the client API for FinalityGadget service.
@@ -49,6 +54,21 @@ type FinalityGadgetClient interface {
 	// QueryLatestFinalizedBlock returns the latest consecutively finalized block
 	// by querying the local db
 	QueryLatestFinalizedBlock(ctx context.Context, in *QueryLatestFinalizedBlockRequest, opts ...grpc.CallOption) (*QueryBlockResponse, error)
+	// QueryFinalityEvidenceByHeight returns the finality evidence of a finalized
+	// block at given height by querying the local db
+	QueryFinalityEvidenceByHeight(ctx context.Context, in *QueryFinalityEvidenceByHeightRequest, opts ...grpc.CallOption) (*QueryFinalityEvidenceResponse, error)
+	// QueryFinalityEvidenceByHash returns the finality evidence of a finalized
+	// block with given hash by querying the local db
+	QueryFinalityEvidenceByHash(ctx context.Context, in *QueryFinalityEvidenceByHashRequest, opts ...grpc.CallOption) (*QueryFinalityEvidenceResponse, error)
+	// QueryFinalityProof returns a self-contained proof bundle that the block
+	// at given height is finalized, re-querying Babylon at the resolved heights
+	QueryFinalityProof(ctx context.Context, in *QueryFinalityProofRequest, opts ...grpc.CallOption) (*QueryFinalityProofResponse, error)
+	// ListFinalizedBlocks returns a page of the finalized blocks within a
+	// height and timestamp range by querying the local db
+	ListFinalizedBlocks(ctx context.Context, in *ListFinalizedBlocksRequest, opts ...grpc.CallOption) (*ListFinalizedBlocksResponse, error)
+	// SubscribeFinalizedBlocks replays the finalized blocks from the given
+	// height and then streams new ones as they are finalized
+	SubscribeFinalizedBlocks(ctx context.Context, in *SubscribeFinalizedBlocksRequest, opts ...grpc.CallOption) (FinalityGadget_SubscribeFinalizedBlocksClient, error)
 }
 
 type finalityGadgetClient struct {
@@ -113,6 +133,74 @@ func (c *finalityGadgetClient) QueryLatestFinalizedBlock(ctx context.Context, in
 	return out, nil
 }
 
+func (c *finalityGadgetClient) QueryFinalityEvidenceByHeight(ctx context.Context, in *QueryFinalityEvidenceByHeightRequest, opts ...grpc.CallOption) (*QueryFinalityEvidenceResponse, error) {
+	out := new(QueryFinalityEvidenceResponse)
+	err := c.cc.Invoke(ctx, FinalityGadget_QueryFinalityEvidenceByHeight_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *finalityGadgetClient) QueryFinalityEvidenceByHash(ctx context.Context, in *QueryFinalityEvidenceByHashRequest, opts ...grpc.CallOption) (*QueryFinalityEvidenceResponse, error) {
+	out := new(QueryFinalityEvidenceResponse)
+	err := c.cc.Invoke(ctx, FinalityGadget_QueryFinalityEvidenceByHash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *finalityGadgetClient) QueryFinalityProof(ctx context.Context, in *QueryFinalityProofRequest, opts ...grpc.CallOption) (*QueryFinalityProofResponse, error) {
+	out := new(QueryFinalityProofResponse)
+	err := c.cc.Invoke(ctx, FinalityGadget_QueryFinalityProof_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *finalityGadgetClient) ListFinalizedBlocks(ctx context.Context, in *ListFinalizedBlocksRequest, opts ...grpc.CallOption) (*ListFinalizedBlocksResponse, error) {
+	out := new(ListFinalizedBlocksResponse)
+	err := c.cc.Invoke(ctx, FinalityGadget_ListFinalizedBlocks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *finalityGadgetClient) SubscribeFinalizedBlocks(ctx context.Context, in *SubscribeFinalizedBlocksRequest, opts ...grpc.CallOption) (FinalityGadget_SubscribeFinalizedBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FinalityGadget_ServiceDesc.Streams[0], FinalityGadget_SubscribeFinalizedBlocks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &finalityGadgetSubscribeFinalizedBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FinalityGadget_SubscribeFinalizedBlocksClient interface {
+	Recv() (*QueryBlockResponse, error)
+	grpc.ClientStream
+}
+
+type finalityGadgetSubscribeFinalizedBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *finalityGadgetSubscribeFinalizedBlocksClient) Recv() (*QueryBlockResponse, error) {
+	m := new(QueryBlockResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // FinalityGadgetServer is the server API for FinalityGadget service.
 // All implementations must embed UnimplementedFinalityGadgetServer
 // for forward compatibility
@@ -135,6 +223,21 @@ type FinalityGadgetServer interface {
 	// QueryLatestFinalizedBlock returns the latest consecutively finalized block
 	// by querying the local db
 	QueryLatestFinalizedBlock(context.Context, *QueryLatestFinalizedBlockRequest) (*QueryBlockResponse, error)
+	// QueryFinalityEvidenceByHeight returns the finality evidence of a finalized
+	// block at given height by querying the local db
+	QueryFinalityEvidenceByHeight(context.Context, *QueryFinalityEvidenceByHeightRequest) (*QueryFinalityEvidenceResponse, error)
+	// QueryFinalityEvidenceByHash returns the finality evidence of a finalized
+	// block with given hash by querying the local db
+	QueryFinalityEvidenceByHash(context.Context, *QueryFinalityEvidenceByHashRequest) (*QueryFinalityEvidenceResponse, error)
+	// QueryFinalityProof returns a self-contained proof bundle that the block
+	// at given height is finalized, re-querying Babylon at the resolved heights
+	QueryFinalityProof(context.Context, *QueryFinalityProofRequest) (*QueryFinalityProofResponse, error)
+	// ListFinalizedBlocks returns a page of the finalized blocks within a
+	// height and timestamp range by querying the local db
+	ListFinalizedBlocks(context.Context, *ListFinalizedBlocksRequest) (*ListFinalizedBlocksResponse, error)
+	// SubscribeFinalizedBlocks replays the finalized blocks from the given
+	// height and then streams new ones as they are finalized
+	SubscribeFinalizedBlocks(*SubscribeFinalizedBlocksRequest, FinalityGadget_SubscribeFinalizedBlocksServer) error
 	mustEmbedUnimplementedFinalityGadgetServer()
 }
 
@@ -160,6 +263,21 @@ func (UnimplementedFinalityGadgetServer) QueryIsBlockFinalizedByHash(context.Con
 func (UnimplementedFinalityGadgetServer) QueryLatestFinalizedBlock(context.Context, *QueryLatestFinalizedBlockRequest) (*QueryBlockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method QueryLatestFinalizedBlock not implemented")
 }
+func (UnimplementedFinalityGadgetServer) QueryFinalityEvidenceByHeight(context.Context, *QueryFinalityEvidenceByHeightRequest) (*QueryFinalityEvidenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryFinalityEvidenceByHeight not implemented")
+}
+func (UnimplementedFinalityGadgetServer) QueryFinalityEvidenceByHash(context.Context, *QueryFinalityEvidenceByHashRequest) (*QueryFinalityEvidenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryFinalityEvidenceByHash not implemented")
+}
+func (UnimplementedFinalityGadgetServer) QueryFinalityProof(context.Context, *QueryFinalityProofRequest) (*QueryFinalityProofResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryFinalityProof not implemented")
+}
+func (UnimplementedFinalityGadgetServer) ListFinalizedBlocks(context.Context, *ListFinalizedBlocksRequest) (*ListFinalizedBlocksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFinalizedBlocks not implemented")
+}
+func (UnimplementedFinalityGadgetServer) SubscribeFinalizedBlocks(*SubscribeFinalizedBlocksRequest, FinalityGadget_SubscribeFinalizedBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeFinalizedBlocks not implemented")
+}
 func (UnimplementedFinalityGadgetServer) mustEmbedUnimplementedFinalityGadgetServer() {}
 
 // UnsafeFinalityGadgetServer may be embedded to opt out of forward compatibility for this service.
@@ -281,6 +399,99 @@ func _FinalityGadget_QueryLatestFinalizedBlock_Handler(srv interface{}, ctx cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FinalityGadget_QueryFinalityEvidenceByHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryFinalityEvidenceByHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FinalityGadgetServer).QueryFinalityEvidenceByHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FinalityGadget_QueryFinalityEvidenceByHeight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FinalityGadgetServer).QueryFinalityEvidenceByHeight(ctx, req.(*QueryFinalityEvidenceByHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FinalityGadget_QueryFinalityEvidenceByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryFinalityEvidenceByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FinalityGadgetServer).QueryFinalityEvidenceByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FinalityGadget_QueryFinalityEvidenceByHash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FinalityGadgetServer).QueryFinalityEvidenceByHash(ctx, req.(*QueryFinalityEvidenceByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FinalityGadget_QueryFinalityProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryFinalityProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FinalityGadgetServer).QueryFinalityProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FinalityGadget_QueryFinalityProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FinalityGadgetServer).QueryFinalityProof(ctx, req.(*QueryFinalityProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FinalityGadget_ListFinalizedBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFinalizedBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FinalityGadgetServer).ListFinalizedBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FinalityGadget_ListFinalizedBlocks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FinalityGadgetServer).ListFinalizedBlocks(ctx, req.(*ListFinalizedBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FinalityGadget_SubscribeFinalizedBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeFinalizedBlocksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FinalityGadgetServer).SubscribeFinalizedBlocks(m, &finalityGadgetSubscribeFinalizedBlocksServer{stream})
+}
+
+type FinalityGadget_SubscribeFinalizedBlocksServer interface {
+	Send(*QueryBlockResponse) error
+	grpc.ServerStream
+}
+
+type finalityGadgetSubscribeFinalizedBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *finalityGadgetSubscribeFinalizedBlocksServer) Send(m *QueryBlockResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // FinalityGadget_ServiceDesc is the grpc.ServiceDesc for FinalityGadget service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -312,7 +523,29 @@ var FinalityGadget_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "QueryLatestFinalizedBlock",
 			Handler:    _FinalityGadget_QueryLatestFinalizedBlock_Handler,
 		},
+		{
+			MethodName: "QueryFinalityEvidenceByHeight",
+			Handler:    _FinalityGadget_QueryFinalityEvidenceByHeight_Handler,
+		},
+		{
+			MethodName: "QueryFinalityEvidenceByHash",
+			Handler:    _FinalityGadget_QueryFinalityEvidenceByHash_Handler,
+		},
+		{
+			MethodName: "QueryFinalityProof",
+			Handler:    _FinalityGadget_QueryFinalityProof_Handler,
+		},
+		{
+			MethodName: "ListFinalizedBlocks",
+			Handler:    _FinalityGadget_ListFinalizedBlocks_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeFinalizedBlocks",
+			Handler:       _FinalityGadget_SubscribeFinalizedBlocks_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/finalitygadget.proto",
 }
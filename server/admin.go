@@ -0,0 +1,56 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/db"
+	"go.uber.org/zap"
+)
+
+// AdminSnapshotPath is the admin HTTP endpoint streaming a snapshot of the DB
+const AdminSnapshotPath = "/admin/v1/snapshot"
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+// withAdminAuth only lets through the requests bearing the admin token of the config
+func (s *Server) withAdminAuth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.AdminToken)) != 1 {
+			s.logger.Warn("Unauthorized admin request", zap.String("path", r.URL.Path), zap.String("remote_addr", r.RemoteAddr))
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+/* snapshotHandler streams a snapshot of the DB, taken from a read transaction while blocks keep being processed
+ *
+ * - the response is the snapshot archive, to be restored with `opfgd db restore`
+ * - the backend must support snapshots, only bbolt does
+ * - errors once the snapshot started streaming can't change the status anymore, they're logged and the
+ *   truncated archive fails to restore
+ */
+func (s *Server) snapshotHandler(w http.ResponseWriter, r *http.Request) {
+	s.logger.Debug("snapshot request", zap.String("path", AdminSnapshotPath))
+
+	snapshotter, ok := s.db.(db.Snapshotter)
+	if !ok {
+		http.Error(w, fmt.Sprintf("the %s DB backend doesn't support snapshots", s.cfg.DBBackend), http.StatusNotImplemented)
+		return
+	}
+
+	filename := fmt.Sprintf("finality-gadget-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if _, err := snapshotter.WriteSnapshot(w, s.cfg.BBNChainID, s.cfg.FGContractAddress); err != nil {
+		s.logger.Error("Failed to stream DB snapshot", zap.Error(err), zap.String("remote_addr", r.RemoteAddr))
+	}
+}
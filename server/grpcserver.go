@@ -149,3 +149,158 @@ func (s *Server) QueryLatestFinalizedBlock(ctx context.Context, req *proto.Query
 		},
 	}, nil
 }
+
+// QueryFinalityEvidenceByHeight is an RPC method that returns the finality evidence of a finalized block at a given height.
+func (s *Server) QueryFinalityEvidenceByHeight(ctx context.Context, req *proto.QueryFinalityEvidenceByHeightRequest) (*proto.QueryFinalityEvidenceResponse, error) {
+	s.logger.Debug(
+		"QueryFinalityEvidenceByHeight request",
+		zap.Uint64("blockHeight", req.BlockHeight),
+	)
+	evidence, err := s.fg.GetFinalityEvidenceByHeight(req.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.QueryFinalityEvidenceResponse{Evidence: toProtoFinalityEvidence(evidence)}, nil
+}
+
+// QueryFinalityEvidenceByHash is an RPC method that returns the finality evidence of a finalized block with a given hash.
+func (s *Server) QueryFinalityEvidenceByHash(ctx context.Context, req *proto.QueryFinalityEvidenceByHashRequest) (*proto.QueryFinalityEvidenceResponse, error) {
+	s.logger.Debug(
+		"QueryFinalityEvidenceByHash request",
+		zap.String("blockHash", req.BlockHash),
+	)
+	evidence, err := s.fg.GetFinalityEvidenceByHash(req.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.QueryFinalityEvidenceResponse{Evidence: toProtoFinalityEvidence(evidence)}, nil
+}
+
+func toProtoFinalityEvidence(evidence *types.FinalityEvidence) *proto.FinalityEvidence {
+	voters := make([]*proto.VoterPower, 0, len(evidence.Voters))
+	for _, voter := range evidence.Voters {
+		voters = append(voters, &proto.VoterPower{
+			FpBtcPkHex: voter.FpBtcPkHex,
+			Power:      voter.Power,
+		})
+	}
+
+	return &proto.FinalityEvidence{
+		BlockHash:     evidence.BlockHash,
+		BlockHeight:   evidence.BlockHeight,
+		BabylonHeight: evidence.BabylonHeight,
+		BtcHeight:     evidence.BtcHeight,
+		TotalPower:    evidence.TotalPower,
+		VotedPower:    evidence.VotedPower,
+		Voters:        voters,
+		QuorumRatio:   evidence.QuorumRatio,
+		QuorumPower:   evidence.QuorumPower,
+	}
+}
+
+// QueryFinalityProof is an RPC method that returns a self-contained proof bundle that the block at a given height is finalized.
+func (s *Server) QueryFinalityProof(ctx context.Context, req *proto.QueryFinalityProofRequest) (*proto.QueryFinalityProofResponse, error) {
+	s.logger.Debug(
+		"QueryFinalityProof request",
+		zap.Uint64("blockHeight", req.BlockHeight),
+	)
+	proof, err := s.fg.QueryFinalityProof(req.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.QueryFinalityProofResponse{Proof: toProtoFinalityProof(proof)}, nil
+}
+
+func toProtoFinalityProof(proof *types.FinalityProof) *proto.FinalityProof {
+	fps := make([]*proto.VoterPower, 0, len(proof.FinalityProviders))
+	for _, fp := range proof.FinalityProviders {
+		fps = append(fps, &proto.VoterPower{
+			FpBtcPkHex: fp.FpBtcPkHex,
+			Power:      fp.Power,
+		})
+	}
+	votes := make([]*proto.FinalityVote, 0, len(proof.Votes))
+	for _, vote := range proof.Votes {
+		votes = append(votes, &proto.FinalityVote{
+			FpBtcPkHex:        vote.FpBtcPkHex,
+			PubRand:           vote.PubRand,
+			FinalitySignature: vote.FinalitySignature,
+		})
+	}
+
+	return &proto.FinalityProof{
+		Version:    proof.Version,
+		ConsumerId: proof.ConsumerId,
+		Block: &proto.BlockInfo{
+			BlockHash:      proof.BlockHash,
+			BlockHeight:    proof.BlockHeight,
+			BlockTimestamp: proof.BlockTimestamp,
+		},
+		BabylonHeight:      proof.BabylonHeight,
+		BtcHeight:          proof.BtcHeight,
+		VotesBabylonHeight: proof.VotesBabylonHeight,
+		TotalPower:         proof.TotalPower,
+		FinalityProviders:  fps,
+		Votes:              votes,
+		SignaturesVerified: proof.SignaturesVerified,
+	}
+}
+
+// ListFinalizedBlocks is an RPC method that returns a page of the finalized blocks within a height and timestamp range.
+func (s *Server) ListFinalizedBlocks(ctx context.Context, req *proto.ListFinalizedBlocksRequest) (*proto.ListFinalizedBlocksResponse, error) {
+	s.logger.Debug(
+		"ListFinalizedBlocks request",
+		zap.Uint64("fromHeight", req.FromHeight),
+		zap.Uint64("toHeight", req.ToHeight),
+		zap.Uint64("fromTimestamp", req.FromTimestamp),
+		zap.Uint64("toTimestamp", req.ToTimestamp),
+		zap.Bool("descending", req.Descending),
+		zap.Uint64("limit", req.Limit),
+	)
+	page, err := s.fg.ListFinalizedBlocks(types.BlockQuery{
+		FromHeight:    req.FromHeight,
+		ToHeight:      req.ToHeight,
+		FromTimestamp: req.FromTimestamp,
+		ToTimestamp:   req.ToTimestamp,
+		Descending:    req.Descending,
+		Limit:         req.Limit,
+	}, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &proto.ListFinalizedBlocksResponse{NextCursor: page.NextCursor}
+	for _, block := range page.Blocks {
+		res.Blocks = append(res.Blocks, &proto.BlockInfo{
+			BlockHash:      block.BlockHash,
+			BlockHeight:    block.BlockHeight,
+			BlockTimestamp: block.BlockTimestamp,
+		})
+	}
+	return res, nil
+}
+
+// SubscribeFinalizedBlocks is a streaming RPC method that replays finalized blocks from a given height and then pushes new ones.
+func (s *Server) SubscribeFinalizedBlocks(req *proto.SubscribeFinalizedBlocksRequest, stream proto.FinalityGadget_SubscribeFinalizedBlocksServer) error {
+	s.logger.Debug(
+		"SubscribeFinalizedBlocks request",
+		zap.Uint64("fromHeight", req.FromHeight),
+	)
+	// the subscription ends on server shutdown, as the gRPC server waits for it to stop gracefully
+	ctx, cancel := s.streamContext(stream.Context())
+	defer cancel()
+	err := s.fg.SubscribeFinalizedBlocks(ctx, req.FromHeight, func(block *types.Block) error {
+		return stream.Send(&proto.QueryBlockResponse{
+			Block: &proto.BlockInfo{
+				BlockHash:      block.BlockHash,
+				BlockHeight:    block.BlockHeight,
+				BlockTimestamp: block.BlockTimestamp,
+			},
+		})
+	})
+	s.logger.Debug("SubscribeFinalizedBlocks stream closed", zap.Error(err))
+	return err
+}
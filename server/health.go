@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// grpcHealthInterval is the interval between two updates of the gRPC health service, which checks every
+	// dependency of the finality gadget
+	grpcHealthInterval = 10 * time.Second
+	// GrpcLivenessService is the gRPC health service name reporting the liveness of the finality gadget, the
+	// overall "" service and the finality gadget service reporting its readiness
+	GrpcLivenessService = "liveness"
+)
+
+func (s *Server) healthLiveHandler(w http.ResponseWriter, r *http.Request) {
+	s.logger.Debug(
+		"liveness request",
+		zap.String("path", "/health/live"),
+	)
+	status := s.fg.QueryLiveness()
+	s.writeHealthResponse(w, status.Alive, status)
+}
+
+func (s *Server) healthReadyHandler(w http.ResponseWriter, r *http.Request) {
+	s.logger.Debug(
+		"readiness request",
+		zap.String("path", "/health/ready"),
+	)
+	status := s.fg.QueryReadiness(r.Context())
+	s.writeHealthResponse(w, status.Ready, status)
+}
+
+// writeHealthResponse writes the status of a probe as JSON, with a 503 status code if the probe fails
+func (s *Server) writeHealthResponse(w http.ResponseWriter, ok bool, status any) {
+	jsonResponse, err := json.Marshal(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_, err = w.Write(jsonResponse)
+	if err != nil {
+		s.logger.Error("Failed to write response", zap.Error(err))
+	}
+}
+
+// newGrpcHealthServer returns the gRPC health service, every service being not serving until the first update
+func newGrpcHealthServer() *health.Server {
+	healthServer := health.NewServer()
+	for _, service := range []string{"", proto.FinalityGadget_ServiceDesc.ServiceName, GrpcLivenessService} {
+		healthServer.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return healthServer
+}
+
+// pollGrpcHealth updates the serving status of the gRPC health service every grpcHealthInterval until ctx is done
+func (s *Server) pollGrpcHealth(ctx context.Context) {
+	ticker := time.NewTicker(grpcHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		liveness := healthpb.HealthCheckResponse_NOT_SERVING
+		if s.fg.QueryLiveness().Alive {
+			liveness = healthpb.HealthCheckResponse_SERVING
+		}
+		s.healthServer.SetServingStatus(GrpcLivenessService, liveness)
+
+		readiness := healthpb.HealthCheckResponse_NOT_SERVING
+		if status := s.fg.QueryReadiness(ctx); status.Ready {
+			readiness = healthpb.HealthCheckResponse_SERVING
+		} else {
+			s.logger.Debug("Finality gadget not ready", zap.Strings("reasons", status.Reasons))
+		}
+		s.healthServer.SetServingStatus("", readiness)
+		s.healthServer.SetServingStatus(proto.FinalityGadget_ServiceDesc.ServiceName, readiness)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -2,8 +2,13 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/babylonlabs-io/finality-gadget/types"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
@@ -12,8 +17,18 @@ func (s *Server) newHttpHandler() http.Handler {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/v1/transaction", s.txStatusHandler)
 	mux.HandleFunc("/v1/chainSyncStatus", s.chainSyncStatusHandler)
+	mux.HandleFunc("/v1/evidence", s.finalityEvidenceHandler)
+	mux.HandleFunc("/v1/proof", s.finalityProofHandler)
+	mux.HandleFunc("/v1/blocks", s.blocksHandler)
+	mux.HandleFunc("/v1/stream", s.streamHandler)
 	mux.HandleFunc("/health", s.healthHandler)
+	mux.HandleFunc("/health/live", s.healthLiveHandler)
+	mux.HandleFunc("/health/ready", s.healthReadyHandler)
 	mux.Handle("/metrics", promhttp.Handler())
+	// The admin endpoints are only served when an admin token is set
+	if s.cfg.AdminToken != "" {
+		mux.HandleFunc(AdminSnapshotPath, s.withAdminAuth(s.snapshotHandler))
+	}
 	return mux
 }
 
@@ -74,12 +89,185 @@ func (s *Server) chainSyncStatusHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+func (s *Server) finalityEvidenceHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract query parameters, the block can be selected either by height or by hash
+	heightParam := r.URL.Query().Get("height")
+	hashParam := r.URL.Query().Get("hash")
+	s.logger.Debug("finality evidence request",
+		zap.String("path", "/v1/evidence"),
+		zap.String("method", r.Method),
+		zap.String("height", heightParam),
+		zap.String("hash", hashParam),
+		zap.String("remoteAddr", r.RemoteAddr),
+	)
+
+	var evidence *types.FinalityEvidence
+	var err error
+	switch {
+	case hashParam != "":
+		evidence, err = s.fg.GetFinalityEvidenceByHash(hashParam)
+	case heightParam != "":
+		height, parseErr := strconv.ParseUint(heightParam, 10, 64)
+		if parseErr != nil {
+			http.Error(w, "invalid height: "+parseErr.Error(), http.StatusBadRequest)
+			return
+		}
+		evidence, err = s.fg.GetFinalityEvidenceByHeight(height)
+	default:
+		http.Error(w, "either height or hash is required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, types.ErrEvidenceNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse, err := json.Marshal(evidence)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(jsonResponse)
+	if err != nil {
+		s.logger.Error("Failed to write response", zap.Error(err))
+	}
+}
+
+func (s *Server) finalityProofHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract query parameters
+	heightParam := r.URL.Query().Get("height")
+	s.logger.Debug("finality proof request",
+		zap.String("path", "/v1/proof"),
+		zap.String("method", r.Method),
+		zap.String("height", heightParam),
+		zap.String("remoteAddr", r.RemoteAddr),
+	)
+
+	height, err := strconv.ParseUint(heightParam, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid height: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	proof, err := s.fg.QueryFinalityProof(height)
+	if err != nil {
+		if errors.Is(err, types.ErrBlockNotFound) || errors.Is(err, types.ErrBlockNotFinalized) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse, err := json.Marshal(proof)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(jsonResponse)
+	if err != nil {
+		s.logger.Error("Failed to write response", zap.Error(err))
+	}
+}
+
+func (s *Server) blocksHandler(w http.ResponseWriter, r *http.Request) {
+	// Extract query parameters, all optional
+	params := r.URL.Query()
+	s.logger.Debug("blocks request",
+		zap.String("path", "/v1/blocks"),
+		zap.String("method", r.Method),
+		zap.String("query", r.URL.RawQuery),
+		zap.String("remoteAddr", r.RemoteAddr),
+	)
+
+	var query types.BlockQuery
+	for _, param := range []struct {
+		name  string
+		value *uint64
+	}{
+		{"from_height", &query.FromHeight},
+		{"to_height", &query.ToHeight},
+		{"from_timestamp", &query.FromTimestamp},
+		{"to_timestamp", &query.ToTimestamp},
+		{"limit", &query.Limit},
+	} {
+		if value := params.Get(param.name); value != "" {
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid "+param.name+": "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			*param.value = parsed
+		}
+	}
+	switch params.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		http.Error(w, "order must be asc or desc", http.StatusBadRequest)
+		return
+	}
+
+	page, err := s.fg.ListFinalizedBlocks(query, params.Get("cursor"))
+	if err != nil {
+		if errors.Is(err, types.ErrInvalidBlockRange) || errors.Is(err, types.ErrInvalidCursor) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// An empty page is listed as an empty array rather than null
+	if page.Blocks == nil {
+		page.Blocks = []*types.Block{}
+	}
+
+	jsonResponse, err := json.Marshal(page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(jsonResponse)
+	if err != nil {
+		s.logger.Error("Failed to write response", zap.Error(err))
+	}
+}
+
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	s.logger.Debug(
 		"health request",
 		zap.String("path", "/health"),
 	)
+	// A degraded finality gadget keeps retrying the failing upstream calls, so it stays healthy for the liveness
+	// probes pointing at /health, which would restart it on a short upstream outage. /health/ready fails instead
+	// once the finality served is stale
+	status := s.fg.QueryHealthStatus()
 	response := "Finality gadget is healthy"
+	if status.Degraded {
+		response = "Finality gadget is degraded"
+		for _, failure := range status.Failures {
+			response += fmt.Sprintf("\n%s: %d failures since %s",
+				failure.Component, failure.Failures, time.Unix(int64(failure.Since), 0).UTC().Format(time.RFC3339)) // #nosec G115
+			if failure.Upstream != "" {
+				response += fmt.Sprintf(" calling %s", failure.Upstream)
+			}
+			response += ": " + failure.Error
+		}
+	}
 	_, err := w.Write([]byte(response))
 	if err != nil {
 		s.logger.Error("Failed to write response", zap.Error(err))
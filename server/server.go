@@ -17,6 +17,14 @@ import (
 	"go.uber.org/zap"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// serverShutdownTimeout bounds how long the servers wait for the pending requests to complete on shutdown,
+	// before closing the remaining connections
+	serverShutdownTimeout = 10 * time.Second
 )
 
 // Server is the main daemon construct for the finality gadget server. It
@@ -25,25 +33,34 @@ import (
 type Server struct {
 	proto.UnimplementedFinalityGadgetServer
 
-	grpcServer  *grpc.Server
-	httpServer  *http.Server
-	fg          finalitygadget.IFinalityGadget
-	cfg         *config.Config
-	db          db.IDatabaseHandler
-	logger      *zap.Logger
-	interceptor signal.Interceptor
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+	httpServer   *http.Server
+	fg           finalitygadget.IFinalityGadget
+	cfg          *config.Config
+	db           db.IDatabaseHandler
+	logger       *zap.Logger
+	interceptor  signal.Interceptor
+	syncStatus   syncStatusFeed
+
+	// shutdownCtx is done once the server shuts down, which ends the long-lived streams
+	shutdownCtx    context.Context
+	cancelShutdown context.CancelFunc
 
 	started int32
 }
 
 // NewFinalityGadgetServer creates a new server with the given config.
 func NewFinalityGadgetServer(cfg *config.Config, db db.IDatabaseHandler, fg finalitygadget.IFinalityGadget, sig signal.Interceptor, logger *zap.Logger) *Server {
+	shutdownCtx, cancelShutdown := context.WithCancel(context.Background())
 	return &Server{
-		fg:          fg,
-		cfg:         cfg,
-		db:          db,
-		logger:      logger,
-		interceptor: sig,
+		fg:             fg,
+		cfg:            cfg,
+		db:             db,
+		logger:         logger,
+		interceptor:    sig,
+		shutdownCtx:    shutdownCtx,
+		cancelShutdown: cancelShutdown,
 	}
 }
 
@@ -54,15 +71,7 @@ func (s *Server) RunUntilShutdown() error {
 		return nil
 	}
 
-	defer func() {
-		s.logger.Info("Closing database...")
-		if err := s.db.Close(); err != nil {
-			s.logger.Error("Failed to close database", zap.Error(err))
-		} else {
-			s.logger.Info("Database closed")
-		}
-	}()
-
+	// the DB is closed by the daemon, once the finality gadget writing to it has stopped too
 	if err := s.startGrpcServer(); err != nil {
 		return fmt.Errorf("failed to start gRPC listener: %v", err)
 	}
@@ -71,19 +80,60 @@ func (s *Server) RunUntilShutdown() error {
 		return fmt.Errorf("failed to start HTTP server: %v", err)
 	}
 
+	// Feed chain sync status changes to /v1/stream connections
+	go s.pollSyncStatus(s.shutdownCtx)
+
+	// Keep the gRPC health service up to date
+	go s.pollGrpcHealth(s.shutdownCtx)
+
 	s.logger.Info("Finality gadget is active")
 
 	// Wait for shutdown signal from either a graceful server stop or from
 	// the interrupt handler.
 	<-s.interceptor.ShutdownChannel()
 
-	// shutdown servers
-	s.grpcServer.GracefulStop()
-	if err := s.httpServer.Shutdown(context.Background()); err != nil {
-		s.logger.Error("Error shutting down HTTP server", zap.Error(err))
+	s.shutdown()
+	return nil
+}
+
+/* shutdown stops the servers
+ *
+ * - the long-lived streams are ended first, as the servers wait for the pending requests to complete
+ * - the gRPC services are reported as not serving anymore before the gRPC server stops
+ * - each server closes its remaining connections if the pending requests don't complete within
+ *   serverShutdownTimeout
+ */
+func (s *Server) shutdown() {
+	s.cancelShutdown()
+	s.healthServer.Shutdown()
+	s.stopGrpcServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		s.logger.Error("Error shutting down HTTP server, closing remaining connections", zap.Error(err))
+		if err := s.httpServer.Close(); err != nil {
+			s.logger.Error("Error closing HTTP server", zap.Error(err))
+		}
 	}
+}
 
-	return nil
+// stopGrpcServer stops the gRPC server gracefully, and forcibly if the pending RPCs don't complete within
+// serverShutdownTimeout
+func (s *Server) stopGrpcServer() {
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(serverShutdownTimeout):
+		s.logger.Error("Timed out stopping gRPC server gracefully, closing remaining connections")
+		s.grpcServer.Stop()
+		<-stopped
+	}
 }
 
 func (s *Server) startGrpcServer() error {
@@ -94,6 +144,8 @@ func (s *Server) startGrpcServer() error {
 
 	grpcServer := grpc.NewServer()
 	proto.RegisterFinalityGadgetServer(grpcServer, s)
+	healthServer := newGrpcHealthServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
 
 	listenerReady := make(chan struct{})
 	// TODO: handle errors if grpcServer.Serve fails in the goroutine
@@ -106,6 +158,7 @@ func (s *Server) startGrpcServer() error {
 	}()
 	<-listenerReady
 	s.grpcServer = grpcServer
+	s.healthServer = healthServer
 	return nil
 }
 
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/config"
+	"github.com/babylonlabs-io/finality-gadget/proto"
+	"github.com/babylonlabs-io/finality-gadget/testutil/mocks"
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/lightningnetwork/lnd/signal"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// freeAddress returns a local address with a port free to listen on
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func TestShutdownWithSubscriber(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	// the subscription only ends once its context is done
+	subscribed := make(chan struct{})
+	mockFg := mocks.NewMockIFinalityGadget(ctl)
+	mockFg.EXPECT().SubscribeFinalizedBlocks(gomock.Any(), uint64(10), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, _ uint64, _ func(*types.Block) error) error {
+			close(subscribed)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	cfg := &config.Config{GRPCListener: freeAddress(t), HTTPListener: freeAddress(t)}
+	s := NewFinalityGadgetServer(cfg, nil, mockFg, signal.Interceptor{}, zap.NewNop())
+	require.NoError(t, s.startGrpcServer())
+	require.NoError(t, s.startHttpServer())
+
+	conn, err := grpc.NewClient(cfg.GRPCListener, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	stream, err := proto.NewFinalityGadgetClient(conn).SubscribeFinalizedBlocks(
+		context.Background(), &proto.SubscribeFinalizedBlocksRequest{FromHeight: 10})
+	require.NoError(t, err)
+	select {
+	case <-subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not started")
+	}
+
+	// The shutdown ends the subscription instead of waiting for the subscriber to go away
+	stopped := make(chan struct{})
+	go func() {
+		s.shutdown()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(serverShutdownTimeout / 2):
+		t.Fatal("shutdown blocked by the subscriber")
+	}
+	_, err = stream.Recv()
+	require.Error(t, err)
+}
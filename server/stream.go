@@ -0,0 +1,304 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/babylonlabs-io/finality-gadget/types"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// streamKeepAliveInterval is how often idle stream connections are pinged, so proxies keep them open
+	streamKeepAliveInterval = 15 * time.Second
+	// streamWriteTimeout bounds how long a single event write to a stream connection may take
+	streamWriteTimeout = 10 * time.Second
+)
+
+var wsUpgrader = websocket.Upgrader{
+	// CORS already allows any origin on the HTTP server
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// syncStatusFeed polls the chain sync status once per interval for all stream connections,
+// so the number of connected clients doesn't multiply the load on the L2 RPC
+type syncStatusFeed struct {
+	mutex       sync.Mutex
+	latest      *types.ChainSyncStatus
+	subscribers map[chan *types.ChainSyncStatus]struct{}
+}
+
+//////////////////////////////
+// METHODS
+//////////////////////////////
+
+/* streamHandler serves a live feed of finality events on /v1/stream
+ *
+ * - WebSocket upgrade requests get one JSON text message per event, other requests get Server-Sent Events
+ * - events are finalized blocks, rollbacks of finalized blocks due to L2 reorgs, and chain sync status changes
+ * - finalized blocks are replayed from the from_height query parameter if set, otherwise only new blocks are sent
+ */
+func (s *Server) streamHandler(w http.ResponseWriter, r *http.Request) {
+	s.logger.Debug("stream request",
+		zap.String("path", "/v1/stream"),
+		zap.Bool("websocket", websocket.IsWebSocketUpgrade(r)),
+		zap.String("fromHeight", r.URL.Query().Get("from_height")),
+		zap.String("remoteAddr", r.RemoteAddr),
+	)
+
+	fromHeight, err := s.streamFromHeight(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// the upgrader already replied with an HTTP error
+			s.logger.Debug("Failed to upgrade stream to websocket", zap.Error(err))
+			return
+		}
+		defer conn.Close()
+
+		ctx, cancel := s.streamContext(r.Context())
+		defer cancel()
+		// the client doesn't send anything, but reading is needed to notice it closing the connection
+		go func() {
+			defer cancel()
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		s.runStream(ctx, fromHeight, &wsEventWriter{conn: conn})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx, cancel := s.streamContext(r.Context())
+	defer cancel()
+	s.runStream(ctx, fromHeight, &sseEventWriter{w: w, flusher: flusher})
+}
+
+// pollSyncStatus refreshes the chain sync status every poll interval until ctx is done,
+// and pushes it to the stream connections whenever it changes
+func (s *Server) pollSyncStatus(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.syncStatus.hasSubscribers() {
+			status, err := s.fg.QueryChainSyncStatus()
+			if err != nil {
+				s.logger.Debug("Failed to query chain sync status for stream", zap.Error(err))
+			} else {
+				s.syncStatus.publish(status)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//////////////////////////////
+// INTERNAL
+//////////////////////////////
+
+// eventWriter writes finality events to a stream connection
+type eventWriter interface {
+	writeEvent(event *types.FinalityEvent) error
+	writeKeepAlive() error
+}
+
+type sseEventWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (sw *sseEventWriter) writeEvent(event *types.FinalityEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(sw.w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	sw.flusher.Flush()
+	return nil
+}
+
+func (sw *sseEventWriter) writeKeepAlive() error {
+	if _, err := fmt.Fprint(sw.w, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	sw.flusher.Flush()
+	return nil
+}
+
+type wsEventWriter struct {
+	conn *websocket.Conn
+}
+
+func (ww *wsEventWriter) writeEvent(event *types.FinalityEvent) error {
+	if err := ww.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+		return err
+	}
+	return ww.conn.WriteJSON(event)
+}
+
+func (ww *wsEventWriter) writeKeepAlive() error {
+	return ww.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout))
+}
+
+// streamContext returns the context of a stream, done once the client goes away or the server shuts down
+func (s *Server) streamContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(s.shutdownCtx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// runStream writes finality events and sync status changes to the connection until ctx is done or a write fails
+func (s *Server) runStream(ctx context.Context, fromHeight uint64, writer eventWriter) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// events come from both the finality gadget and the sync status feed, so serialize writes
+	var writeMutex sync.Mutex
+	write := func(event *types.FinalityEvent) error {
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+		return writer.writeEvent(event)
+	}
+
+	statusCh := s.syncStatus.subscribe()
+	defer s.syncStatus.unsubscribe(statusCh)
+
+	fgDone := make(chan error, 1)
+	go func() {
+		fgDone <- s.fg.SubscribeFinalityEvents(ctx, fromHeight, write)
+	}()
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case err = <-fgDone:
+		case status := <-statusCh:
+			err = write(&types.FinalityEvent{Type: types.FinalityEventSyncStatus, SyncStatus: status})
+		case <-keepAlive.C:
+			writeMutex.Lock()
+			err = writer.writeKeepAlive()
+			writeMutex.Unlock()
+		}
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				s.logger.Debug("Stream closed", zap.Error(err))
+			}
+			return
+		}
+	}
+}
+
+// streamFromHeight returns the height to replay finalized blocks from, defaulting to the block
+// after the latest finalized one so only new blocks are sent
+func (s *Server) streamFromHeight(r *http.Request) (uint64, error) {
+	if param := r.URL.Query().Get("from_height"); param != "" {
+		fromHeight, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid from_height: %w", err)
+		}
+		return fromHeight, nil
+	}
+
+	latestBlock, err := s.fg.QueryLatestFinalizedBlock()
+	if err != nil {
+		return 0, err
+	}
+	if latestBlock == nil {
+		return 0, nil
+	}
+	return latestBlock.BlockHeight + 1, nil
+}
+
+// subscribe returns a channel receiving the latest sync status whenever it changes,
+// starting with the current one if known
+func (f *syncStatusFeed) subscribe() chan *types.ChainSyncStatus {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.subscribers == nil {
+		f.subscribers = make(map[chan *types.ChainSyncStatus]struct{})
+	}
+	ch := make(chan *types.ChainSyncStatus, 1)
+	if f.latest != nil {
+		ch <- f.latest
+	}
+	f.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (f *syncStatusFeed) unsubscribe(ch chan *types.ChainSyncStatus) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.subscribers, ch)
+}
+
+func (f *syncStatusFeed) hasSubscribers() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return len(f.subscribers) > 0
+}
+
+// publish pushes the status to all subscribers if it changed. A subscriber that hasn't
+// consumed the previous status gets it replaced, as only the latest status matters.
+func (f *syncStatusFeed) publish(status *types.ChainSyncStatus) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if reflect.DeepEqual(f.latest, status) {
+		return
+	}
+	f.latest = status
+
+	for ch := range f.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- status
+	}
+}
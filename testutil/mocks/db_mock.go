@@ -68,6 +68,49 @@ func (mr *MockIDatabaseHandlerMockRecorder) CreateInitialSchema() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInitialSchema", reflect.TypeOf((*MockIDatabaseHandler)(nil).CreateInitialSchema))
 }
 
+// DeleteBlocksBeforeHeight mocks base method.
+func (m *MockIDatabaseHandler) DeleteBlocksBeforeHeight(height, limit uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlocksBeforeHeight", height, limit)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteBlocksBeforeHeight indicates an expected call of DeleteBlocksBeforeHeight.
+func (mr *MockIDatabaseHandlerMockRecorder) DeleteBlocksBeforeHeight(height, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlocksBeforeHeight", reflect.TypeOf((*MockIDatabaseHandler)(nil).DeleteBlocksBeforeHeight), height, limit)
+}
+
+// DeleteBlocksFromHeight mocks base method.
+func (m *MockIDatabaseHandler) DeleteBlocksFromHeight(height uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBlocksFromHeight", height)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBlocksFromHeight indicates an expected call of DeleteBlocksFromHeight.
+func (mr *MockIDatabaseHandlerMockRecorder) DeleteBlocksFromHeight(height any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBlocksFromHeight", reflect.TypeOf((*MockIDatabaseHandler)(nil).DeleteBlocksFromHeight), height)
+}
+
+// DeleteBtcHeadersFromHeight mocks base method.
+func (m *MockIDatabaseHandler) DeleteBtcHeadersFromHeight(height uint32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBtcHeadersFromHeight", height)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBtcHeadersFromHeight indicates an expected call of DeleteBtcHeadersFromHeight.
+func (mr *MockIDatabaseHandlerMockRecorder) DeleteBtcHeadersFromHeight(height any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBtcHeadersFromHeight", reflect.TypeOf((*MockIDatabaseHandler)(nil).DeleteBtcHeadersFromHeight), height)
+}
+
 // GetActivatedTimestamp mocks base method.
 func (m *MockIDatabaseHandler) GetActivatedTimestamp() (uint64, error) {
 	m.ctrl.T.Helper()
@@ -113,6 +156,96 @@ func (mr *MockIDatabaseHandlerMockRecorder) GetBlockByHeight(height any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockByHeight", reflect.TypeOf((*MockIDatabaseHandler)(nil).GetBlockByHeight), height)
 }
 
+// GetBlocksFromHeight mocks base method.
+func (m *MockIDatabaseHandler) GetBlocksFromHeight(height, limit uint64) ([]*types.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocksFromHeight", height, limit)
+	ret0, _ := ret[0].([]*types.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocksFromHeight indicates an expected call of GetBlocksFromHeight.
+func (mr *MockIDatabaseHandlerMockRecorder) GetBlocksFromHeight(height, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocksFromHeight", reflect.TypeOf((*MockIDatabaseHandler)(nil).GetBlocksFromHeight), height, limit)
+}
+
+// GetBtcHeaderByHeight mocks base method.
+func (m *MockIDatabaseHandler) GetBtcHeaderByHeight(height uint32) (*types.BtcHeader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBtcHeaderByHeight", height)
+	ret0, _ := ret[0].(*types.BtcHeader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBtcHeaderByHeight indicates an expected call of GetBtcHeaderByHeight.
+func (mr *MockIDatabaseHandlerMockRecorder) GetBtcHeaderByHeight(height any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBtcHeaderByHeight", reflect.TypeOf((*MockIDatabaseHandler)(nil).GetBtcHeaderByHeight), height)
+}
+
+// GetBtcHeightByTimestamp mocks base method.
+func (m *MockIDatabaseHandler) GetBtcHeightByTimestamp(timestamp uint64) (uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBtcHeightByTimestamp", timestamp)
+	ret0, _ := ret[0].(uint32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBtcHeightByTimestamp indicates an expected call of GetBtcHeightByTimestamp.
+func (mr *MockIDatabaseHandlerMockRecorder) GetBtcHeightByTimestamp(timestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBtcHeightByTimestamp", reflect.TypeOf((*MockIDatabaseHandler)(nil).GetBtcHeightByTimestamp), timestamp)
+}
+
+// GetEvidenceByHash mocks base method.
+func (m *MockIDatabaseHandler) GetEvidenceByHash(hash string) (*types.FinalityEvidence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvidenceByHash", hash)
+	ret0, _ := ret[0].(*types.FinalityEvidence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvidenceByHash indicates an expected call of GetEvidenceByHash.
+func (mr *MockIDatabaseHandlerMockRecorder) GetEvidenceByHash(hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvidenceByHash", reflect.TypeOf((*MockIDatabaseHandler)(nil).GetEvidenceByHash), hash)
+}
+
+// GetEvidenceByHeight mocks base method.
+func (m *MockIDatabaseHandler) GetEvidenceByHeight(height uint64) (*types.FinalityEvidence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvidenceByHeight", height)
+	ret0, _ := ret[0].(*types.FinalityEvidence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEvidenceByHeight indicates an expected call of GetEvidenceByHeight.
+func (mr *MockIDatabaseHandlerMockRecorder) GetEvidenceByHeight(height any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvidenceByHeight", reflect.TypeOf((*MockIDatabaseHandler)(nil).GetEvidenceByHeight), height)
+}
+
+// GetProcessingCheckpoint mocks base method.
+func (m *MockIDatabaseHandler) GetProcessingCheckpoint() (*types.ProcessingCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProcessingCheckpoint")
+	ret0, _ := ret[0].(*types.ProcessingCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProcessingCheckpoint indicates an expected call of GetProcessingCheckpoint.
+func (mr *MockIDatabaseHandlerMockRecorder) GetProcessingCheckpoint() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProcessingCheckpoint", reflect.TypeOf((*MockIDatabaseHandler)(nil).GetProcessingCheckpoint))
+}
+
 // InsertBlocks mocks base method.
 func (m *MockIDatabaseHandler) InsertBlocks(block []*types.Block) error {
 	m.ctrl.T.Helper()
@@ -127,6 +260,64 @@ func (mr *MockIDatabaseHandlerMockRecorder) InsertBlocks(block any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlocks", reflect.TypeOf((*MockIDatabaseHandler)(nil).InsertBlocks), block)
 }
 
+// InsertBlocksWithCheckpoint mocks base method.
+func (m *MockIDatabaseHandler) InsertBlocksWithCheckpoint(blocks []*types.Block, checkpoint *types.ProcessingCheckpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBlocksWithCheckpoint", blocks, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertBlocksWithCheckpoint indicates an expected call of InsertBlocksWithCheckpoint.
+func (mr *MockIDatabaseHandlerMockRecorder) InsertBlocksWithCheckpoint(blocks, checkpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBlocksWithCheckpoint", reflect.TypeOf((*MockIDatabaseHandler)(nil).InsertBlocksWithCheckpoint), blocks, checkpoint)
+}
+
+// InsertBtcHeaders mocks base method.
+func (m *MockIDatabaseHandler) InsertBtcHeaders(headers []*types.BtcHeader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertBtcHeaders", headers)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertBtcHeaders indicates an expected call of InsertBtcHeaders.
+func (mr *MockIDatabaseHandlerMockRecorder) InsertBtcHeaders(headers any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertBtcHeaders", reflect.TypeOf((*MockIDatabaseHandler)(nil).InsertBtcHeaders), headers)
+}
+
+// ListBlocks mocks base method.
+func (m *MockIDatabaseHandler) ListBlocks(query types.BlockQuery) ([]*types.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBlocks", query)
+	ret0, _ := ret[0].([]*types.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBlocks indicates an expected call of ListBlocks.
+func (mr *MockIDatabaseHandlerMockRecorder) ListBlocks(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBlocks", reflect.TypeOf((*MockIDatabaseHandler)(nil).ListBlocks), query)
+}
+
+// QueryEarliestBtcHeader mocks base method.
+func (m *MockIDatabaseHandler) QueryEarliestBtcHeader() (*types.BtcHeader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryEarliestBtcHeader")
+	ret0, _ := ret[0].(*types.BtcHeader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryEarliestBtcHeader indicates an expected call of QueryEarliestBtcHeader.
+func (mr *MockIDatabaseHandlerMockRecorder) QueryEarliestBtcHeader() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryEarliestBtcHeader", reflect.TypeOf((*MockIDatabaseHandler)(nil).QueryEarliestBtcHeader))
+}
+
 // QueryEarliestFinalizedBlock mocks base method.
 func (m *MockIDatabaseHandler) QueryEarliestFinalizedBlock() (*types.Block, error) {
 	m.ctrl.T.Helper()
@@ -172,6 +363,21 @@ func (mr *MockIDatabaseHandlerMockRecorder) QueryIsBlockFinalizedByHeight(height
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryIsBlockFinalizedByHeight", reflect.TypeOf((*MockIDatabaseHandler)(nil).QueryIsBlockFinalizedByHeight), height)
 }
 
+// QueryLatestBtcHeader mocks base method.
+func (m *MockIDatabaseHandler) QueryLatestBtcHeader() (*types.BtcHeader, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryLatestBtcHeader")
+	ret0, _ := ret[0].(*types.BtcHeader)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryLatestBtcHeader indicates an expected call of QueryLatestBtcHeader.
+func (mr *MockIDatabaseHandlerMockRecorder) QueryLatestBtcHeader() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryLatestBtcHeader", reflect.TypeOf((*MockIDatabaseHandler)(nil).QueryLatestBtcHeader))
+}
+
 // QueryLatestFinalizedBlock mocks base method.
 func (m *MockIDatabaseHandler) QueryLatestFinalizedBlock() (*types.Block, error) {
 	m.ctrl.T.Helper()
@@ -145,18 +145,33 @@ func (m *MockIBabylonClient) EXPECT() *MockIBabylonClientMockRecorder {
 }
 
 // QueryAllFpBtcPubKeys mocks base method.
-func (m *MockIBabylonClient) QueryAllFpBtcPubKeys(consumerId string) ([]string, error) {
+func (m *MockIBabylonClient) QueryAllFpBtcPubKeys(consumerId string, babylonHeight uint64) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "QueryAllFpBtcPubKeys", consumerId)
+	ret := m.ctrl.Call(m, "QueryAllFpBtcPubKeys", consumerId, babylonHeight)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // QueryAllFpBtcPubKeys indicates an expected call of QueryAllFpBtcPubKeys.
-func (mr *MockIBabylonClientMockRecorder) QueryAllFpBtcPubKeys(consumerId any) *gomock.Call {
+func (mr *MockIBabylonClientMockRecorder) QueryAllFpBtcPubKeys(consumerId, babylonHeight any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryAllFpBtcPubKeys", reflect.TypeOf((*MockIBabylonClient)(nil).QueryAllFpBtcPubKeys), consumerId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryAllFpBtcPubKeys", reflect.TypeOf((*MockIBabylonClient)(nil).QueryAllFpBtcPubKeys), consumerId, babylonHeight)
+}
+
+// QueryBabylonHeightByTimestamp mocks base method.
+func (m *MockIBabylonClient) QueryBabylonHeightByTimestamp(targetTimestamp uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBabylonHeightByTimestamp", targetTimestamp)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBabylonHeightByTimestamp indicates an expected call of QueryBabylonHeightByTimestamp.
+func (mr *MockIBabylonClientMockRecorder) QueryBabylonHeightByTimestamp(targetTimestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBabylonHeightByTimestamp", reflect.TypeOf((*MockIBabylonClient)(nil).QueryBabylonHeightByTimestamp), targetTimestamp)
 }
 
 // QueryEarliestActiveDelBtcHeight mocks base method.
@@ -174,6 +189,51 @@ func (mr *MockIBabylonClientMockRecorder) QueryEarliestActiveDelBtcHeight(fpPubk
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryEarliestActiveDelBtcHeight", reflect.TypeOf((*MockIBabylonClient)(nil).QueryEarliestActiveDelBtcHeight), fpPubkeyHexList)
 }
 
+// QueryEpochInterval mocks base method.
+func (m *MockIBabylonClient) QueryEpochInterval() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryEpochInterval")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryEpochInterval indicates an expected call of QueryEpochInterval.
+func (mr *MockIBabylonClientMockRecorder) QueryEpochInterval() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryEpochInterval", reflect.TypeOf((*MockIBabylonClient)(nil).QueryEpochInterval))
+}
+
+// QueryLastFinalizedEpoch mocks base method.
+func (m *MockIBabylonClient) QueryLastFinalizedEpoch() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryLastFinalizedEpoch")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryLastFinalizedEpoch indicates an expected call of QueryLastFinalizedEpoch.
+func (mr *MockIBabylonClientMockRecorder) QueryLastFinalizedEpoch() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryLastFinalizedEpoch", reflect.TypeOf((*MockIBabylonClient)(nil).QueryLastFinalizedEpoch))
+}
+
+// QueryLatestHeight mocks base method.
+func (m *MockIBabylonClient) QueryLatestHeight() (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryLatestHeight")
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryLatestHeight indicates an expected call of QueryLatestHeight.
+func (mr *MockIBabylonClientMockRecorder) QueryLatestHeight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryLatestHeight", reflect.TypeOf((*MockIBabylonClient)(nil).QueryLatestHeight))
+}
+
 // QueryMultiFpPower mocks base method.
 func (m *MockIBabylonClient) QueryMultiFpPower(fpPubkeyHexList []string, btcHeight uint32) (map[string]uint64, error) {
 	m.ctrl.T.Helper()
@@ -213,6 +273,51 @@ func (m *MockICosmWasmClient) EXPECT() *MockICosmWasmClientMockRecorder {
 	return m.recorder
 }
 
+// QueryAllowedFinalityProviders mocks base method.
+func (m *MockICosmWasmClient) QueryAllowedFinalityProviders(babylonHeight uint64) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryAllowedFinalityProviders", babylonHeight)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryAllowedFinalityProviders indicates an expected call of QueryAllowedFinalityProviders.
+func (mr *MockICosmWasmClientMockRecorder) QueryAllowedFinalityProviders(babylonHeight any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryAllowedFinalityProviders", reflect.TypeOf((*MockICosmWasmClient)(nil).QueryAllowedFinalityProviders), babylonHeight)
+}
+
+// QueryBlockVoters mocks base method.
+func (m *MockICosmWasmClient) QueryBlockVoters(queryParams *types.Block) ([]*types.BlockVoter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBlockVoters", queryParams)
+	ret0, _ := ret[0].([]*types.BlockVoter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBlockVoters indicates an expected call of QueryBlockVoters.
+func (mr *MockICosmWasmClientMockRecorder) QueryBlockVoters(queryParams any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBlockVoters", reflect.TypeOf((*MockICosmWasmClient)(nil).QueryBlockVoters), queryParams)
+}
+
+// QueryBlockVotersAtHeight mocks base method.
+func (m *MockICosmWasmClient) QueryBlockVotersAtHeight(queryParams *types.Block, babylonHeight uint64) ([]*types.BlockVoter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBlockVotersAtHeight", queryParams, babylonHeight)
+	ret0, _ := ret[0].([]*types.BlockVoter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBlockVotersAtHeight indicates an expected call of QueryBlockVotersAtHeight.
+func (mr *MockICosmWasmClientMockRecorder) QueryBlockVotersAtHeight(queryParams, babylonHeight any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBlockVotersAtHeight", reflect.TypeOf((*MockICosmWasmClient)(nil).QueryBlockVotersAtHeight), queryParams, babylonHeight)
+}
+
 // QueryConfig mocks base method.
 func (m *MockICosmWasmClient) QueryConfig() (*types.ContractConfig, error) {
 	m.ctrl.T.Helper()
@@ -243,19 +348,19 @@ func (mr *MockICosmWasmClientMockRecorder) QueryConsumerId() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryConsumerId", reflect.TypeOf((*MockICosmWasmClient)(nil).QueryConsumerId))
 }
 
-// QueryListOfVotedFinalityProviders mocks base method.
-func (m *MockICosmWasmClient) QueryListOfVotedFinalityProviders(queryParams *types.Block) ([]string, error) {
+// QueryPubRandCommitForHeight mocks base method.
+func (m *MockICosmWasmClient) QueryPubRandCommitForHeight(fpBtcPkHex string, height uint64) (*types.PubRandCommit, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "QueryListOfVotedFinalityProviders", queryParams)
-	ret0, _ := ret[0].([]string)
+	ret := m.ctrl.Call(m, "QueryPubRandCommitForHeight", fpBtcPkHex, height)
+	ret0, _ := ret[0].(*types.PubRandCommit)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// QueryListOfVotedFinalityProviders indicates an expected call of QueryListOfVotedFinalityProviders.
-func (mr *MockICosmWasmClientMockRecorder) QueryListOfVotedFinalityProviders(queryParams any) *gomock.Call {
+// QueryPubRandCommitForHeight indicates an expected call of QueryPubRandCommitForHeight.
+func (mr *MockICosmWasmClientMockRecorder) QueryPubRandCommitForHeight(fpBtcPkHex, height any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryListOfVotedFinalityProviders", reflect.TypeOf((*MockICosmWasmClient)(nil).QueryListOfVotedFinalityProviders), queryParams)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryPubRandCommitForHeight", reflect.TypeOf((*MockICosmWasmClient)(nil).QueryPubRandCommitForHeight), fpBtcPkHex, height)
 }
 
 // MockIEthL2Client is a mock of IEthL2Client interface.
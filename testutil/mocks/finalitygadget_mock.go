@@ -10,6 +10,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	types "github.com/babylonlabs-io/finality-gadget/types"
@@ -70,6 +71,51 @@ func (mr *MockIFinalityGadgetMockRecorder) GetBlockByHeight(height any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockByHeight", reflect.TypeOf((*MockIFinalityGadget)(nil).GetBlockByHeight), height)
 }
 
+// GetFinalityEvidenceByHash mocks base method.
+func (m *MockIFinalityGadget) GetFinalityEvidenceByHash(hash string) (*types.FinalityEvidence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFinalityEvidenceByHash", hash)
+	ret0, _ := ret[0].(*types.FinalityEvidence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFinalityEvidenceByHash indicates an expected call of GetFinalityEvidenceByHash.
+func (mr *MockIFinalityGadgetMockRecorder) GetFinalityEvidenceByHash(hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFinalityEvidenceByHash", reflect.TypeOf((*MockIFinalityGadget)(nil).GetFinalityEvidenceByHash), hash)
+}
+
+// GetFinalityEvidenceByHeight mocks base method.
+func (m *MockIFinalityGadget) GetFinalityEvidenceByHeight(height uint64) (*types.FinalityEvidence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFinalityEvidenceByHeight", height)
+	ret0, _ := ret[0].(*types.FinalityEvidence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFinalityEvidenceByHeight indicates an expected call of GetFinalityEvidenceByHeight.
+func (mr *MockIFinalityGadgetMockRecorder) GetFinalityEvidenceByHeight(height any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFinalityEvidenceByHeight", reflect.TypeOf((*MockIFinalityGadget)(nil).GetFinalityEvidenceByHeight), height)
+}
+
+// ListFinalizedBlocks mocks base method.
+func (m *MockIFinalityGadget) ListFinalizedBlocks(query types.BlockQuery, cursor string) (*types.BlockPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFinalizedBlocks", query, cursor)
+	ret0, _ := ret[0].(*types.BlockPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFinalizedBlocks indicates an expected call of ListFinalizedBlocks.
+func (mr *MockIFinalityGadgetMockRecorder) ListFinalizedBlocks(query, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFinalizedBlocks", reflect.TypeOf((*MockIFinalityGadget)(nil).ListFinalizedBlocks), query, cursor)
+}
+
 // QueryBlockRangeBabylonFinalized mocks base method.
 func (m *MockIFinalityGadget) QueryBlockRangeBabylonFinalized(queryBlocks []*types.Block) (*uint64, error) {
 	m.ctrl.T.Helper()
@@ -115,6 +161,35 @@ func (mr *MockIFinalityGadgetMockRecorder) QueryChainSyncStatus() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryChainSyncStatus", reflect.TypeOf((*MockIFinalityGadget)(nil).QueryChainSyncStatus))
 }
 
+// QueryFinalityProof mocks base method.
+func (m *MockIFinalityGadget) QueryFinalityProof(height uint64) (*types.FinalityProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryFinalityProof", height)
+	ret0, _ := ret[0].(*types.FinalityProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryFinalityProof indicates an expected call of QueryFinalityProof.
+func (mr *MockIFinalityGadgetMockRecorder) QueryFinalityProof(height any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryFinalityProof", reflect.TypeOf((*MockIFinalityGadget)(nil).QueryFinalityProof), height)
+}
+
+// QueryHealthStatus mocks base method.
+func (m *MockIFinalityGadget) QueryHealthStatus() *types.HealthStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryHealthStatus")
+	ret0, _ := ret[0].(*types.HealthStatus)
+	return ret0
+}
+
+// QueryHealthStatus indicates an expected call of QueryHealthStatus.
+func (mr *MockIFinalityGadgetMockRecorder) QueryHealthStatus() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryHealthStatus", reflect.TypeOf((*MockIFinalityGadget)(nil).QueryHealthStatus))
+}
+
 // QueryIsBlockBabylonFinalized mocks base method.
 func (m *MockIFinalityGadget) QueryIsBlockBabylonFinalized(block *types.Block) (bool, error) {
 	m.ctrl.T.Helper()
@@ -190,6 +265,34 @@ func (mr *MockIFinalityGadgetMockRecorder) QueryLatestFinalizedBlock() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryLatestFinalizedBlock", reflect.TypeOf((*MockIFinalityGadget)(nil).QueryLatestFinalizedBlock))
 }
 
+// QueryLiveness mocks base method.
+func (m *MockIFinalityGadget) QueryLiveness() *types.LivenessStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryLiveness")
+	ret0, _ := ret[0].(*types.LivenessStatus)
+	return ret0
+}
+
+// QueryLiveness indicates an expected call of QueryLiveness.
+func (mr *MockIFinalityGadgetMockRecorder) QueryLiveness() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryLiveness", reflect.TypeOf((*MockIFinalityGadget)(nil).QueryLiveness))
+}
+
+// QueryReadiness mocks base method.
+func (m *MockIFinalityGadget) QueryReadiness(ctx context.Context) *types.ReadinessStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryReadiness", ctx)
+	ret0, _ := ret[0].(*types.ReadinessStatus)
+	return ret0
+}
+
+// QueryReadiness indicates an expected call of QueryReadiness.
+func (mr *MockIFinalityGadgetMockRecorder) QueryReadiness(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryReadiness", reflect.TypeOf((*MockIFinalityGadget)(nil).QueryReadiness), ctx)
+}
+
 // QueryTransactionStatus mocks base method.
 func (m *MockIFinalityGadget) QueryTransactionStatus(txHash string) (*types.TransactionInfo, error) {
 	m.ctrl.T.Helper()
@@ -204,3 +307,31 @@ func (mr *MockIFinalityGadgetMockRecorder) QueryTransactionStatus(txHash any) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryTransactionStatus", reflect.TypeOf((*MockIFinalityGadget)(nil).QueryTransactionStatus), txHash)
 }
+
+// SubscribeFinalityEvents mocks base method.
+func (m *MockIFinalityGadget) SubscribeFinalityEvents(ctx context.Context, fromHeight uint64, send func(*types.FinalityEvent) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeFinalityEvents", ctx, fromHeight, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubscribeFinalityEvents indicates an expected call of SubscribeFinalityEvents.
+func (mr *MockIFinalityGadgetMockRecorder) SubscribeFinalityEvents(ctx, fromHeight, send any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeFinalityEvents", reflect.TypeOf((*MockIFinalityGadget)(nil).SubscribeFinalityEvents), ctx, fromHeight, send)
+}
+
+// SubscribeFinalizedBlocks mocks base method.
+func (m *MockIFinalityGadget) SubscribeFinalizedBlocks(ctx context.Context, fromHeight uint64, send func(*types.Block) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeFinalizedBlocks", ctx, fromHeight, send)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubscribeFinalizedBlocks indicates an expected call of SubscribeFinalizedBlocks.
+func (mr *MockIFinalityGadgetMockRecorder) SubscribeFinalizedBlocks(ctx, fromHeight, send any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeFinalizedBlocks", reflect.TypeOf((*MockIFinalityGadget)(nil).SubscribeFinalizedBlocks), ctx, fromHeight, send)
+}
@@ -0,0 +1,43 @@
+package types
+
+// AlertStatus is the status of an alert delivered to the alert webhooks
+type AlertStatus string
+
+const (
+	AlertStatusFiring   AlertStatus = "firing"
+	AlertStatusResolved AlertStatus = "resolved"
+)
+
+// StallAlert is the payload POSTed to the alert webhooks when the finalization stalls, when the diagnosis of an
+// ongoing stall changes, and when the finalization resumes
+type StallAlert struct {
+	// ID identifies the stall, the alerts about the same stall sharing it. Together with the status and
+	// timestamp, it lets the receivers drop the alerts delivered twice
+	ID     string      `json:"id"`
+	Status AlertStatus `json:"status"`
+	// Reasons lists the stall conditions met, empty once resolved
+	Reasons []string `json:"reasons,omitempty"`
+	// StartedAt is the unix time the stall was detected at, Timestamp the unix time of the alert
+	StartedAt uint64 `json:"started_at"`
+	Timestamp uint64 `json:"timestamp"`
+
+	LatestFinalizedHeight uint64 `json:"latest_finalized_height"`
+	L2HeadHeight          uint64 `json:"l2_head_height"`
+
+	// Diagnosis holds the votes on the first block not finalized, if it could be evaluated
+	Diagnosis *StallDiagnosis `json:"diagnosis,omitempty"`
+}
+
+// StallDiagnosis holds the votes on the first block at a finality signature interval above the latest finalized block
+type StallDiagnosis struct {
+	BlockHeight uint64 `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	TotalPower  uint64 `json:"total_power"`
+	VotedPower  uint64 `json:"voted_power"`
+	// QuorumPower is the voted power required to finalize the block
+	QuorumPower uint64 `json:"quorum_power"`
+	// MissingPower is the voting power missing to reach the quorum, 0 if the block has a quorum
+	MissingPower uint64 `json:"missing_power"`
+	// MissingVoters are the FPs with voting power that did not vote for the block, by decreasing power
+	MissingVoters []*VoterPower `json:"missing_voters"`
+}
@@ -4,6 +4,28 @@ type Block struct {
 	BlockHash      string `json:"block_hash" description:"block hash"`
 	BlockHeight    uint64 `json:"block_height" description:"block height"`
 	BlockTimestamp uint64 `json:"block_timestamp" description:"block timestamp"`
+	BabylonHeight  uint64 `json:"babylon_height,omitempty" description:"babylon height used to resolve the finality provider set"`
+	// Evidence is persisted separately from the block record
+	Evidence *FinalityEvidence `json:"-"`
+}
+
+// BlockQuery selects finalized blocks by height and timestamp, all bounds being inclusive
+type BlockQuery struct {
+	FromHeight uint64
+	// ToHeight is the highest height to list, 0 meaning no upper bound
+	ToHeight      uint64
+	FromTimestamp uint64
+	// ToTimestamp is the latest timestamp to list, 0 meaning no upper bound
+	ToTimestamp uint64
+	// Descending lists the blocks from the highest height down
+	Descending bool
+	Limit      uint64
+}
+
+// BlockPage is a page of finalized blocks, NextCursor resuming the listing after it if more blocks match
+type BlockPage struct {
+	Blocks     []*Block `json:"blocks"`
+	NextCursor string   `json:"next_cursor,omitempty"`
 }
 
 type ChainSyncStatus struct {
@@ -18,4 +40,7 @@ type ContractConfig struct {
 	BsnActivationHeight       uint64 `json:"bsn_activation_height"`
 	FinalitySignatureInterval uint64 `json:"finality_signature_interval"`
 	MinPubRand                uint64 `json:"min_pub_rand"`
+	// QuorumNumerator and QuorumDenominator are only set if the contract exposes a quorum
+	QuorumNumerator   uint64 `json:"quorum_numerator,omitempty"`
+	QuorumDenominator uint64 `json:"quorum_denominator,omitempty"`
 }
@@ -0,0 +1,8 @@
+package types
+
+// BtcHeader is a BTC block header in the local timestamp-to-height index
+type BtcHeader struct {
+	Height    uint32 `json:"height" description:"BTC block height"`
+	Hash      string `json:"hash" description:"BTC block hash"`
+	Timestamp uint64 `json:"timestamp" description:"BTC block timestamp"`
+}
@@ -0,0 +1,40 @@
+package types
+
+// CheckpointOutcome is the outcome of the evaluation recorded by a processing checkpoint
+type CheckpointOutcome uint8
+
+const (
+	// CheckpointFinalized: the heights evaluated up to the checkpoint were finalized and stored
+	CheckpointFinalized CheckpointOutcome = iota + 1
+	// CheckpointSkipped: no height up to the checkpoint is at a finality signature interval
+	CheckpointSkipped
+	// CheckpointNotFinalized: the next height at a finality signature interval is not finalized yet
+	CheckpointNotFinalized
+	// CheckpointRolledBack: the blocks above the checkpoint were rolled back after an L2 reorg
+	CheckpointRolledBack
+)
+
+func (o CheckpointOutcome) String() string {
+	switch o {
+	case CheckpointFinalized:
+		return "finalized"
+	case CheckpointSkipped:
+		return "skipped"
+	case CheckpointNotFinalized:
+		return "not_finalized"
+	case CheckpointRolledBack:
+		return "rolled_back"
+	default:
+		return "unknown"
+	}
+}
+
+// ProcessingCheckpoint records how far the block processing loop got, so a restart resumes right after
+// the last evaluated height instead of re-evaluating everything above the latest finalized block
+type ProcessingCheckpoint struct {
+	// Height is the last evaluated L2 height, processing resumes above it
+	Height  uint64            `json:"height"`
+	Outcome CheckpointOutcome `json:"outcome"`
+	// Timestamp is the unix time of the evaluation
+	Timestamp uint64 `json:"timestamp"`
+}
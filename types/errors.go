@@ -4,8 +4,13 @@ import "errors"
 
 var (
 	ErrBlockNotFound              = errors.New("block not found")
+	ErrEvidenceNotFound           = errors.New("finality evidence not found")
+	ErrBlockNotFinalized          = errors.New("block is not finalized on Babylon")
+	ErrInvalidFinalityProof       = errors.New("invalid finality proof")
 	ErrInvalidBlockRange          = errors.New("invalid block range")
+	ErrInvalidCursor              = errors.New("invalid cursor")
 	ErrNoFpHasVotingPower         = errors.New("no FP has voting power for the consumer chain")
 	ErrBtcStakingNotActivated     = errors.New("BTC staking is not activated for the consumer chain")
 	ErrActivatedTimestampNotFound = errors.New("BTC staking activated timestamp not found")
+	ErrBtcHeaderNotFound          = errors.New("BTC header not found in the local index")
 )
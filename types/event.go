@@ -0,0 +1,16 @@
+package types
+
+type FinalityEventType string
+
+const (
+	FinalityEventFinalizedBlock FinalityEventType = "finalized_block"
+	FinalityEventRollback       FinalityEventType = "rollback"
+	FinalityEventSyncStatus     FinalityEventType = "sync_status"
+)
+
+type FinalityEvent struct {
+	Type           FinalityEventType `json:"type" description:"event type"`
+	Block          *Block            `json:"block,omitempty" description:"newly finalized block, for finalized_block events"`
+	RollbackHeight uint64            `json:"rollback_height,omitempty" description:"lowest rolled back height, for rollback events"`
+	SyncStatus     *ChainSyncStatus  `json:"sync_status,omitempty" description:"latest chain sync status, for sync_status events"`
+}
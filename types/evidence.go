@@ -0,0 +1,18 @@
+package types
+
+type FinalityEvidence struct {
+	BlockHeight   uint64        `json:"block_height" description:"block height"`
+	BlockHash     string        `json:"block_hash" description:"block hash"`
+	BabylonHeight uint64        `json:"babylon_height" description:"babylon height used to resolve the finality provider set"`
+	BtcHeight     uint32        `json:"btc_height" description:"btc height used to compute the voting power"`
+	TotalPower    uint64        `json:"total_power" description:"total voting power of the finality provider set"`
+	VotedPower    uint64        `json:"voted_power" description:"voting power of the finality providers that voted for the block"`
+	Voters        []*VoterPower `json:"voters" description:"finality providers that voted for the block and their voting power"`
+	QuorumRatio   float64       `json:"quorum_ratio" description:"configured share of the total power required for finality, 0 if recorded before it was stored"`
+	QuorumPower   uint64        `json:"quorum_power" description:"minimum voted power required for finality, 0 if recorded before it was stored"`
+}
+
+type VoterPower struct {
+	FpBtcPkHex string `json:"fp_btc_pk_hex" description:"finality provider BTC public key (hex)"`
+	Power      uint64 `json:"power" description:"voting power of the finality provider"`
+}
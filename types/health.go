@@ -0,0 +1,69 @@
+package types
+
+// HealthStatus reports whether the finality gadget is degraded, ie. some of its components keep failing.
+// A degraded finality gadget keeps retrying, but the finalized blocks it serves may lag behind the L2 meanwhile.
+type HealthStatus struct {
+	Degraded bool                `json:"degraded"`
+	Failures []*ComponentFailure `json:"failures,omitempty"`
+}
+
+// ComponentFailure describes the failures in a row of a component of the finality gadget
+type ComponentFailure struct {
+	// Component is the failing block processing stage or background task
+	Component string `json:"component"`
+	// Upstream is the upstream whose calls fail, if known
+	Upstream string `json:"upstream,omitempty"`
+	// Error is the last error of the component
+	Error string `json:"error"`
+	// Since is the unix time of the first failure in a row
+	Since uint64 `json:"since"`
+	// Failures is the number of failures in a row
+	Failures uint64 `json:"failures"`
+}
+
+// DependencyDB is the dependency name of the db in the readiness checks, besides the upstreams
+const DependencyDB = "db"
+
+// DependencyStatus is the result of a readiness check of a dependency of the finality gadget
+type DependencyStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+	// LatencyMs is how long the check took, in milliseconds
+	LatencyMs int64 `json:"latency_ms"`
+}
+
+// LivenessStatus reports whether the finality gadget is running, regardless of its dependencies
+type LivenessStatus struct {
+	// Alive is whether the block processing loop is running and its heartbeat is recent enough
+	Alive         bool   `json:"alive"`
+	UptimeSeconds uint64 `json:"uptime_seconds"`
+
+	// LastHeartbeatTimestamp is the unix time of the last heartbeat of the block processing, 0 if there was none yet
+	LastHeartbeatTimestamp   uint64  `json:"last_heartbeat_timestamp"`
+	SecondsSinceHeartbeat    float64 `json:"seconds_since_heartbeat"`
+	MaxSecondsSinceHeartbeat float64 `json:"max_seconds_since_heartbeat"`
+}
+
+// ReadinessStatus reports whether the finality gadget serves up to date finality: its dependencies are reachable,
+// it keeps polling the L2 chain, and its latest finalized block is close enough to the L2 head
+type ReadinessStatus struct {
+	Ready bool `json:"ready"`
+	// Reasons lists why the finality gadget isn't ready
+	Reasons      []string            `json:"reasons,omitempty"`
+	Dependencies []*DependencyStatus `json:"dependencies"`
+
+	L2HeadHeight          uint64 `json:"l2_head_height"`
+	LatestFinalizedHeight uint64 `json:"latest_finalized_height"`
+	// ProcessingLag is the number of L2 blocks above the latest finalized block
+	ProcessingLag    uint64 `json:"processing_lag"`
+	MaxProcessingLag uint64 `json:"max_processing_lag"`
+
+	// LastPollTimestamp is the unix time of the last successful poll of the L2 chain, 0 if there was none yet
+	LastPollTimestamp       uint64  `json:"last_poll_timestamp"`
+	SecondsSinceLastPoll    float64 `json:"seconds_since_last_poll"`
+	MaxSecondsSinceLastPoll float64 `json:"max_seconds_since_last_poll"`
+
+	// Failures lists the components of the finality gadget failing in a row
+	Failures []*ComponentFailure `json:"failures,omitempty"`
+}
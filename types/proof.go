@@ -0,0 +1,19 @@
+package types
+
+// FinalityProofVersion is the version of the proof bundle format produced by this build
+const FinalityProofVersion uint32 = 2
+
+type FinalityProof struct {
+	Version            uint32        `json:"version" description:"proof bundle format version"`
+	ConsumerId         string        `json:"consumer_id" description:"consumer id of the rollup BSN"`
+	BlockHeight        uint64        `json:"block_height" description:"block height"`
+	BlockHash          string        `json:"block_hash" description:"block hash"`
+	BlockTimestamp     uint64        `json:"block_timestamp" description:"block timestamp"`
+	BabylonHeight      uint64        `json:"babylon_height" description:"babylon height at which the FP set and the contract allow-list were queried to finalize the block"`
+	BtcHeight          uint32        `json:"btc_height" description:"btc height at which the voting power was queried to finalize the block"`
+	VotesBabylonHeight uint64        `json:"votes_babylon_height" description:"babylon height at which the votes were queried for the bundle"`
+	TotalPower         uint64        `json:"total_power" description:"total voting power of the finality provider set"`
+	FinalityProviders  []*VoterPower `json:"finality_providers" description:"finality providers that voted for the block and their voting power"`
+	Votes              []*BlockVoter `json:"votes" description:"votes for the block by the finality providers"`
+	SignaturesVerified bool          `json:"signatures_verified" description:"whether the finality gadget verified the EOTS signatures of the votes, else the contract is trusted"`
+}
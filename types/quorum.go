@@ -0,0 +1,71 @@
+package types
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// QuorumRule is the share of the total voting power that must vote for a block to be finalized
+type QuorumRule struct {
+	Numerator   uint64 `json:"numerator"`
+	Denominator uint64 `json:"denominator"`
+	// Strict requires the voted power to be strictly greater than the threshold, instead of greater or equal
+	Strict bool `json:"strict"`
+}
+
+// DefaultQuorumRule is the BFT quorum: at least 2/3 of the total voting power
+var DefaultQuorumRule = QuorumRule{Numerator: 2, Denominator: 3}
+
+// Validate checks the rule is a satisfiable ratio in (0, 1]
+func (q QuorumRule) Validate() error {
+	if q.Denominator == 0 {
+		return fmt.Errorf("quorum denominator must be greater than 0")
+	}
+	if q.Numerator == 0 {
+		return fmt.Errorf("quorum numerator must be greater than 0")
+	}
+	if q.Numerator > q.Denominator {
+		return fmt.Errorf("quorum %d/%d must not be greater than 1", q.Numerator, q.Denominator)
+	}
+	if q.Strict && q.Numerator == q.Denominator {
+		return fmt.Errorf("strict quorum %d/%d can never be reached", q.Numerator, q.Denominator)
+	}
+	return nil
+}
+
+// Reached returns true if votedPower meets the rule against totalPower.
+// The products are compared as 128-bit integers so large voting powers can't overflow.
+func (q QuorumRule) Reached(votedPower, totalPower uint64) bool {
+	votedHi, votedLo := bits.Mul64(votedPower, q.Denominator)
+	totalHi, totalLo := bits.Mul64(totalPower, q.Numerator)
+	if votedHi != totalHi {
+		return votedHi > totalHi
+	}
+	if q.Strict {
+		return votedLo > totalLo
+	}
+	return votedLo >= totalLo
+}
+
+// Threshold returns the minimum voted power that meets the rule against totalPower
+func (q QuorumRule) Threshold(totalPower uint64) uint64 {
+	hi, lo := bits.Mul64(totalPower, q.Numerator)
+	// the quotient fits in 64 bits as the numerator is at most the denominator
+	threshold, rem := bits.Div64(hi, lo, q.Denominator)
+	if rem != 0 || q.Strict {
+		threshold++
+	}
+	return threshold
+}
+
+// Ratio returns the rule as a float, for display
+func (q QuorumRule) Ratio() float64 {
+	return float64(q.Numerator) / float64(q.Denominator)
+}
+
+func (q QuorumRule) String() string {
+	if q.Strict {
+		return fmt.Sprintf("> %d/%d", q.Numerator, q.Denominator)
+	}
+	return fmt.Sprintf(">= %d/%d", q.Numerator, q.Denominator)
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Upstreams queried by the finality gadget
+const (
+	UpstreamL2       = "l2"
+	UpstreamBabylon  = "babylon"
+	UpstreamBitcoin  = "bitcoin"
+	UpstreamCosmWasm = "cosmwasm"
+)
+
+// ErrorClass tells whether retrying a failed upstream call can succeed
+type ErrorClass uint8
+
+const (
+	// ErrorClassTransient: the upstream is unreachable, overloaded or lagging, the call can be retried
+	ErrorClassTransient ErrorClass = iota + 1
+	// ErrorClassPermanent: the upstream rejects the call, eg. bad credentials or an unsupported method,
+	// and retrying it fails the same way
+	ErrorClassPermanent
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassTransient:
+		return "transient"
+	case ErrorClassPermanent:
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+// UpstreamError is the error of a call to an upstream, classified as transient or permanent
+type UpstreamError struct {
+	Upstream string
+	Class    ErrorClass
+	Err      error
+}
+
+func (e *UpstreamError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// NewUpstreamError classifies the error of a call to the upstream. Nil and already classified errors are
+// returned as is.
+func NewUpstreamError(upstream string, class ErrorClass, err error) error {
+	var upstreamErr *UpstreamError
+	if err == nil || errors.As(err, &upstreamErr) {
+		return err
+	}
+	return &UpstreamError{Upstream: upstream, Class: class, Err: err}
+}
+
+// ClassifyUpstreamError classifies the error of a call to the upstream from its gRPC status, which the Cosmos SDK
+// queries also return. Any other error, such as a timeout or a network error, is transient.
+func ClassifyUpstreamError(upstream string, err error) error {
+	class := ErrorClassTransient
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+			codes.Unauthenticated, codes.Unimplemented, codes.FailedPrecondition, codes.OutOfRange:
+			class = ErrorClassPermanent
+		}
+	}
+	return NewUpstreamError(upstream, class, err)
+}
+
+// IsPermanentError tells whether the error is from an upstream call that fails the same way when retried.
+// Unclassified errors are not permanent.
+func IsPermanentError(err error) bool {
+	var upstreamErr *UpstreamError
+	return errors.As(err, &upstreamErr) && upstreamErr.Class == ErrorClassPermanent
+}
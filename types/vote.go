@@ -0,0 +1,32 @@
+package types
+
+type BlockVoter struct {
+	FpBtcPkHex        string `json:"fp_btc_pk_hex" description:"finality provider BTC public key (hex)"`
+	PubRand           []byte `json:"pub_rand" description:"EOTS public randomness used for the signature"`
+	FinalitySignature []byte `json:"finality_signature" description:"EOTS finality signature over the block"`
+}
+
+type PubRandCommit struct {
+	StartHeight   uint64 `json:"start_height" description:"first L2 height covered by the commitment"`
+	NumPubRand    uint64 `json:"num_pub_rand" description:"number of public randomness values committed"`
+	BabylonHeight uint64 `json:"babylon_height" description:"babylon height at which the commitment was timestamped"`
+	Commitment    []byte `json:"commitment" description:"merkle root of the committed public randomness"`
+}
+
+// EndHeight returns the last L2 height covered by the commitment
+func (c *PubRandCommit) EndHeight() uint64 {
+	return c.StartHeight + c.NumPubRand - 1
+}
+
+// Epoch returns the Babylon epoch the commitment was submitted in, epoch 1 starting at Babylon height 1
+func (c *PubRandCommit) Epoch(epochInterval uint64) uint64 {
+	if c.BabylonHeight == 0 || epochInterval == 0 {
+		return 0
+	}
+	return (c.BabylonHeight-1)/epochInterval + 1
+}
+
+// Covers returns true if the commitment contains public randomness for the given L2 height
+func (c *PubRandCommit) Covers(height uint64) bool {
+	return c.NumPubRand > 0 && c.StartHeight <= height && height <= c.EndHeight()
+}